@@ -1,12 +1,14 @@
 package anthropic
 
 import (
-    "bytes"
     "context"
     "encoding/json"
     "fmt"
+    "io"
     "io/ioutil"
     "net/http"
+    "strings"
+    "time"
     "github.com/rdhillbb/logging"
 )
 
@@ -43,6 +45,7 @@ func logJSON(prefix string, data interface{}) {
 func (c *AnthropicClient) UpdateSystemPrompt(prompt string) {
     logMessage("Updating system prompt")
     c.systemPrompt = prompt
+    c.systemTemplate = nil
     logJSON("Updated client configuration", map[string]interface{}{
         "systemPrompt": prompt,
     })
@@ -76,9 +79,23 @@ func WithHTTPClient(client *http.Client) ClientOption {
     }
 }
 
+// WithRequestTimeout bounds each API request made by the client, from
+// sending it until the response has been read; 0 removes the limit. The
+// default is 10 minutes. For streams it bounds the wait for the response to
+// start, and WithStreamIdleTimeout covers the rest. Use WithTimeout to bound
+// a whole call.
+func WithRequestTimeout(d time.Duration) ClientOption {
+    return func(c *AnthropicClient) {
+        if d >= 0 {
+            c.requestTimeout = d
+        }
+    }
+}
+
 func WithSystemPrompt(prompt string) ClientOption {
     return func(c *AnthropicClient) {
         c.systemPrompt = prompt
+        c.systemTemplate = nil
     }
 }
 
@@ -89,18 +106,15 @@ func NewClient(apiKey string, opts ...ClientOption) *AnthropicClient {
     client := &AnthropicClient{
         apiKey:       apiKey,
         httpClient:   &http.Client{},
+        requestTimeout: defaultRequestTimeout,
         systemPrompt: defaultSystemPrompt,
-        defaultParams: MessageParams{
-            Tools: GetDefaultTools(),
-            ToolChoice: &ToolChoice{
-                Type: ToolChoiceAuto,
-            },
-        },
+        systemTemplate: defaultSystemTemplate,
     }
     
     for _, opt := range opts {
         opt(client)
     }
+    client.session = client.NewSession()
     
     logJSON("Client configuration", map[string]interface{}{
         "maxConvLength": client.maxConvLength,
@@ -115,135 +129,221 @@ func NewClient(apiKey string, opts ...ClientOption) *AnthropicClient {
 
 // sendRequest handles all HTTP communication with the Anthropic API.
 // It includes comprehensive logging of requests, responses, and errors.
-func (c *AnthropicClient) sendRequest(ctx context.Context, reqBody Request) (*AnthropicResponse, error) {
-    logMessage("Preparing API request")
-    logJSON("Request payload", reqBody)
+// Usage is recorded against the client and, when non-nil, the originating session.
+func (c *AnthropicClient) sendRequest(ctx context.Context, session *Session, reqBody Request) (*AnthropicResponse, error) {
+    if err := c.inflight.begin(); err != nil {
+        logMessage("Refusing request: %v", err)
+        return nil, err
+    }
+    defer c.inflight.end()
 
-    jsonData, err := json.Marshal(reqBody)
+    buf, jsonData, err := c.encodeRequest(&reqBody)
     if err != nil {
-        logMessage("Error marshaling request: %v", err)
-        return nil, fmt.Errorf("error marshaling request: %w", err)
+        return nil, err
     }
-
-    req, err := http.NewRequestWithContext(ctx, "POST", defaultAPIEndpoint, bytes.NewBuffer(jsonData))
+    defer buf.release()
+    key := c.cacheKey(jsonData)
+    if resp, ok := c.cache.get(key); ok {
+        logMessage("Returning cached response")
+        return resp, nil
+    }
+    req, err := c.prepareRequest(ctx, session, &reqBody, buf, jsonData)
     if err != nil {
-        logMessage("Error creating HTTP request: %v", err)
-        return nil, fmt.Errorf("error creating request: %w", err)
+        return nil, err
+    }
+    if c.requestTimeout > 0 {
+        reqCtx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+        defer cancel()
+        req = req.WithContext(reqCtx)
     }
-
-    // Set required headers for Anthropic API
-    req.Header.Set("Content-Type", "application/json")
-    req.Header.Set("anthropic-version", "2023-06-01")
-    req.Header.Set("x-api-key", c.apiKey)
 
     logMessage("Sending request to Anthropic API")
+    start := time.Now()
+    record := AuditRecord{Time: start, Model: reqBody.Model, Request: jsonData}
     resp, err := c.httpClient.Do(req)
     if err != nil {
         logMessage("API request failed: %v", err)
-        return nil, fmt.Errorf("error sending request: %w", err)
+        err = fmt.Errorf("error sending request: %w", err)
+        record.Error, record.Latency = err.Error(), time.Since(start)
+        c.auditExchange(ctx, session, record)
+        return nil, err
     }
     defer resp.Body.Close()
 
     body, err := ioutil.ReadAll(resp.Body)
     if err != nil {
         logMessage("Error reading response body: %v", err)
-        return nil, fmt.Errorf("error reading response: %w", err)
+        err = fmt.Errorf("error reading response: %w", err)
+        record.StatusCode, record.Error, record.Latency = resp.StatusCode, err.Error(), time.Since(start)
+        c.auditExchange(ctx, session, record)
+        return nil, err
     }
+    latency := time.Since(start)
+    captureRawResponse(ctx, resp, body)
+    record.RequestID = resp.Header.Get("request-id")
+    record.StatusCode = resp.StatusCode
+    record.Response = body
+    record.Latency = latency
 
     // Handle non-200 responses with proper error parsing
     if resp.StatusCode != http.StatusOK {
-        logMessage("Received error response (status %d)", resp.StatusCode)
-        var errorResp struct {
-            Error struct {
-                Type    string `json:"type"`
-                Message string `json:"message"`
-            } `json:"error"`
-        }
-        if err := json.Unmarshal(body, &errorResp); err != nil {
-            logMessage("Failed to parse error response: %v", err)
-            return nil, fmt.Errorf("error response status %d: %s", resp.StatusCode, body)
-        }
-        logMessage("API error: %s - %s", errorResp.Error.Type, errorResp.Error.Message)
-        return nil, fmt.Errorf("API error: %s - %s", errorResp.Error.Type, errorResp.Error.Message)
+        err := responseError(resp.StatusCode, body)
+        record.Error = err.Error()
+        c.auditExchange(ctx, session, record)
+        return nil, err
     }
 
     var anthropicResp AnthropicResponse
     if err := json.Unmarshal(body, &anthropicResp); err != nil {
         logMessage("Error parsing response JSON: %v", err)
-        return nil, fmt.Errorf("error parsing response: %w", err)
+        err = fmt.Errorf("error parsing response: %w", err)
+        record.Error = err.Error()
+        c.auditExchange(ctx, session, record)
+        return nil, err
     }
 
-    logJSON("API response", anthropicResp)
-    return &anthropicResp, nil
+    result, err := c.finishResponse(ctx, session, reqBody, record, &anthropicResp)
+    if err != nil {
+        return nil, err
+    }
+    c.cache.put(key, result)
+    return result, nil
 }
 
-// ChatMe handles a single message interaction while maintaining conversation history.
-// It manages the conversation state and handles logging of the entire interaction.
-func (c *AnthropicClient) ChatMe(ctx context.Context, message string, params *MessageParams) (*AnthropicResponse, error) {
-    logMessage("Starting chat interaction with message: %s", message)
-    
-    content := []MessageContent{{
-        Type: ContentTypeText,
-        Text: message,
-    }}
-
-    // Add user message to conversation history
-    c.addMessageToConversation(RoleUser, content)
-    logMessage("Added user message to conversation")
-    logJSON("Current conversation state", c.conversation)
-    
-    c.trimConversationHistory()
+// encodeRequest validates and adjusts reqBody and encodes it into a pooled
+// buffer. The encoding is only valid until the buffer is released.
+func (c *AnthropicClient) encodeRequest(reqBody *Request) (*requestBuffer, []byte, error) {
+    logMessage("Preparing API request")
+    if c.repairConversation {
+        reqBody.Messages = RepairConversation(reqBody.Messages)
+        if err := ValidateConversation(reqBody.Messages); err != nil {
+            logMessage("Conversation cannot be repaired: %v", err)
+            return nil, nil, err
+        }
+    }
+    if err := c.validateRequest(*reqBody); err != nil {
+        logMessage("Request failed validation: %v", err)
+        return nil, nil, err
+    }
+    reqBody.MaxTokens = c.resolveMaxTokens(reqBody.Model, reqBody.MaxTokens)
+    if c.piiRedactor != nil {
+        reqBody.Messages = c.piiRedactor.redactMessages(reqBody.Messages)
+    }
+    logJSON("Request payload", reqBody)
 
-    // Use system prompt hierarchy: params > client > default
-    systemPrompt := c.systemPrompt
-    if params != nil && params.System != "" {
-        systemPrompt = params.System
+    buf := getRequestBuffer()
+    jsonData, err := buf.encode(reqBody)
+    if err != nil {
+        logMessage("Error marshaling request: %v", err)
+        buf.release()
+        return nil, nil, fmt.Errorf("error marshaling request: %w", err)
     }
+    return buf, jsonData, nil
+}
 
-    // Prepare request with complete message history
-    reqBody := Request{
-        Model:       params.Model,
-        System:      systemPrompt,
-        Messages:    c.conversation,
-        MaxTokens:   params.MaxTokens,
-        Temperature: params.Temperature,
-        TopP:        params.TopP,
-        TopK:        params.TopK,
-        Tools:       params.Tools,
-        ToolChoice:  params.ToolChoice,
+// prepareRequest applies the client's limits and builds the HTTP request for
+// a body from encodeRequest
+func (c *AnthropicClient) prepareRequest(ctx context.Context, session *Session, reqBody *Request, buf *requestBuffer, jsonData []byte) (*http.Request, error) {
+    if err := c.checkBudget(session); err != nil {
+        logMessage("Refusing request: %v", err)
+        return nil, err
+    }
+    if c.rateLimiter != nil {
+        if err := c.rateLimiter.wait(ctx); err != nil {
+            logMessage("Request not sent: %v", err)
+            return nil, err
+        }
     }
 
-    // Send request and handle any errors
-    response, err := c.sendRequest(ctx, reqBody)
+    body, compressed := c.compressBody(buf, jsonData)
+    req, err := http.NewRequestWithContext(ctx, "POST", defaultAPIEndpoint, nil)
     if err != nil {
-        logMessage("Chat request failed: %v", err)
+        logMessage("Error creating HTTP request: %v", err)
+        return nil, fmt.Errorf("error creating request: %w", err)
+    }
+    req.Body = buf.body(body)
+    req.GetBody = func() (io.ReadCloser, error) { return buf.body(body), nil }
+    req.ContentLength = int64(len(body))
+
+    // Set required headers for Anthropic API
+    req.Header.Set("Content-Type", "application/json")
+    if compressed {
+        req.Header.Set("Content-Encoding", "gzip")
+    }
+    req.Header.Set("anthropic-version", "2023-06-01")
+    if err := c.setAuthHeaders(ctx, req); err != nil {
+        logMessage("Error setting credentials: %v", err)
+        req.Body.Close()
         return nil, err
     }
+    if betas := betaHeader(*reqBody); betas != "" {
+        req.Header.Set("anthropic-beta", betas)
+    }
+    return req, nil
+}
 
-    // Process and store assistant's response
-    if len(response.Content) > 0 {
-        logMessage("Adding assistant response to conversation")
-        c.addMessageToConversation(RoleAssistant, response.Content)
-        c.trimConversationHistory()
-        logJSON("Updated conversation state", c.conversation)
+// responseError turns a non-200 response into an error
+func responseError(statusCode int, body []byte) error {
+    logMessage("Received error response (status %d)", statusCode)
+    var errorResp struct {
+        Error struct {
+            Type    string `json:"type"`
+            Message string `json:"message"`
+        } `json:"error"`
+    }
+    if err := json.Unmarshal(body, &errorResp); err != nil {
+        logMessage("Failed to parse error response: %v", err)
+        return fmt.Errorf("error response status %d: %s", statusCode, body)
+    }
+    logMessage("API error: %s - %s", errorResp.Error.Type, errorResp.Error.Message)
+    if statusCode == http.StatusBadRequest && strings.HasPrefix(errorResp.Error.Message, "prompt is too long") {
+        return &PromptTooLongError{Message: errorResp.Error.Message}
     }
+    return fmt.Errorf("API error: %s - %s", errorResp.Error.Type, errorResp.Error.Message)
+}
 
-    return response, nil
+// finishResponse records a successful exchange and applies the output
+// guardrails and response hooks
+func (c *AnthropicClient) finishResponse(ctx context.Context, session *Session, reqBody Request, record AuditRecord, resp *AnthropicResponse) (*AnthropicResponse, error) {
+    logJSON("API response", resp)
+    c.recordUsage(session, reqBody, resp, record.RequestID, record.Latency)
+    if err := c.auditExchange(ctx, session, record); err != nil {
+        return nil, err
+    }
+    if err := c.checkOutput(ctx, resp); err != nil {
+        return nil, err
+    }
+    c.fireResponse(resp)
+    return resp, nil
 }
 
-// Conversation management methods with logging
+// The conversation methods below operate on the client's default session.
+// Use NewSession to hold several independent conversations on one client.
 
-func (c *AnthropicClient) addMessageToConversation(role string, content []MessageContent) {
-    logMessage("Adding message to conversation (role: %s)", role)
-    c.conversation = append(c.conversation, Message{
-        Role:    role,
-        Content: content,
-    })
+// ChatMe handles a single message interaction while maintaining conversation history.
+// It manages the conversation state and handles logging of the entire interaction.
+func (c *AnthropicClient) ChatMe(ctx context.Context, message string, params *MessageParams, opts ...RequestOption) (*AnthropicResponse, error) {
+    return c.session.ChatMe(ctx, message, params, opts...)
 }
 
-func (c *AnthropicClient) trimConversationHistory() {
-    if c.maxConvLength > 0 && len(c.conversation) > c.maxConvLength {
-        logMessage("Trimming conversation to max length: %d", c.maxConvLength)
-        c.conversation = c.conversation[len(c.conversation)-c.maxConvLength:]
-    }
+// Regenerate drops the last assistant turn from the conversation history and
+// requests a fresh response to the preceding user message.
+func (c *AnthropicClient) Regenerate(ctx context.Context, params *MessageParams, opts ...RequestOption) (*AnthropicResponse, error) {
+    return c.session.Regenerate(ctx, params, opts...)
+}
+
+// EditAndRetry replaces the Nth user turn, discards the messages that followed
+// it, and requests a new assistant response.
+func (c *AnthropicClient) EditAndRetry(ctx context.Context, index int, message string, params *MessageParams, opts ...RequestOption) (*AnthropicResponse, error) {
+    return c.session.EditAndRetry(ctx, index, message, params, opts...)
+}
+
+// GetConversation returns a copy of the default session's conversation history
+func (c *AnthropicClient) GetConversation() []Message {
+    return c.session.GetConversation()
+}
+
+// DefaultSession returns the session used by the client-level conversation methods
+func (c *AnthropicClient) DefaultSession() *Session {
+    return c.session
 }
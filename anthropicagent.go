@@ -0,0 +1,233 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+)
+
+// AgentConfig declares an agent: who it is, what it can do, and how long it
+// may work on a task
+type AgentConfig struct {
+    Name          string        // Identifies the agent in logs and results
+    Description   string        // What the agent is for
+    Instructions  string        // System prompt
+    Persona       string        // Registered persona supplying the system prompt when Instructions is empty
+    Tools         *ToolRegistry // Tools the agent may call; nil uses the client's registry
+    Params        MessageParams // Model and sampling settings, over the client defaults
+    MaxIterations int           // Model turns per task; 0 uses the client default
+    KeepHistory   bool          // Carry the conversation from one task into the next
+
+    // Memory, when set, is searched for facts relevant to each task, which
+    // are added to the system prompt, and the agent gets a remember tool to
+    // store new ones. MemoryRecall caps the facts recalled; 0 means 5.
+    Memory       MemoryStore
+    MemoryRecall int
+
+    // StopWhen ends a task early once it returns true (see MessageParams.StopWhen)
+    StopWhen func(resp *AnthropicResponse) bool
+}
+
+// Agent runs tasks through the tool loop with a fixed configuration, so call
+// sites declare agents once instead of assembling handlers, parameters and
+// loops each time. Usage counts toward the client's totals and budget. An
+// agent works on one task at a time.
+type Agent struct {
+    config  AgentConfig
+    client  *AnthropicClient
+    session *Session
+    mu      sync.Mutex
+}
+
+// AgentResult is the outcome of one agent task
+type AgentResult struct {
+    Agent    string
+    Task     string
+    Output   string             // Text of the final response
+    Response *AnthropicResponse // Final response
+    Messages []Message          // The task, tool calls and results, and answer
+    Usage    ModelUsage         // Tokens and estimated cost of the task
+    Duration time.Duration
+}
+
+// NewAgent creates an agent on the client. It fails when the configured
+// persona is not registered.
+func (c *AnthropicClient) NewAgent(config AgentConfig) (*Agent, error) {
+    if config.Name == "" {
+        config.Name = "agent"
+    }
+    if config.Instructions == "" && config.Persona != "" {
+        if _, ok := Persona(config.Persona); !ok {
+            return nil, fmt.Errorf("agent %s: unknown persona %q", config.Name, config.Persona)
+        }
+    }
+    return &Agent{
+        config:  config,
+        client:  c,
+        session: c.NewSession(WithSessionID("agent-" + config.Name + "-" + newSessionID())),
+    }, nil
+}
+
+// Name returns the agent's name
+func (a *Agent) Name() string {
+    return a.config.Name
+}
+
+// Description returns what the agent is for
+func (a *Agent) Description() string {
+    return a.config.Description
+}
+
+// Session returns the session holding the agent's conversation
+func (a *Agent) Session() *Session {
+    return a.session
+}
+
+// Run works on task until the agent gives a final answer, calls a terminal
+// tool, meets its stop condition, or runs out of iterations (ErrMaxIterations)
+func (a *Agent) Run(ctx context.Context, task string, opts ...RequestOption) (*AgentResult, error) {
+    return a.run(ctx, task, a.config.Tools, nil, opts)
+}
+
+// run is Run with the tools replaced by registry and an extra stop condition
+func (a *Agent) run(ctx context.Context, task string, registry *ToolRegistry, stop func(*AnthropicResponse) bool, opts []RequestOption) (*AgentResult, error) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+
+    logMessage("Agent %s starting task: %s", a.config.Name, task)
+    if !a.config.KeepHistory {
+        a.session.Reset()
+    }
+    start := time.Now()
+    before := a.session.UsageReport().Total
+    first := len(a.session.GetConversation())
+
+    // The caller's context may carry another agent's tools
+    if registry == nil {
+        registry = a.client.registry
+    }
+    if a.config.Memory != nil {
+        extended, err := registry.extend(&rememberTool{store: a.config.Memory, agent: a.config.Name})
+        if err != nil {
+            return nil, fmt.Errorf("agent %s: %w", a.config.Name, err)
+        }
+        registry = extended
+    }
+    ctx = withRegistry(ctx, registry)
+    params := a.params(registry, stop)
+    if a.config.Memory != nil {
+        if recalled := a.recallPrompt(ctx, task); recalled != "" {
+            params.System = strings.TrimSpace(params.System + "\n\n" + recalled)
+        }
+    }
+    resp, err := a.session.AChatWithTools(ctx, task, &params, nil, opts...)
+    if err != nil {
+        logMessage("Agent %s failed: %v", a.config.Name, err)
+        return nil, fmt.Errorf("agent %s: %w", a.config.Name, err)
+    }
+
+    conversation := a.session.GetConversation()
+    if first > len(conversation) {
+        first = 0
+    }
+    result := &AgentResult{
+        Agent:    a.config.Name,
+        Task:     task,
+        Output:   resp.Text(),
+        Response: resp,
+        Messages: conversation[first:],
+        Usage:    usageSince(before, a.session.UsageReport().Total),
+        Duration: time.Since(start),
+    }
+    logMessage("Agent %s finished in %s", a.config.Name, result.Duration)
+    return result, nil
+}
+
+// params builds the call parameters for a task
+func (a *Agent) params(registry *ToolRegistry, stop func(*AnthropicResponse) bool) MessageParams {
+    params := a.config.Params
+    if a.config.MaxIterations > 0 {
+        params.MaxToolIterations = a.config.MaxIterations
+    }
+    if a.config.StopWhen != nil {
+        params.StopWhen = a.config.StopWhen
+    }
+    if stop != nil {
+        own := params.StopWhen
+        params.StopWhen = func(resp *AnthropicResponse) bool {
+            return stop(resp) || (own != nil && own(resp))
+        }
+    }
+    if registry != nil && len(params.Tools) == 0 {
+        params.Tools = registry.Tools()
+    } else if added := a.addedTools(registry, params.Tools); len(added) > 0 {
+        params.Tools = append(append([]Tool{}, params.Tools...), added...)
+    }
+    switch {
+    case params.System != "":
+    case a.config.Instructions != "":
+        params.System = a.config.Instructions
+    case a.config.Persona != "":
+        params.System = a.renderPersona(params.Tools)
+    }
+    return params
+}
+
+// addedTools returns the tools registry holds beyond the agent's own and
+// the listed ones, such as remember and an orchestrator's delegate tools,
+// which an explicit Params.Tools list would otherwise leave out
+func (a *Agent) addedTools(registry *ToolRegistry, listed []Tool) []Tool {
+    if registry == nil {
+        return nil
+    }
+    own := a.config.Tools
+    if own == nil {
+        own = a.client.registry
+    }
+    names := make(map[string]bool, len(listed))
+    for _, tool := range listed {
+        names[tool.Name] = true
+    }
+    var added []Tool
+    for _, tool := range registry.Tools() {
+        if names[tool.Name] {
+            continue
+        }
+        if own != nil {
+            if _, mine := own.Definition(tool.Name); mine {
+                continue
+            }
+        }
+        added = append(added, tool)
+    }
+    return added
+}
+
+func (a *Agent) renderPersona(tools []Tool) string {
+    tmpl, ok := Persona(a.config.Persona)
+    if !ok {
+        return ""
+    }
+    now := time.Now()
+    text, err := tmpl.Render(PromptData{Now: now, Date: now.Format("2006-01-02"), Tools: tools})
+    if err != nil {
+        logMessage("%v", err)
+        return tmpl.String()
+    }
+    return text
+}
+
+// usageSince returns the usage accumulated between two totals
+func usageSince(before, after ModelUsage) ModelUsage {
+    return ModelUsage{
+        Requests:                 after.Requests - before.Requests,
+        InputTokens:              after.InputTokens - before.InputTokens,
+        OutputTokens:             after.OutputTokens - before.OutputTokens,
+        CacheCreationInputTokens: after.CacheCreationInputTokens - before.CacheCreationInputTokens,
+        CacheReadInputTokens:     after.CacheReadInputTokens - before.CacheReadInputTokens,
+        CostUSD:                  after.CostUSD - before.CostUSD,
+        Priced:                   after.Priced,
+    }
+}
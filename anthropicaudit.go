@@ -0,0 +1,168 @@
+package anthropic
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "sync"
+    "time"
+)
+
+// AuditRecord is one API exchange as sent and received. Request and Response
+// hold the exact JSON bodies; Response is the error body for failed calls and
+// empty when no response arrived.
+type AuditRecord struct {
+    Time       time.Time       `json:"time"`
+    RequestID  string          `json:"request_id,omitempty"`
+    SessionID  string          `json:"session_id,omitempty"`
+    Model      string          `json:"model"`
+    Request    json.RawMessage `json:"request"`
+    Response   json.RawMessage `json:"response,omitempty"`
+    StatusCode int             `json:"status_code,omitempty"`
+    Error      string          `json:"error,omitempty"`
+    Latency    time.Duration   `json:"latency"`
+}
+
+// AuditStore persists audit records. SaveAudit is called synchronously after
+// every request, so slow stores should buffer.
+type AuditStore interface {
+    SaveAudit(ctx context.Context, record AuditRecord) error
+}
+
+// AuditConfig enables the audit transcript
+type AuditConfig struct {
+    Store AuditStore
+
+    // Redact rewrites a record before it is stored, e.g. to mask PII with
+    // RedactJSONStrings. The record's bodies are copies and may be replaced.
+    Redact func(*AuditRecord)
+
+    // Required fails a call whose record cannot be stored instead of only
+    // logging the error, for deployments where unaudited output is not allowed
+    Required bool
+}
+
+// WithAudit records every request and response to cfg.Store
+func WithAudit(cfg AuditConfig) ClientOption {
+    return func(c *AnthropicClient) {
+        if cfg.Store != nil {
+            c.audit = &cfg
+        }
+    }
+}
+
+// auditExchange stores the record of one request. It returns an error only
+// when the store fails and auditing is required.
+func (c *AnthropicClient) auditExchange(ctx context.Context, session *Session, record AuditRecord) error {
+    if c.audit == nil {
+        return nil
+    }
+    if session != nil {
+        record.SessionID = session.id
+    }
+    // The bodies may share memory with buffers the caller keeps using
+    record.Request = append(json.RawMessage(nil), record.Request...)
+    record.Response = append(json.RawMessage(nil), record.Response...)
+    if len(record.Response) > 0 && !json.Valid(record.Response) {
+        // Gateways can answer with HTML or plain text; keep it as a string
+        record.Response, _ = json.Marshal(string(record.Response))
+    }
+    if c.audit.Redact != nil {
+        c.audit.Redact(&record)
+    }
+
+    if err := c.audit.Store.SaveAudit(ctx, record); err != nil {
+        logMessage("Failed to store audit record: %v", err)
+        if c.audit.Required {
+            return fmt.Errorf("error storing audit record: %w", err)
+        }
+    }
+    return nil
+}
+
+// RedactJSONStrings applies redact to every string value in a JSON document,
+// leaving object keys and structure untouched
+func RedactJSONStrings(data json.RawMessage, redact func(string) string) (json.RawMessage, error) {
+    if len(data) == 0 {
+        return data, nil
+    }
+    var doc interface{}
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return nil, fmt.Errorf("error parsing JSON for redaction: %w", err)
+    }
+    redacted, err := json.Marshal(redactValue(doc, redact))
+    if err != nil {
+        return nil, fmt.Errorf("error encoding redacted JSON: %w", err)
+    }
+    return redacted, nil
+}
+
+func redactValue(v interface{}, redact func(string) string) interface{} {
+    switch val := v.(type) {
+    case string:
+        return redact(val)
+    case []interface{}:
+        for i := range val {
+            val[i] = redactValue(val[i], redact)
+        }
+    case map[string]interface{}:
+        for k := range val {
+            val[k] = redactValue(val[k], redact)
+        }
+    }
+    return v
+}
+
+// MemoryAuditStore keeps audit records in memory, for tests and short-lived tools
+type MemoryAuditStore struct {
+    mu      sync.Mutex
+    records []AuditRecord
+}
+
+// NewMemoryAuditStore creates an empty in-memory store
+func NewMemoryAuditStore() *MemoryAuditStore {
+    return &MemoryAuditStore{}
+}
+
+func (s *MemoryAuditStore) SaveAudit(ctx context.Context, record AuditRecord) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.records = append(s.records, record)
+    return nil
+}
+
+// Records returns a copy of the stored records
+func (s *MemoryAuditStore) Records() []AuditRecord {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    records := make([]AuditRecord, len(s.records))
+    copy(records, s.records)
+    return records
+}
+
+// JSONLAuditStore writes each record as a line of JSON, e.g. to an
+// append-only file
+type JSONLAuditStore struct {
+    mu sync.Mutex
+    w  io.Writer
+}
+
+// NewJSONLAuditStore creates a store writing to w
+func NewJSONLAuditStore(w io.Writer) *JSONLAuditStore {
+    return &JSONLAuditStore{w: w}
+}
+
+func (s *JSONLAuditStore) SaveAudit(ctx context.Context, record AuditRecord) error {
+    line, err := json.Marshal(record)
+    if err != nil {
+        return fmt.Errorf("error encoding audit record: %w", err)
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    _, err = s.w.Write(append(line, '\n'))
+    return err
+}
@@ -0,0 +1,166 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "sync"
+    "time"
+)
+
+// APIKeyProvider returns the API key to send with a request. It is called for
+// every request, so keys held in Vault or a secrets manager can be rotated
+// without recreating the client; wrap slow lookups with CachedAPIKey.
+type APIKeyProvider func(ctx context.Context) (string, error)
+
+// WithAPIKey sets the API key, replacing any key provider or bearer token
+// authentication. It is mostly useful with With, to derive a client that
+// uses a different key.
+func WithAPIKey(key string) ClientOption {
+    return func(c *AnthropicClient) {
+        c.apiKey = key
+        c.apiKeyProvider = nil
+        c.tokenSource = nil
+        c.ownCredentials = true
+    }
+}
+
+// WithAPIKeyProvider fetches the API key from provider instead of using the
+// key passed to NewClient, replacing any bearer token authentication
+func WithAPIKeyProvider(provider APIKeyProvider) ClientOption {
+    return func(c *AnthropicClient) {
+        if provider != nil {
+            c.apiKeyProvider = provider
+            c.tokenSource = nil
+            c.ownCredentials = true
+        }
+    }
+}
+
+// CachedAPIKey wraps provider so a fetched key is reused for ttl before it is
+// fetched again. Failed fetches are not cached.
+func CachedAPIKey(provider APIKeyProvider, ttl time.Duration) APIKeyProvider {
+    var (
+        mu      sync.Mutex
+        key     string
+        fetched time.Time
+    )
+    return func(ctx context.Context) (string, error) {
+        mu.Lock()
+        defer mu.Unlock()
+
+        if key != "" && time.Since(fetched) < ttl {
+            return key, nil
+        }
+        k, err := provider(ctx)
+        if err != nil {
+            return "", err
+        }
+        key, fetched = k, time.Now()
+        return key, nil
+    }
+}
+
+// tokenExpiryDelta is how long before expiry a cached token is refreshed
+const tokenExpiryDelta = 30 * time.Second
+
+// Token is a bearer token for Authorization header authentication
+type Token struct {
+    AccessToken string
+    Expiry      time.Time // Zero means the token does not expire
+}
+
+// Valid reports whether the token is set and not about to expire
+func (t *Token) Valid() bool {
+    if t == nil || t.AccessToken == "" {
+        return false
+    }
+    return t.Expiry.IsZero() || time.Until(t.Expiry) > tokenExpiryDelta
+}
+
+// TokenSource supplies bearer tokens, in the style of golang.org/x/oauth2.
+// Token is called whenever the cached token is missing or about to expire.
+type TokenSource interface {
+    Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+    return f(ctx)
+}
+
+// StaticToken returns a TokenSource that always supplies token
+func StaticToken(token string) TokenSource {
+    return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+        return &Token{AccessToken: token}, nil
+    })
+}
+
+// reuseTokenSource caches a token until it is about to expire
+type reuseTokenSource struct {
+    mu    sync.Mutex
+    src   TokenSource
+    token *Token
+}
+
+func (r *reuseTokenSource) Token(ctx context.Context) (*Token, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.token.Valid() {
+        return r.token, nil
+    }
+    token, err := r.src.Token(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if token == nil || token.AccessToken == "" {
+        return nil, fmt.Errorf("token source returned an empty token")
+    }
+    r.token = token
+    return token, nil
+}
+
+// WithBearerToken authenticates with "Authorization: Bearer <token>" instead
+// of x-api-key, as gateways and enterprise deployments expect
+func WithBearerToken(token string) ClientOption {
+    return WithTokenSource(StaticToken(token))
+}
+
+// WithTokenSource authenticates with bearer tokens from src instead of
+// x-api-key. Tokens are cached and refreshed from src shortly before they
+// expire.
+func WithTokenSource(src TokenSource) ClientOption {
+    return func(c *AnthropicClient) {
+        if src != nil {
+            c.tokenSource = &reuseTokenSource{src: src}
+            c.ownCredentials = true
+        }
+    }
+}
+
+// setAuthHeaders adds the client's credentials to an API request: a bearer
+// token when a token source is configured, the API key otherwise
+func (c *AnthropicClient) setAuthHeaders(ctx context.Context, req *http.Request) error {
+    if c.tokenSource != nil {
+        token, err := c.tokenSource.Token(ctx)
+        if err != nil {
+            return fmt.Errorf("error fetching bearer token: %w", err)
+        }
+        req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+        return nil
+    }
+
+    key := c.apiKey
+    if c.apiKeyProvider != nil {
+        k, err := c.apiKeyProvider(ctx)
+        if err != nil {
+            return fmt.Errorf("error fetching API key: %w", err)
+        }
+        key = k
+    }
+    req.Header.Set("x-api-key", key)
+    return nil
+}
@@ -0,0 +1,31 @@
+package anthropic
+
+import (
+    "strings"
+)
+
+// Beta feature flags sent in the anthropic-beta header
+const (
+    betaMCPClient     = "mcp-client-2025-04-04"
+    betaCodeExecution = "code-execution-2025-05-22"
+)
+
+// requestBetas lists the beta features a request depends on
+func requestBetas(reqBody Request) []string {
+    var betas []string
+    if len(reqBody.MCPServers) > 0 {
+        betas = append(betas, betaMCPClient)
+    }
+    for _, tool := range reqBody.Tools {
+        if tool.Type == ToolTypeCodeExecution {
+            betas = append(betas, betaCodeExecution)
+            break
+        }
+    }
+    return betas
+}
+
+// betaHeader formats the anthropic-beta header value, or "" when no betas apply
+func betaHeader(reqBody Request) string {
+    return strings.Join(requestBetas(reqBody), ",")
+}
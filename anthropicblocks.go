@@ -0,0 +1,479 @@
+package anthropic
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "strings"
+)
+
+// supportedImageTypes lists the media types accepted for base64 image blocks
+var supportedImageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// NewTextBlock creates a text content block
+func NewTextBlock(text string) (MessageContent, error) {
+    if text == "" {
+        return MessageContent{}, fmt.Errorf("text block must not be empty")
+    }
+    return MessageContent{Type: ContentTypeText, Text: text}, nil
+}
+
+// NewToolUseBlock creates a tool_use block, marshaling input to JSON. Input
+// must encode to a JSON object, as tool inputs always are.
+func NewToolUseBlock(id, name string, input interface{}) (MessageContent, error) {
+    if id == "" || name == "" {
+        return MessageContent{}, fmt.Errorf("tool_use block requires an id and a name")
+    }
+    encoded, err := json.Marshal(input)
+    if err != nil {
+        return MessageContent{}, fmt.Errorf("cannot encode input for tool %s: %w", name, err)
+    }
+    if input == nil {
+        encoded = json.RawMessage(`{}`)
+    } else if len(encoded) == 0 || encoded[0] != '{' {
+        return MessageContent{}, fmt.Errorf("input for tool %s must be a JSON object", name)
+    }
+    return MessageContent{Type: ContentTypeToolUse, ID: id, Name: name, Input: encoded}, nil
+}
+
+// NewToolResultBlock creates a tool_result block answering the tool_use with
+// the given ID
+func NewToolResultBlock(toolUseID, content string, isError bool) (MessageContent, error) {
+    if toolUseID == "" {
+        return MessageContent{}, fmt.Errorf("tool_result block requires a tool_use_id")
+    }
+    return MessageContent{
+        Type:      ContentTypeToolResult,
+        ToolUseID: toolUseID,
+        Content:   content,
+        IsError:   isError,
+    }, nil
+}
+
+// NewImageBlock creates a base64 image block. mediaType must be one of
+// image/jpeg, image/png, image/gif or image/webp.
+func NewImageBlock(mediaType string, data []byte) (MessageContent, error) {
+    if !containsString(supportedImageTypes, mediaType) {
+        return MessageContent{}, fmt.Errorf("unsupported image media type %q", mediaType)
+    }
+    if len(data) == 0 {
+        return MessageContent{}, fmt.Errorf("image data must not be empty")
+    }
+    return MessageContent{
+        Type: ContentTypeImage,
+        Source: &ImageSource{
+            Type:      "base64",
+            MediaType: mediaType,
+            Data:      base64.StdEncoding.EncodeToString(data),
+        },
+    }, nil
+}
+
+// NewImageURLBlock creates an image block referencing an image by URL
+func NewImageURLBlock(url string) (MessageContent, error) {
+    if url == "" {
+        return MessageContent{}, fmt.Errorf("image URL must not be empty")
+    }
+    return MessageContent{
+        Type:   ContentTypeImage,
+        Source: &ImageSource{Type: "url", URL: url},
+    }, nil
+}
+
+// NewDocumentBlock creates a document block. PDFs (application/pdf) are sent
+// base64-encoded and plain text (text/plain) as-is; title is optional.
+func NewDocumentBlock(mediaType string, data []byte, title string) (MessageContent, error) {
+    if len(data) == 0 {
+        return MessageContent{}, fmt.Errorf("document data must not be empty")
+    }
+
+    var source *ImageSource
+    switch mediaType {
+    case "application/pdf":
+        source = &ImageSource{Type: "base64", MediaType: mediaType, Data: base64.StdEncoding.EncodeToString(data)}
+    case "text/plain":
+        source = &ImageSource{Type: "text", MediaType: mediaType, Data: string(data)}
+    default:
+        return MessageContent{}, fmt.Errorf("unsupported document media type %q", mediaType)
+    }
+    return MessageContent{Type: ContentTypeDocument, Source: source, Title: title}, nil
+}
+
+// ContentBlock is implemented by the typed content blocks. Convert a
+// MessageContent with its Block method and type-switch on the result:
+//
+//     for _, block := range resp.Blocks() {
+//         switch b := block.(type) {
+//         case anthropic.TextBlock:
+//             fmt.Println(b.Text)
+//         case anthropic.ToolUseBlock:
+//             dispatch(b.Name, b.Input)
+//         }
+//     }
+//
+// MessageContent remains the wire format and the element type of
+// Message.Content, so histories, hooks and the subpackages keep working
+// unchanged; it is not being replaced. Every block type the client handles
+// has a typed block here, so the fields MessageContent carries for server
+// tools need not be read directly. Blocks convert back with ToMessageContent.
+type ContentBlock interface {
+    BlockType() ContentType
+    ToMessageContent() MessageContent
+}
+
+// TextBlock is a text content block
+type TextBlock struct {
+    Text      string
+    Citations []Citation
+}
+
+// ToolUseBlock is a tool call from Claude
+type ToolUseBlock struct {
+    ID    string
+    Name  string
+    Input json.RawMessage
+}
+
+// ToolResultBlock answers a tool call. Blocks, when set, replaces Content
+// with text and image blocks.
+type ToolResultBlock struct {
+    ToolUseID string
+    Content   string
+    Blocks    []ContentBlock
+    IsError   bool
+}
+
+// ThinkingBlock carries Claude's extended thinking. It must be sent back
+// unmodified, signature included, when continuing a tool loop.
+type ThinkingBlock struct {
+    Thinking  string
+    Signature string
+}
+
+// ImageBlock is an image, given inline or by URL
+type ImageBlock struct {
+    Source ImageSource
+}
+
+// DocumentBlock is a PDF or plain-text document
+type DocumentBlock struct {
+    Source ImageSource
+    Title  string
+}
+
+// ServerToolUseBlock is a call Claude made to a server tool such as web
+// search or code execution. It runs on Anthropic's side; no handler is needed.
+type ServerToolUseBlock struct {
+    ID    string
+    Name  string
+    Input json.RawMessage
+}
+
+// MCPToolUseBlock is a call Claude made to a tool on a remote MCP server
+type MCPToolUseBlock struct {
+    ID         string
+    Name       string
+    ServerName string
+    Input      json.RawMessage
+}
+
+// MCPToolResultBlock is the result of a remote MCP tool call
+type MCPToolResultBlock struct {
+    ToolUseID string
+    Content   string
+    Blocks    []ContentBlock
+    IsError   bool
+}
+
+// WebSearchResult is one page returned by the web search tool. The encrypted
+// content must be sent back unmodified for citations to work.
+type WebSearchResult struct {
+    URL              string
+    Title            string
+    EncryptedContent string
+    PageAge          string
+}
+
+// WebSearchToolResultBlock holds the results of one web search, or the
+// error code when the search failed
+type WebSearchToolResultBlock struct {
+    ToolUseID string
+    Results   []WebSearchResult
+    ErrorCode string
+}
+
+// CodeExecutionToolResultBlock holds the outcome of one server-side code
+// execution
+type CodeExecutionToolResultBlock struct {
+    CodeExecutionResult
+}
+
+// RawBlock holds any block type without a dedicated struct, or with an
+// unexpected shape, so conversion never loses content
+type RawBlock struct {
+    Content MessageContent
+}
+
+func (TextBlock) BlockType() ContentType                    { return ContentTypeText }
+func (ToolUseBlock) BlockType() ContentType                 { return ContentTypeToolUse }
+func (ToolResultBlock) BlockType() ContentType              { return ContentTypeToolResult }
+func (ThinkingBlock) BlockType() ContentType                { return ContentTypeThinking }
+func (ImageBlock) BlockType() ContentType                   { return ContentTypeImage }
+func (DocumentBlock) BlockType() ContentType                { return ContentTypeDocument }
+func (ServerToolUseBlock) BlockType() ContentType           { return ContentTypeServerToolUse }
+func (MCPToolUseBlock) BlockType() ContentType              { return ContentTypeMCPToolUse }
+func (MCPToolResultBlock) BlockType() ContentType           { return ContentTypeMCPToolResult }
+func (WebSearchToolResultBlock) BlockType() ContentType     { return ContentTypeWebSearchToolResult }
+func (CodeExecutionToolResultBlock) BlockType() ContentType { return ContentTypeCodeExecutionToolResult }
+func (b RawBlock) BlockType() ContentType                   { return b.Content.Type }
+
+func (b TextBlock) ToMessageContent() MessageContent {
+    return MessageContent{Type: ContentTypeText, Text: b.Text, Citations: b.Citations}
+}
+
+func (b ToolUseBlock) ToMessageContent() MessageContent {
+    return MessageContent{Type: ContentTypeToolUse, ID: b.ID, Name: b.Name, Input: b.Input}
+}
+
+func (b ToolResultBlock) ToMessageContent() MessageContent {
+    return MessageContent{
+        Type:          ContentTypeToolResult,
+        ToolUseID:     b.ToolUseID,
+        Content:       b.Content,
+        ContentBlocks: ContentFromBlocks(b.Blocks...),
+        IsError:       b.IsError,
+    }
+}
+
+func (b ThinkingBlock) ToMessageContent() MessageContent {
+    return MessageContent{Type: ContentTypeThinking, Thinking: b.Thinking, Signature: b.Signature}
+}
+
+func (b ImageBlock) ToMessageContent() MessageContent {
+    source := b.Source
+    return MessageContent{Type: ContentTypeImage, Source: &source}
+}
+
+func (b DocumentBlock) ToMessageContent() MessageContent {
+    source := b.Source
+    return MessageContent{Type: ContentTypeDocument, Source: &source, Title: b.Title}
+}
+
+func (b ServerToolUseBlock) ToMessageContent() MessageContent {
+    return MessageContent{Type: ContentTypeServerToolUse, ID: b.ID, Name: b.Name, Input: b.Input}
+}
+
+func (b MCPToolUseBlock) ToMessageContent() MessageContent {
+    return MessageContent{Type: ContentTypeMCPToolUse, ID: b.ID, Name: b.Name, ServerName: b.ServerName, Input: b.Input}
+}
+
+func (b MCPToolResultBlock) ToMessageContent() MessageContent {
+    return MessageContent{
+        Type:          ContentTypeMCPToolResult,
+        ToolUseID:     b.ToolUseID,
+        Content:       b.Content,
+        ContentBlocks: ContentFromBlocks(b.Blocks...),
+        IsError:       b.IsError,
+    }
+}
+
+func (b WebSearchToolResultBlock) ToMessageContent() MessageContent {
+    content := MessageContent{Type: ContentTypeWebSearchToolResult, ToolUseID: b.ToolUseID}
+    if b.ErrorCode != "" {
+        // Errors arrive as a single object rather than an array
+        content.ContentBlocks = []MessageContent{{Type: ContentTypeWebSearchToolResultError, ErrorCode: b.ErrorCode}}
+        content.contentObject = true
+        return content
+    }
+    content.ContentBlocks = make([]MessageContent, len(b.Results))
+    for i, result := range b.Results {
+        content.ContentBlocks[i] = MessageContent{
+            Type:             ContentTypeWebSearchResult,
+            URL:              result.URL,
+            Title:            result.Title,
+            EncryptedContent: result.EncryptedContent,
+            PageAge:          result.PageAge,
+        }
+    }
+    return content
+}
+
+func (b CodeExecutionToolResultBlock) ToMessageContent() MessageContent {
+    outcome := MessageContent{Type: ContentTypeCodeExecutionToolResultError, ErrorCode: b.ErrorCode}
+    if b.ErrorCode == "" {
+        returnCode := b.ReturnCode
+        outcome = MessageContent{
+            Type:          ContentTypeCodeExecutionResult,
+            Stdout:        b.Stdout,
+            Stderr:        b.Stderr,
+            ReturnCode:    &returnCode,
+            ContentBlocks: []MessageContent{},
+        }
+        for _, id := range b.FileIDs {
+            outcome.ContentBlocks = append(outcome.ContentBlocks, MessageContent{Type: ContentTypeCodeExecutionOutput, FileID: id})
+        }
+    }
+    return MessageContent{
+        Type:          ContentTypeCodeExecutionToolResult,
+        ToolUseID:     b.ToolUseID,
+        ContentBlocks: []MessageContent{outcome},
+        contentObject: true,
+    }
+}
+
+func (b RawBlock) ToMessageContent() MessageContent { return b.Content }
+
+// Block returns the typed form of the content block
+func (m MessageContent) Block() ContentBlock {
+    switch m.Type {
+    case ContentTypeText:
+        return TextBlock{Text: m.Text, Citations: m.Citations}
+    case ContentTypeToolUse:
+        return ToolUseBlock{ID: m.ID, Name: m.Name, Input: m.Input}
+    case ContentTypeToolResult:
+        if m.contentObject {
+            break // Only server tool results use the object form
+        }
+        return ToolResultBlock{
+            ToolUseID: m.ToolUseID,
+            Content:   m.Content,
+            Blocks:    BlocksFromContent(m.ContentBlocks),
+            IsError:   m.IsError,
+        }
+    case ContentTypeThinking:
+        return ThinkingBlock{Thinking: m.Thinking, Signature: m.Signature}
+    case ContentTypeImage:
+        if m.Source != nil {
+            return ImageBlock{Source: *m.Source}
+        }
+    case ContentTypeDocument:
+        if m.Source != nil {
+            return DocumentBlock{Source: *m.Source, Title: m.Title}
+        }
+    case ContentTypeServerToolUse:
+        return ServerToolUseBlock{ID: m.ID, Name: m.Name, Input: m.Input}
+    case ContentTypeMCPToolUse:
+        return MCPToolUseBlock{ID: m.ID, Name: m.Name, ServerName: m.ServerName, Input: m.Input}
+    case ContentTypeMCPToolResult:
+        return MCPToolResultBlock{
+            ToolUseID: m.ToolUseID,
+            Content:   m.Content,
+            Blocks:    BlocksFromContent(m.ContentBlocks),
+            IsError:   m.IsError,
+        }
+    case ContentTypeWebSearchToolResult:
+        if block, ok := webSearchBlock(m); ok {
+            return block
+        }
+    case ContentTypeCodeExecutionToolResult:
+        if block, ok := codeExecutionBlock(m); ok {
+            return block
+        }
+    }
+    return RawBlock{Content: m}
+}
+
+// webSearchBlock converts a web_search_tool_result, reporting false when its
+// content has an unexpected shape
+func webSearchBlock(m MessageContent) (WebSearchToolResultBlock, bool) {
+    block := WebSearchToolResultBlock{ToolUseID: m.ToolUseID}
+    for _, inner := range m.ContentBlocks {
+        switch inner.Type {
+        case ContentTypeWebSearchResult:
+            block.Results = append(block.Results, WebSearchResult{
+                URL:              inner.URL,
+                Title:            inner.Title,
+                EncryptedContent: inner.EncryptedContent,
+                PageAge:          inner.PageAge,
+            })
+        case ContentTypeWebSearchToolResultError:
+            block.ErrorCode = inner.ErrorCode
+        default:
+            return WebSearchToolResultBlock{}, false
+        }
+    }
+    return block, true
+}
+
+// codeExecutionBlock converts a code_execution_tool_result, reporting false
+// when its content has an unexpected shape
+func codeExecutionBlock(m MessageContent) (CodeExecutionToolResultBlock, bool) {
+    block := CodeExecutionToolResultBlock{CodeExecutionResult{ToolUseID: m.ToolUseID}}
+    if len(m.ContentBlocks) != 1 {
+        return CodeExecutionToolResultBlock{}, false
+    }
+    switch inner := m.ContentBlocks[0]; inner.Type {
+    case ContentTypeCodeExecutionResult:
+        block.Stdout = inner.Stdout
+        block.Stderr = inner.Stderr
+        if inner.ReturnCode != nil {
+            block.ReturnCode = *inner.ReturnCode
+        }
+        for _, output := range inner.ContentBlocks {
+            if output.FileID != "" {
+                block.FileIDs = append(block.FileIDs, output.FileID)
+            }
+        }
+    case ContentTypeCodeExecutionToolResultError:
+        block.ErrorCode = inner.ErrorCode
+    default:
+        return CodeExecutionToolResultBlock{}, false
+    }
+    return block, true
+}
+
+// BlocksFromContent converts content blocks to their typed form
+func BlocksFromContent(contents []MessageContent) []ContentBlock {
+    if len(contents) == 0 {
+        return nil
+    }
+    blocks := make([]ContentBlock, len(contents))
+    for i, content := range contents {
+        blocks[i] = content.Block()
+    }
+    return blocks
+}
+
+// ContentFromBlocks converts typed blocks back to the wire form
+func ContentFromBlocks(blocks ...ContentBlock) []MessageContent {
+    if len(blocks) == 0 {
+        return nil
+    }
+    contents := make([]MessageContent, len(blocks))
+    for i, block := range blocks {
+        contents[i] = block.ToMessageContent()
+    }
+    return contents
+}
+
+// BlocksOf returns the blocks of type T, e.g. BlocksOf[ToolUseBlock](resp.Blocks())
+func BlocksOf[T ContentBlock](blocks []ContentBlock) []T {
+    var matches []T
+    for _, block := range blocks {
+        if typed, ok := block.(T); ok {
+            matches = append(matches, typed)
+        }
+    }
+    return matches
+}
+
+// Blocks returns the response content in typed form
+func (r *AnthropicResponse) Blocks() []ContentBlock {
+    return BlocksFromContent(r.Content)
+}
+
+// Text returns the text blocks of the response joined together
+func (r *AnthropicResponse) Text() string {
+    var text strings.Builder
+    for _, content := range r.Content {
+        if content.Type == ContentTypeText {
+            text.WriteString(content.Text)
+        }
+    }
+    return text.String()
+}
+
+// NewMessageFromBlocks creates a message from typed blocks
+func NewMessageFromBlocks(role Role, blocks ...ContentBlock) Message {
+    return Message{Role: role, Content: ContentFromBlocks(blocks...)}
+}
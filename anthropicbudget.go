@@ -0,0 +1,81 @@
+package anthropic
+
+import (
+    "errors"
+    "fmt"
+)
+
+// ErrBudgetExceeded is returned (wrapped in a *BudgetExceededError) when a client
+// or session has spent its allotment. Test for it with errors.Is.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget caps spending for a client or session. A zero field means no limit.
+// The budget is checked before each request, so the request that crosses the
+// limit completes and the next one is refused.
+type Budget struct {
+    MaxUSD    float64
+    MaxTokens int
+}
+
+// BudgetExceededError reports which budget was exhausted and how much was spent
+type BudgetExceededError struct {
+    Scope       string    // "client" or "session"
+    SpentUSD    float64
+    SpentTokens int
+    Budget      Budget
+}
+
+func (e *BudgetExceededError) Error() string {
+    return fmt.Sprintf("%s budget exceeded: spent $%.4f / %d tokens (limit $%.4f / %d tokens)",
+        e.Scope, e.SpentUSD, e.SpentTokens, e.Budget.MaxUSD, e.Budget.MaxTokens)
+}
+
+// Is makes errors.Is(err, ErrBudgetExceeded) match
+func (e *BudgetExceededError) Is(target error) bool {
+    return target == ErrBudgetExceeded
+}
+
+// WithBudget limits the total spend of the client across all sessions
+func WithBudget(budget Budget) ClientOption {
+    return func(c *AnthropicClient) {
+        c.budget = budget
+    }
+}
+
+// WithSessionBudget limits the total spend of a single session
+func WithSessionBudget(budget Budget) SessionOption {
+    return func(s *Session) {
+        s.budget = budget
+    }
+}
+
+// checkBudget refuses to issue another request once the client or session
+// has reached its spending limit.
+func (c *AnthropicClient) checkBudget(session *Session) error {
+    if err := budgetError("client", c.budget, c.UsageReport()); err != nil {
+        return err
+    }
+    if session != nil {
+        if err := budgetError("session", session.budget, session.UsageReport()); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func budgetError(scope string, budget Budget, report UsageReport) error {
+    if budget.MaxUSD <= 0 && budget.MaxTokens <= 0 {
+        return nil
+    }
+    spentTokens := report.Total.TotalTokens()
+    if (budget.MaxUSD > 0 && report.Total.CostUSD >= budget.MaxUSD) ||
+        (budget.MaxTokens > 0 && spentTokens >= budget.MaxTokens) {
+        return &BudgetExceededError{
+            Scope:       scope,
+            SpentUSD:    report.Total.CostUSD,
+            SpentTokens: spentTokens,
+            Budget:      budget,
+        }
+    }
+    return nil
+}
@@ -0,0 +1,102 @@
+package anthropic
+
+import (
+    "bytes"
+    "compress/gzip"
+    "encoding/json"
+    "io"
+    "sync"
+    "sync/atomic"
+)
+
+// maxPooledBuffer caps the size of buffers kept for reuse, so one huge
+// request does not pin its memory for the life of the process
+const maxPooledBuffer = 8 << 20
+
+// requestBuffer holds the encoded body of one request. Tool loops resend the
+// whole conversation on every iteration, so the buffers and encoder are
+// pooled rather than grown from scratch each time. A buffer is shared by
+// the caller and the HTTP transport, which may still be reading the body
+// after the response arrives, and returns to the pool once both release it.
+type requestBuffer struct {
+    json bytes.Buffer
+    gzip bytes.Buffer
+    enc  *json.Encoder
+    refs int32
+}
+
+var requestBuffers = sync.Pool{
+    New: func() interface{} {
+        buf := &requestBuffer{}
+        buf.enc = json.NewEncoder(&buf.json)
+        return buf
+    },
+}
+
+var gzipWriters = sync.Pool{
+    New: func() interface{} {
+        return gzip.NewWriter(nil)
+    },
+}
+
+// getRequestBuffer returns an empty buffer held once, by the caller
+func getRequestBuffer() *requestBuffer {
+    buf := requestBuffers.Get().(*requestBuffer)
+    buf.refs = 1
+    return buf
+}
+
+// encode writes v as JSON and returns the encoding, which stays valid until
+// the buffer is released
+func (b *requestBuffer) encode(v interface{}) ([]byte, error) {
+    b.json.Reset()
+    if err := b.enc.Encode(v); err != nil {
+        return nil, err
+    }
+    // Encode terminates the value with a newline, which Marshal does not
+    return bytes.TrimSuffix(b.json.Bytes(), []byte("\n")), nil
+}
+
+// compress gzips data into the buffer and returns the result, which stays
+// valid until the buffer is released
+func (b *requestBuffer) compress(data []byte) ([]byte, error) {
+    b.gzip.Reset()
+    zw := gzipWriters.Get().(*gzip.Writer)
+    defer gzipWriters.Put(zw)
+    zw.Reset(&b.gzip)
+    if _, err := zw.Write(data); err != nil {
+        return nil, err
+    }
+    if err := zw.Close(); err != nil {
+        return nil, err
+    }
+    return b.gzip.Bytes(), nil
+}
+
+// body returns a reader over data, which must be held by b, for use as a
+// request body. The buffer is held until the transport closes the reader.
+func (b *requestBuffer) body(data []byte) io.ReadCloser {
+    atomic.AddInt32(&b.refs, 1)
+    return &bufferBody{Reader: bytes.NewReader(data), buf: b}
+}
+
+func (b *requestBuffer) release() {
+    if atomic.AddInt32(&b.refs, -1) != 0 {
+        return
+    }
+    if b.json.Cap() > maxPooledBuffer || b.gzip.Cap() > maxPooledBuffer {
+        return
+    }
+    requestBuffers.Put(b)
+}
+
+type bufferBody struct {
+    *bytes.Reader
+    buf  *requestBuffer
+    once sync.Once
+}
+
+func (r *bufferBody) Close() error {
+    r.once.Do(r.buf.release)
+    return nil
+}
@@ -0,0 +1,91 @@
+package anthropic
+
+import (
+    "encoding/json"
+    "fmt"
+    "strings"
+    "testing"
+)
+
+// benchmarkRequest builds a request like the ones a tool loop resends: a
+// system prompt, a few tools and several rounds of tool calls and results
+func benchmarkRequest() Request {
+    tools := []Tool{
+        {Name: "get_weather", Description: "Get the current weather for a city", InputSchema: InputSchema{
+            Type: "object",
+            Properties: map[string]Property{
+                "city":  {Type: "string", Description: "City name"},
+                "units": {Type: "string", Description: "celsius or fahrenheit", Enum: []string{"celsius", "fahrenheit"}},
+            },
+            Required: []string{"city"},
+        }},
+        {Name: "search_docs", Description: "Search the product documentation", InputSchema: InputSchema{
+            Type:       "object",
+            Properties: map[string]Property{"query": {Type: "string", Description: "Search terms"}},
+            Required:   []string{"query"},
+        }},
+    }
+
+    messages := []Message{{Role: RoleUser, Content: []MessageContent{{Type: ContentTypeText, Text: "Plan a trip across the cities in my itinerary."}}}}
+    for i := 0; i < 8; i++ {
+        id := fmt.Sprintf("toolu_%02d", i)
+        messages = append(messages,
+            Message{Role: RoleAssistant, Content: []MessageContent{
+                {Type: ContentTypeText, Text: "Let me check the weather there first."},
+                {Type: ContentTypeToolUse, ID: id, Name: "get_weather", Input: json.RawMessage(`{"city":"Paris","units":"celsius"}`)},
+            }},
+            Message{Role: RoleUser, Content: []MessageContent{
+                {Type: ContentTypeToolResult, ToolUseID: id, Content: strings.Repeat("Sunny, 21°C, light wind from the west. ", 20)},
+            }},
+        )
+    }
+
+    return Request{
+        Model:     "claude-3-5-sonnet-20241022",
+        System:    strings.Repeat("You are a helpful travel assistant. ", 30),
+        Messages:  messages,
+        MaxTokens: 1024,
+        Tools:     tools,
+    }
+}
+
+func BenchmarkEncodeRequest(b *testing.B) {
+    c := NewClient("test-key")
+    req := benchmarkRequest()
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        reqBody := req
+        buf, _, err := c.encodeRequest(&reqBody)
+        if err != nil {
+            b.Fatal(err)
+        }
+        buf.release()
+    }
+}
+
+// BenchmarkRequestBufferEncode measures the pooled encoding alone, without
+// the validation encodeRequest does first
+func BenchmarkRequestBufferEncode(b *testing.B) {
+    req := benchmarkRequest()
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        buf := getRequestBuffer()
+        if _, err := buf.encode(&req); err != nil {
+            b.Fatal(err)
+        }
+        buf.release()
+    }
+}
+
+func BenchmarkMarshalRequest(b *testing.B) {
+    req := benchmarkRequest()
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if _, err := json.Marshal(req); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
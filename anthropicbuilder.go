@@ -0,0 +1,291 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+)
+
+// MessageBuilder assembles a Message block by block. Each step is validated
+// as it is added; the first problem is kept and reported by Build.
+//
+//     msg, err := anthropic.NewMessage().User().
+//         Text("What is in this picture?").
+//         Image("image/png", pngBytes).
+//         Build()
+type MessageBuilder struct {
+    msg Message
+    err error
+}
+
+// NewMessage starts building a message
+func NewMessage() *MessageBuilder {
+    return &MessageBuilder{}
+}
+
+// User makes the message a user turn
+func (b *MessageBuilder) User() *MessageBuilder {
+    return b.role(RoleUser)
+}
+
+// Assistant makes the message an assistant turn, e.g. to prefill a response
+func (b *MessageBuilder) Assistant() *MessageBuilder {
+    return b.role(RoleAssistant)
+}
+
+func (b *MessageBuilder) role(role Role) *MessageBuilder {
+    if b.msg.Role != "" && b.msg.Role != role {
+        return b.fail("message role already set to %s", b.msg.Role)
+    }
+    b.msg.Role = role
+    return b
+}
+
+// Text appends a text block
+func (b *MessageBuilder) Text(text string) *MessageBuilder {
+    return b.add(NewTextBlock(text))
+}
+
+// Image appends a base64-encoded image block
+func (b *MessageBuilder) Image(mediaType string, data []byte) *MessageBuilder {
+    return b.add(NewImageBlock(mediaType, data))
+}
+
+// ImageURL appends an image block referencing an image by URL
+func (b *MessageBuilder) ImageURL(url string) *MessageBuilder {
+    return b.add(NewImageURLBlock(url))
+}
+
+// Document appends a PDF or plain-text document block
+func (b *MessageBuilder) Document(mediaType string, data []byte, title string) *MessageBuilder {
+    return b.add(NewDocumentBlock(mediaType, data, title))
+}
+
+// ToolUse appends a tool_use block; input is marshaled to JSON. Only valid in
+// assistant messages.
+func (b *MessageBuilder) ToolUse(id, name string, input interface{}) *MessageBuilder {
+    if b.msg.Role != RoleAssistant {
+        return b.fail("tool_use blocks belong in assistant messages")
+    }
+    return b.add(NewToolUseBlock(id, name, input))
+}
+
+// ToolResult appends a tool_result block answering the tool_use with the given
+// ID. Only valid in user messages.
+func (b *MessageBuilder) ToolResult(toolUseID, content string, isError bool) *MessageBuilder {
+    if b.msg.Role != RoleUser {
+        return b.fail("tool_result blocks belong in user messages")
+    }
+    return b.add(NewToolResultBlock(toolUseID, content, isError))
+}
+
+// Block appends a prepared content block
+func (b *MessageBuilder) Block(block MessageContent) *MessageBuilder {
+    if block.Type == "" {
+        return b.fail("content block is missing its type")
+    }
+    return b.add(block, nil)
+}
+
+func (b *MessageBuilder) add(block MessageContent, err error) *MessageBuilder {
+    if err != nil {
+        return b.fail("%v", err)
+    }
+    if b.err == nil {
+        b.msg.Content = append(b.msg.Content, block)
+    }
+    return b
+}
+
+func (b *MessageBuilder) fail(format string, args ...interface{}) *MessageBuilder {
+    if b.err == nil {
+        b.err = fmt.Errorf(format, args...)
+    }
+    return b
+}
+
+// Build returns the message, or the first error recorded while building it
+func (b *MessageBuilder) Build() (Message, error) {
+    if b.err != nil {
+        return Message{}, fmt.Errorf("invalid message: %w", b.err)
+    }
+    if b.msg.Role == "" {
+        return Message{}, fmt.Errorf("invalid message: role not set (call User or Assistant)")
+    }
+    if len(b.msg.Content) == 0 {
+        return Message{}, fmt.Errorf("invalid message: no content blocks")
+    }
+    return b.msg, nil
+}
+
+// RequestBuilder assembles a Request for Client.Send, validating each
+// setting as it is applied.
+//
+//     req, err := anthropic.NewRequest().
+//         Model("claude-3-5-sonnet-20241022").
+//         MaxTokens(1024).
+//         Tool(weatherTool).
+//         Message(anthropic.NewMessage().User().Text("Weather in Paris?")).
+//         Build()
+type RequestBuilder struct {
+    req Request
+    err error
+}
+
+// NewRequest starts building a request
+func NewRequest() *RequestBuilder {
+    return &RequestBuilder{}
+}
+
+// Model sets the model; required
+func (b *RequestBuilder) Model(model string) *RequestBuilder {
+    if model == "" {
+        return b.fail("model must not be empty")
+    }
+    b.req.Model = model
+    return b
+}
+
+// MaxTokens sets the output token limit; required
+func (b *RequestBuilder) MaxTokens(n int) *RequestBuilder {
+    if n <= 0 {
+        return b.fail("max_tokens must be positive, got %d", n)
+    }
+    b.req.MaxTokens = n
+    return b
+}
+
+// System sets the system prompt
+func (b *RequestBuilder) System(prompt string) *RequestBuilder {
+    b.req.System = prompt
+    return b
+}
+
+// SystemBlocks sets the system prompt as content blocks, replacing System
+func (b *RequestBuilder) SystemBlocks(blocks ...MessageContent) *RequestBuilder {
+    for _, block := range blocks {
+        if block.Type == "" {
+            return b.fail("system block is missing its type")
+        }
+    }
+    b.req.SystemBlocks = append(b.req.SystemBlocks, blocks...)
+    return b
+}
+
+// Temperature sets the sampling temperature (0.0-1.0)
+func (b *RequestBuilder) Temperature(t float64) *RequestBuilder {
+    if t < 0 || t > 1 {
+        return b.fail("temperature must be between 0 and 1, got %g", t)
+    }
+    b.req.Temperature = Float(t)
+    return b
+}
+
+// TopP sets nucleus sampling (0.0-1.0)
+func (b *RequestBuilder) TopP(p float64) *RequestBuilder {
+    if p < 0 || p > 1 {
+        return b.fail("top_p must be between 0 and 1, got %g", p)
+    }
+    b.req.TopP = Float(p)
+    return b
+}
+
+// TopK limits sampling to the K most likely tokens
+func (b *RequestBuilder) TopK(k int) *RequestBuilder {
+    if k < 0 {
+        return b.fail("top_k must not be negative, got %d", k)
+    }
+    b.req.TopK = Int(k)
+    return b
+}
+
+// StopSequences sets the strings that end generation
+func (b *RequestBuilder) StopSequences(sequences ...string) *RequestBuilder {
+    for _, seq := range sequences {
+        if seq == "" {
+            return b.fail("stop sequences must not be empty")
+        }
+    }
+    b.req.StopSequences = append(b.req.StopSequences, sequences...)
+    return b
+}
+
+// Tool adds a tool definition
+func (b *RequestBuilder) Tool(tool Tool) *RequestBuilder {
+    if err := validateToolDefinition(tool); err != nil {
+        return b.fail("%v", err)
+    }
+    for _, existing := range b.req.Tools {
+        if existing.Name == tool.Name {
+            return b.fail("tool %s added twice", tool.Name)
+        }
+    }
+    b.req.Tools = append(b.req.Tools, tool)
+    return b
+}
+
+// ToolChoice sets how Claude picks among the tools
+func (b *RequestBuilder) ToolChoice(choice ToolChoice) *RequestBuilder {
+    if err := validateToolChoice(&choice); err != nil {
+        return b.fail("%v", err)
+    }
+    b.req.ToolChoice = &choice
+    return b
+}
+
+// Message builds and appends a message
+func (b *RequestBuilder) Message(mb *MessageBuilder) *RequestBuilder {
+    msg, err := mb.Build()
+    if err != nil {
+        return b.fail("message %d: %v", len(b.req.Messages), err)
+    }
+    return b.Messages(msg)
+}
+
+// Messages appends ready-made messages
+func (b *RequestBuilder) Messages(msgs ...Message) *RequestBuilder {
+    b.req.Messages = append(b.req.Messages, msgs...)
+    return b
+}
+
+func (b *RequestBuilder) fail(format string, args ...interface{}) *RequestBuilder {
+    if b.err == nil {
+        b.err = fmt.Errorf(format, args...)
+    }
+    return b
+}
+
+// Build returns the request, or the first error recorded while building it.
+// It also checks the settings that only make sense together: required fields,
+// a leading user message, and a tool choice naming a defined tool.
+func (b *RequestBuilder) Build() (Request, error) {
+    if b.err != nil {
+        return Request{}, fmt.Errorf("invalid request: %w", b.err)
+    }
+    switch {
+    case b.req.Model == "":
+        return Request{}, fmt.Errorf("invalid request: model not set")
+    case b.req.MaxTokens == 0:
+        return Request{}, fmt.Errorf("invalid request: max_tokens not set")
+    case len(b.req.Messages) == 0:
+        return Request{}, fmt.Errorf("invalid request: no messages")
+    case b.req.Messages[0].Role != RoleUser:
+        return Request{}, fmt.Errorf("invalid request: first message must be from the user")
+    }
+    if choice := b.req.ToolChoice; choice != nil && choice.Type == ToolChoiceTool {
+        found := false
+        for _, tool := range b.req.Tools {
+            found = found || tool.Name == choice.Name
+        }
+        if !found {
+            return Request{}, fmt.Errorf("invalid request: tool_choice names undefined tool %s", choice.Name)
+        }
+    }
+    return b.req, nil
+}
+
+// Send issues a prepared Request, such as one from RequestBuilder. Like
+// Messages it is stateless and records nothing in any session.
+func (c *AnthropicClient) Send(ctx context.Context, req Request) (*AnthropicResponse, error) {
+    req.MCPServers = normalizeMCPServers(req.MCPServers)
+    return c.sendRequest(ctx, nil, req)
+}
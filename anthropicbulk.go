@@ -0,0 +1,84 @@
+package anthropic
+
+import (
+    "context"
+    "sync"
+)
+
+// BulkOptions configures BulkChat
+type BulkOptions struct {
+    Concurrency int                // Prompts in flight at once; 0 means 4
+    OnProgress  func(BulkProgress) // Called after each prompt completes, one call at a time
+}
+
+// BulkProgress reports how far a BulkChat run has got
+type BulkProgress struct {
+    Completed int // Prompts finished, successfully or not
+    Failed    int // Prompts that returned an error
+    Total     int
+}
+
+// BulkResult is the outcome of one BulkChat prompt
+type BulkResult struct {
+    Prompt   string
+    Response *AnthropicResponse
+    Err      error
+}
+
+// BulkChat sends each prompt as an independent single-turn conversation,
+// spreading them across a bounded pool of workers, and returns the results
+// in the order of prompts. Every prompt gets a fresh session, so the
+// client's system prompt, guardrails and rate limit apply but no history is
+// shared. A failed prompt does not stop the others; once ctx is done the
+// remaining prompts fail with its error.
+func (c *AnthropicClient) BulkChat(ctx context.Context, prompts []string, params *MessageParams, options BulkOptions, opts ...RequestOption) []BulkResult {
+    workers := options.Concurrency
+    if workers <= 0 {
+        workers = 4
+    }
+    if workers > len(prompts) {
+        workers = len(prompts)
+    }
+    logMessage("Starting bulk chat of %d prompts with %d workers", len(prompts), workers)
+
+    results := make([]BulkResult, len(prompts))
+    progress := BulkProgress{Total: len(prompts)}
+    var mu sync.Mutex
+    finish := func(i int, resp *AnthropicResponse, err error) {
+        mu.Lock()
+        defer mu.Unlock()
+        results[i] = BulkResult{Prompt: prompts[i], Response: resp, Err: err}
+        progress.Completed++
+        if err != nil {
+            progress.Failed++
+        }
+        if options.OnProgress != nil {
+            options.OnProgress(progress)
+        }
+    }
+
+    jobs := make(chan int)
+    var wg sync.WaitGroup
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := range jobs {
+                if err := ctx.Err(); err != nil {
+                    finish(i, nil, err)
+                    continue
+                }
+                resp, err := c.NewSession().ChatMe(ctx, prompts[i], params, opts...)
+                finish(i, resp, err)
+            }
+        }()
+    }
+    for i := range prompts {
+        jobs <- i
+    }
+    close(jobs)
+    wg.Wait()
+
+    logMessage("Bulk chat finished: %d of %d prompts failed", progress.Failed, progress.Total)
+    return results
+}
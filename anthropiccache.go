@@ -0,0 +1,182 @@
+package anthropic
+
+import (
+    "container/list"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "sync"
+    "time"
+)
+
+const defaultCacheEntries = 1024
+
+// WithResponseCache keeps successful responses for ttl and answers identical
+// requests from memory, so repeated calls (retries from an upstream service,
+// development reloads) cost no tokens. Requests are identical when their
+// encoded bodies match: the same model, system prompt, messages, tools and
+// parameters. At most maxEntries responses are kept, evicting the least
+// recently used; 0 means 1024. Cached answers are not recorded as usage,
+// passed to hooks, or audited again. Streams are never cached. Clients
+// derived with With share the cache unless they set their own or use
+// different credentials; ClientPool tenants never share it.
+func WithResponseCache(ttl time.Duration, maxEntries int) ClientOption {
+    return func(c *AnthropicClient) {
+        if ttl <= 0 {
+            return
+        }
+        if maxEntries <= 0 {
+            maxEntries = defaultCacheEntries
+        }
+        c.cache = &responseCache{
+            ttl:        ttl,
+            maxEntries: maxEntries,
+            entries:    make(map[string]*list.Element),
+            order:      list.New(),
+        }
+    }
+}
+
+// ClearResponseCache empties the client's response cache
+func (c *AnthropicClient) ClearResponseCache() {
+    c.cache.clear()
+}
+
+// cacheKey hashes an encoded request body, returning "" when caching is off
+func (c *AnthropicClient) cacheKey(jsonData []byte) string {
+    if c.cache == nil {
+        return ""
+    }
+    sum := sha256.Sum256(jsonData)
+    return hex.EncodeToString(sum[:])
+}
+
+// responseCache is an LRU cache of responses with a fixed time to live. Its
+// methods do nothing on a nil cache.
+type responseCache struct {
+    mu         sync.Mutex
+    ttl        time.Duration
+    maxEntries int
+    entries    map[string]*list.Element
+    order      *list.List // Most recently used first
+}
+
+type cacheEntry struct {
+    key     string
+    resp    *AnthropicResponse
+    expires time.Time
+}
+
+func (rc *responseCache) get(key string) (*AnthropicResponse, bool) {
+    if rc == nil || key == "" {
+        return nil, false
+    }
+    rc.mu.Lock()
+    defer rc.mu.Unlock()
+
+    elem, ok := rc.entries[key]
+    if !ok {
+        return nil, false
+    }
+    entry := elem.Value.(*cacheEntry)
+    if time.Now().After(entry.expires) {
+        rc.order.Remove(elem)
+        delete(rc.entries, key)
+        return nil, false
+    }
+    rc.order.MoveToFront(elem)
+    return copyResponse(entry.resp), true
+}
+
+func (rc *responseCache) put(key string, resp *AnthropicResponse) {
+    if rc == nil || key == "" {
+        return
+    }
+    rc.mu.Lock()
+    defer rc.mu.Unlock()
+
+    entry := &cacheEntry{key: key, resp: copyResponse(resp), expires: time.Now().Add(rc.ttl)}
+    if elem, ok := rc.entries[key]; ok {
+        elem.Value = entry
+        rc.order.MoveToFront(elem)
+        return
+    }
+    rc.entries[key] = rc.order.PushFront(entry)
+    for rc.order.Len() > rc.maxEntries {
+        oldest := rc.order.Back()
+        rc.order.Remove(oldest)
+        delete(rc.entries, oldest.Value.(*cacheEntry).key)
+    }
+}
+
+// empty returns a new, empty cache with rc's settings, or nil for a nil cache
+func (rc *responseCache) empty() *responseCache {
+    if rc == nil {
+        return nil
+    }
+    return &responseCache{
+        ttl:        rc.ttl,
+        maxEntries: rc.maxEntries,
+        entries:    make(map[string]*list.Element),
+        order:      list.New(),
+    }
+}
+
+func (rc *responseCache) clear() {
+    if rc == nil {
+        return
+    }
+    rc.mu.Lock()
+    defer rc.mu.Unlock()
+    rc.entries = make(map[string]*list.Element)
+    rc.order.Init()
+}
+
+// copyResponse deep-copies resp, so callers editing any part of the content,
+// including tool inputs and nested blocks, do not change the stored entry
+func copyResponse(resp *AnthropicResponse) *AnthropicResponse {
+    dup := *resp
+    dup.Content = copyContent(resp.Content)
+    if resp.Usage.ServerToolUse != nil {
+        serverToolUse := *resp.Usage.ServerToolUse
+        dup.Usage.ServerToolUse = &serverToolUse
+    }
+    if resp.TerminalCall != nil {
+        call := *resp.TerminalCall
+        call.Input = append(json.RawMessage(nil), call.Input...)
+        call.Result.Blocks = copyContent(call.Result.Blocks)
+        dup.TerminalCall = &call
+    }
+    return &dup
+}
+
+// copyContent deep-copies content blocks and everything they point to
+func copyContent(contents []MessageContent) []MessageContent {
+    if contents == nil {
+        return nil
+    }
+    dup := make([]MessageContent, len(contents))
+    for i, content := range contents {
+        if content.Input != nil {
+            content.Input = append(json.RawMessage(nil), content.Input...)
+        }
+        if content.Citations != nil {
+            content.Citations = append([]Citation(nil), content.Citations...)
+        }
+        if content.Source != nil {
+            source := *content.Source
+            content.Source = &source
+        }
+        if content.CacheControl != nil {
+            cacheControl := *content.CacheControl
+            content.CacheControl = &cacheControl
+        }
+        if content.ReturnCode != nil {
+            returnCode := *content.ReturnCode
+            content.ReturnCode = &returnCode
+        }
+        content.ContentBlocks = copyContent(content.ContentBlocks)
+        dup[i] = content
+    }
+    return dup
+}
@@ -0,0 +1,78 @@
+package anthropic
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "strings"
+)
+
+const classifySystemPrompt = "You are a precise text classifier. Assign the text to the single label that fits it best, " +
+    "even when none fits perfectly, and rate your confidence honestly."
+
+// Classification is the label Classify chose for a text
+type Classification struct {
+    Label      string  `json:"label"`
+    Confidence float64 `json:"confidence"` // From 0 (a guess) to 1 (certain)
+    Rationale  string  `json:"rationale"`  // Why the label fits, in a sentence or two
+}
+
+// Classify assigns text exactly one of labels, for routing and moderation.
+// Claude is forced to answer through a tool whose label field is an enum of
+// labels, so the result needs no parsing; an answer outside the set is sent
+// back for correction as in ChatJSON. The system prompt is Classify's own
+// unless opts set one with WithSystem.
+func (c *AnthropicClient) Classify(ctx context.Context, text string, labels []string, opts ...RequestOption) (*Classification, error) {
+    if strings.TrimSpace(text) == "" {
+        return nil, fmt.Errorf("cannot classify empty text")
+    }
+    if len(labels) < 2 {
+        return nil, fmt.Errorf("classification needs at least two labels, got %d", len(labels))
+    }
+    seen := make(map[string]bool, len(labels))
+    for _, label := range labels {
+        if label == "" || seen[label] {
+            return nil, fmt.Errorf("classification labels must be unique and non-empty: %q", label)
+        }
+        seen[label] = true
+    }
+
+    schema := InputSchema{
+        Type: "object",
+        Properties: map[string]Property{
+            "label":      {Type: "string", Description: "The label that fits the text best", Enum: labels},
+            "confidence": {Type: "number", Description: "Confidence in the label, from 0 (a guess) to 1 (certain)"},
+            "rationale":  {Type: "string", Description: "Why the label fits, in a sentence or two"},
+        },
+        Required: []string{"label", "confidence", "rationale"},
+    }
+    var result Classification
+    decode := func(input json.RawMessage) error {
+        if err := ValidateAgainstSchema(schema, input); err != nil {
+            return err
+        }
+        var out Classification
+        if err := json.Unmarshal(input, &out); err != nil {
+            return fmt.Errorf("output cannot be decoded: %w", err)
+        }
+        if out.Confidence < 0 || out.Confidence > 1 {
+            return fmt.Errorf("confidence must be between 0 and 1, got %g", out.Confidence)
+        }
+        result = out
+        return nil
+    }
+
+    params := c.resolveParams(nil, opts)
+    systemPrompt := params.System
+    if systemPrompt == "" {
+        systemPrompt = classifySystemPrompt
+    }
+    prompt := fmt.Sprintf("Classify the text below as one of: %s.\n\n<text>\n%s\n</text>", strings.Join(labels, ", "), text)
+
+    logMessage("Classifying %d bytes into %d labels", len(text), len(labels))
+    if _, err := c.chatStructured(ctx, nil, prompt, systemPrompt, schema, params, c.outputRepairLimit(params), decode); err != nil {
+        return nil, fmt.Errorf("classification error: %w", err)
+    }
+    logMessage("Classified as %s (confidence %.2f)", result.Label, result.Confidence)
+    return &result, nil
+}
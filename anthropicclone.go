@@ -0,0 +1,73 @@
+package anthropic
+
+// With returns a derived client with opts applied on top of c's settings,
+// for cheap per-tenant or per-feature variants:
+//
+//     support := client.With(WithSystemPrompt("You are a support agent."),
+//         WithDefaultParams(anthropic.MessageParams{Model: "claude-3-5-haiku-20241022", MaxTokens: 1024}))
+//
+// The clone shares c's HTTP client, and so its transport and connection pool,
+// along with its tool registry, rate limit and response cache. Everything else is copied, so
+// options applied to one client never affect the other. The clone starts with
+// its own empty default session and its own usage totals, tool statistics and
+// budget accounting. A clone given its own credentials also gets its own
+// empty response cache, so it is never answered with responses paid for by
+// another key.
+func (c *AnthropicClient) With(opts ...ClientOption) *AnthropicClient {
+    logMessage("Deriving AnthropicClient")
+    clone := &AnthropicClient{
+        apiKey:            c.apiKey,
+        apiKeyProvider:    c.apiKeyProvider,
+        tokenSource:       c.tokenSource,
+        defaultParams:     c.defaultParams,
+        httpClient:        c.httpClient,
+        maxConvLength:     c.maxConvLength,
+        systemPrompt:      c.systemPrompt,
+        systemTemplate:    c.systemTemplate,
+        hooks:             append(c.hooks[:0:0], c.hooks...),
+        titleModel:        c.titleModel,
+        budget:            c.budget,
+        usageCallbacks:    append(c.usageCallbacks[:0:0], c.usageCallbacks...),
+        registry:          c.registry,
+        toolMiddleware:    append(c.toolMiddleware[:0:0], c.toolMiddleware...),
+        maxToolIterations: c.maxToolIterations,
+        maxContinuations:  c.maxContinuations,
+        strictValidation:  c.strictValidation,
+        repairConversation: c.repairConversation,
+        streamIdleTimeout: c.streamIdleTimeout,
+        requestTimeout:    c.requestTimeout,
+        idempotencyWindow: c.idempotencyWindow,
+        compressAbove:     c.compressAbove,
+        overflowStrategy:  c.overflowStrategy,
+        contextWindow:     c.contextWindow,
+        contextWarnAt:     c.contextWarnAt,
+        contextWarn:       c.contextWarn,
+        rateLimiter:       c.rateLimiter,
+        cache:             c.cache,
+        audit:             c.audit,
+        piiRedactor:       c.piiRedactor,
+        inputGuardrails:   append(c.inputGuardrails[:0:0], c.inputGuardrails...),
+        outputGuardrails:  append(c.outputGuardrails[:0:0], c.outputGuardrails...),
+    }
+    if c.pricing != nil {
+        clone.pricing = make(map[string]ModelPricing, len(c.pricing))
+        for model, p := range c.pricing {
+            clone.pricing[model] = p
+        }
+    }
+    if c.models != nil {
+        clone.models = make(map[string]ModelCapabilities, len(c.models))
+        for model, caps := range c.models {
+            clone.models[model] = caps
+        }
+    }
+
+    for _, opt := range opts {
+        opt(clone)
+    }
+    if clone.ownCredentials && clone.cache == c.cache {
+        clone.cache = c.cache.empty()
+    }
+    clone.session = clone.NewSession()
+    return clone
+}
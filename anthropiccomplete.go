@@ -0,0 +1,150 @@
+package anthropic
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "strings"
+    "time"
+)
+
+const defaultCompleteEndpoint = "https://api.anthropic.com/v1/complete"
+
+// Turn markers of the legacy Text Completions prompt format
+const (
+    HumanPrompt = "\n\nHuman:"
+    AIPrompt    = "\n\nAssistant:"
+)
+
+// CompletionRequest is a legacy Text Completions request. Model and
+// MaxTokensToSample fall back to the client defaults when unset.
+type CompletionRequest struct {
+    Model             string                 `json:"model"`
+    Prompt            string                 `json:"prompt"` // See CompletionPrompt
+    MaxTokensToSample int                    `json:"max_tokens_to_sample"`
+    StopSequences     []string               `json:"stop_sequences,omitempty"`
+    Temperature       *float64               `json:"temperature,omitempty"`
+    TopP              *float64               `json:"top_p,omitempty"`
+    TopK              *int                   `json:"top_k,omitempty"`
+    Metadata          map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CompletionResponse is the reply to a CompletionRequest
+type CompletionResponse struct {
+    Type       string `json:"type"`
+    ID         string `json:"id"`
+    Completion string `json:"completion"`
+    StopReason string `json:"stop_reason"` // "stop_sequence" or "max_tokens"
+    Stop       string `json:"stop"`        // The stop sequence that ended generation, if any
+    Model      string `json:"model"`
+}
+
+// CompletionPrompt wraps a single user message in the Human/Assistant turn
+// markers the Text Completions endpoint requires
+func CompletionPrompt(text string) string {
+    return HumanPrompt + " " + strings.TrimSpace(text) + AIPrompt
+}
+
+// Complete sends a request to the legacy /v1/complete endpoint, for old
+// integrations and gateways that only speak the prompt/completion protocol.
+// New code should use the Messages API. Requests honor the client's budget,
+// rate limit, timeout and audit store, but the endpoint reports no token
+// usage, so completions are not counted in UsageReport, and guardrails,
+// hooks and the response cache do not apply.
+func (c *AnthropicClient) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+    if err := c.inflight.begin(); err != nil {
+        logMessage("Refusing request: %v", err)
+        return nil, err
+    }
+    defer c.inflight.end()
+
+    if req.Model == "" {
+        req.Model = c.defaultParams.Model
+    }
+    if req.MaxTokensToSample <= 0 {
+        req.MaxTokensToSample = c.resolveMaxTokens(req.Model, c.defaultParams.MaxTokens)
+    }
+    switch {
+    case req.Model == "":
+        return nil, &RequestValidationError{Problems: []string{"model is not set"}}
+    case !strings.HasPrefix(req.Prompt, HumanPrompt) || !strings.Contains(req.Prompt, AIPrompt):
+        return nil, &RequestValidationError{Model: req.Model, Problems: []string{
+            fmt.Sprintf("prompt must start with %q and contain an %q turn", HumanPrompt, AIPrompt),
+        }}
+    }
+
+    if err := c.checkBudget(nil); err != nil {
+        logMessage("Refusing request: %v", err)
+        return nil, err
+    }
+    if c.rateLimiter != nil {
+        if err := c.rateLimiter.wait(ctx); err != nil {
+            logMessage("Request not sent: %v", err)
+            return nil, err
+        }
+    }
+    if c.requestTimeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+        defer cancel()
+    }
+
+    jsonData, err := json.Marshal(req)
+    if err != nil {
+        return nil, fmt.Errorf("error marshaling request: %w", err)
+    }
+    httpReq, err := http.NewRequestWithContext(ctx, "POST", defaultCompleteEndpoint, bytes.NewReader(jsonData))
+    if err != nil {
+        return nil, fmt.Errorf("error creating request: %w", err)
+    }
+    httpReq.Header.Set("Content-Type", "application/json")
+    httpReq.Header.Set("anthropic-version", "2023-06-01")
+    if err := c.setAuthHeaders(ctx, httpReq); err != nil {
+        logMessage("Error setting credentials: %v", err)
+        return nil, err
+    }
+
+    logMessage("Sending completion request for %s", req.Model)
+    start := time.Now()
+    record := AuditRecord{Time: start, Model: req.Model, Request: jsonData}
+    resp, err := c.httpClient.Do(httpReq)
+    if err != nil {
+        logMessage("API request failed: %v", err)
+        err = fmt.Errorf("error sending request: %w", err)
+        record.Error, record.Latency = err.Error(), time.Since(start)
+        c.auditExchange(ctx, nil, record)
+        return nil, err
+    }
+    defer resp.Body.Close()
+    record.RequestID = resp.Header.Get("request-id")
+    record.StatusCode = resp.StatusCode
+
+    body, err := ioutil.ReadAll(resp.Body)
+    record.Response, record.Latency = body, time.Since(start)
+    if err != nil {
+        err = fmt.Errorf("error reading response: %w", err)
+        record.Error = err.Error()
+        c.auditExchange(ctx, nil, record)
+        return nil, err
+    }
+    if resp.StatusCode != http.StatusOK {
+        err := responseError(resp.StatusCode, body)
+        record.Error = err.Error()
+        c.auditExchange(ctx, nil, record)
+        return nil, err
+    }
+
+    var result CompletionResponse
+    if err := json.Unmarshal(body, &result); err != nil {
+        logMessage("Error parsing completion response: %v", err)
+        return nil, fmt.Errorf("error parsing response: %w", err)
+    }
+    if err := c.auditExchange(ctx, nil, record); err != nil {
+        return nil, err
+    }
+    logMessage("Completion finished with stop reason %s in %s", result.StopReason, record.Latency)
+    return &result, nil
+}
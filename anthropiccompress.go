@@ -0,0 +1,32 @@
+package anthropic
+
+// WithRequestCompression gzips request bodies of at least minBytes, sending
+// them with Content-Encoding: gzip. Large base64 images, PDFs and long
+// conversations compress well, which shortens uploads on slow links. Only
+// enable it for endpoints or gateways that accept compressed request bodies.
+func WithRequestCompression(minBytes int) ClientOption {
+    return func(c *AnthropicClient) {
+        if minBytes < 0 {
+            return
+        }
+        if minBytes == 0 {
+            minBytes = 1
+        }
+        c.compressAbove = minBytes
+    }
+}
+
+// compressBody returns data gzipped into buf when compression is enabled and
+// data is large enough, reporting whether it was compressed
+func (c *AnthropicClient) compressBody(buf *requestBuffer, data []byte) ([]byte, bool) {
+    if c.compressAbove == 0 || len(data) < c.compressAbove {
+        return data, false
+    }
+    compressed, err := buf.compress(data)
+    if err != nil {
+        logMessage("Sending request uncompressed: %v", err)
+        return data, false
+    }
+    logMessage("Compressed request body from %d to %d bytes", len(data), len(compressed))
+    return compressed, true
+}
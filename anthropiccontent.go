@@ -0,0 +1,116 @@
+package anthropic
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+)
+
+// messageContentAlias has MessageContent's fields without its JSON methods
+type messageContentAlias MessageContent
+
+// MarshalJSON emits exactly the fields that belong to the block's type, so a
+// stray field left on a reused MessageContent is never sent. tool_result
+// content is emitted as a block array when ContentBlocks is set, and as the
+// plain Content string otherwise.
+func (m MessageContent) MarshalJSON() ([]byte, error) {
+    m = m.wireFields()
+    if len(m.ContentBlocks) == 0 {
+        return json.Marshal(messageContentAlias(m))
+    }
+    if m.contentObject && len(m.ContentBlocks) == 1 {
+        return json.Marshal(struct {
+            messageContentAlias
+            Content MessageContent `json:"content"`
+        }{
+            messageContentAlias: messageContentAlias(m),
+            Content:             m.ContentBlocks[0],
+        })
+    }
+    return json.Marshal(struct {
+        messageContentAlias
+        Content []MessageContent `json:"content"`
+    }{
+        messageContentAlias: messageContentAlias(m),
+        Content:             m.ContentBlocks,
+    })
+}
+
+// UnmarshalJSON accepts "content" as a string, an array of blocks, or a single
+// block object (as in web search errors)
+func (m *MessageContent) UnmarshalJSON(data []byte) error {
+    var raw struct {
+        messageContentAlias
+        Content json.RawMessage `json:"content,omitempty"`
+    }
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return err
+    }
+    *m = MessageContent(raw.messageContentAlias)
+    m.Content = ""
+    m.ContentBlocks = nil
+    m.contentObject = false
+
+    content := bytes.TrimSpace(raw.Content)
+    if len(content) == 0 {
+        return nil
+    }
+    switch content[0] {
+    case '"':
+        return json.Unmarshal(content, &m.Content)
+    case '[':
+        return json.Unmarshal(content, &m.ContentBlocks)
+    case '{':
+        var block MessageContent
+        if err := json.Unmarshal(content, &block); err != nil {
+            return err
+        }
+        m.ContentBlocks = []MessageContent{block}
+        m.contentObject = true
+        return nil
+    case 'n':
+        return nil
+    default:
+        return fmt.Errorf("unsupported content value for %s block: %s", m.Type, content)
+    }
+}
+
+// wireFields keeps only the fields the API accepts for m's block type. Types
+// without a fixed field set, such as server tool results, pass through whole
+// so they round-trip unchanged.
+func (m MessageContent) wireFields() MessageContent {
+    out := MessageContent{Type: m.Type}
+    switch m.Type {
+    case ContentTypeText:
+        out.Text = m.Text
+        out.Citations = m.Citations
+    case ContentTypeToolUse, ContentTypeServerToolUse, ContentTypeMCPToolUse:
+        out.ID = m.ID
+        out.Name = m.Name
+        out.Input = m.Input
+        if len(out.Input) == 0 {
+            out.Input = json.RawMessage(`{}`) // input is required, even when empty
+        }
+        if m.Type == ContentTypeMCPToolUse {
+            out.ServerName = m.ServerName
+        }
+    case ContentTypeToolResult, ContentTypeMCPToolResult:
+        out.ToolUseID = m.ToolUseID
+        out.Content = m.Content
+        out.ContentBlocks = m.ContentBlocks
+        out.contentObject = m.contentObject
+        out.IsError = m.IsError
+    case ContentTypeThinking:
+        out.Thinking = m.Thinking
+        out.Signature = m.Signature
+    case ContentTypeImage:
+        out.Source = m.Source
+    case ContentTypeDocument:
+        out.Source = m.Source
+        out.Title = m.Title
+    default:
+        return m
+    }
+    out.CacheControl = m.CacheControl
+    return out
+}
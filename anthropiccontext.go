@@ -0,0 +1,120 @@
+package anthropic
+
+import "encoding/json"
+
+// ContextWindow returns the context window of a model in the built-in
+// capability table, in tokens
+func ContextWindow(model string) int {
+    if caps, ok := lookupModel(defaultModelCapabilities, model); ok {
+        return caps.ContextWindow
+    }
+    return defaultContextWindow
+}
+
+// ContextUsage reports how much of the model's context window a
+// conversation fills
+type ContextUsage struct {
+    Used    int     // Estimated tokens in the conversation
+    Limit   int     // Context window of the model
+    Percent float64 // Used as a percentage of Limit
+}
+
+func newContextUsage(used, limit int) ContextUsage {
+    return ContextUsage{Used: used, Limit: limit, Percent: 100 * float64(used) / float64(limit)}
+}
+
+// WithContextWindow overrides the context window used by ContextUsage, e.g.
+// for a model running with an extended context beta
+func WithContextWindow(tokens int) ClientOption {
+    return func(c *AnthropicClient) {
+        if tokens > 0 {
+            c.contextWindow = tokens
+        }
+    }
+}
+
+// WithContextWarning calls fn when a conversation reaches percent of the
+// context window, so callers can summarize or start a new session before
+// requests fail. It fires once per crossing, during the call that crossed
+// the threshold, and must not call methods of the session it is given.
+func WithContextWarning(percent float64, fn func(s *Session, usage ContextUsage)) ClientOption {
+    return func(c *AnthropicClient) {
+        if percent > 0 && fn != nil {
+            c.contextWarnAt = percent
+            c.contextWarn = fn
+        }
+    }
+}
+
+// ContextUsage reports how full the default session's context window is
+func (c *AnthropicClient) ContextUsage() ContextUsage {
+    return c.session.ContextUsage()
+}
+
+// ContextUsage reports how full the session's context window is. The count
+// comes from the token usage of the last request, plus an estimate for any
+// messages added since; before the first request it is an estimate.
+func (s *Session) ContextUsage() ContextUsage {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    used := estimateTokens(s.conversation)
+    if s.contextLen > 0 && s.contextLen <= len(s.conversation) {
+        used = s.contextTokens + estimateTokens(s.conversation[s.contextLen:])
+    }
+    return newContextUsage(used, s.contextLimit())
+}
+
+// measureContext records the conversation size reported by a response to the
+// session's history, which the reply is about to extend. Callers must hold s.mu.
+func (s *Session) measureContext(model string, resp *AnthropicResponse) {
+    u := resp.Usage
+    s.contextModel = model
+    s.contextTokens = u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens + u.OutputTokens
+    s.contextLen = len(s.conversation)
+    if len(resp.Content) > 0 {
+        s.contextLen++
+    }
+
+    c := s.client
+    if c.contextWarn == nil {
+        return
+    }
+    usage := newContextUsage(s.contextTokens, s.contextLimit())
+    switch {
+    case usage.Percent < c.contextWarnAt:
+        s.contextWarned = false
+    case !s.contextWarned:
+        s.contextWarned = true
+        logMessage("Session %s is using %.0f%% of the context window", s.id, usage.Percent)
+        c.contextWarn(s, usage)
+    }
+}
+
+// contextLimit returns the window of the model last used by the session
+func (s *Session) contextLimit() int {
+    if s.client.contextWindow > 0 {
+        return s.client.contextWindow
+    }
+    model := s.contextModel
+    if model == "" {
+        model = s.client.defaultParams.Model
+    }
+    if caps, ok := s.client.ModelCapabilities(model); ok && caps.ContextWindow > 0 {
+        return caps.ContextWindow
+    }
+    return defaultContextWindow
+}
+
+// estimateTokens approximates the tokens in messages at four bytes of JSON
+// per token
+func estimateTokens(messages []Message) int {
+    if len(messages) == 0 {
+        return 0
+    }
+    data, err := json.Marshal(messages)
+    if err != nil {
+        return 0
+    }
+    return len(data) / 4
+}
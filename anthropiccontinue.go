@@ -0,0 +1,86 @@
+package anthropic
+
+import (
+    "context"
+    "strings"
+)
+
+// WithAutoContinue resumes answers cut off at max_tokens up to n times. The
+// partial answer is sent back as an assistant prefill so Claude carries on
+// where it stopped, and the pieces are returned as one response.
+// MessageParams.MaxContinuations overrides it per call.
+func WithAutoContinue(n int) ClientOption {
+    return func(c *AnthropicClient) {
+        if n > 0 {
+            c.maxContinuations = n
+        }
+    }
+}
+
+// continuationLimit resolves how often a truncated answer is continued
+func (c *AnthropicClient) continuationLimit(params *MessageParams) int {
+    switch {
+    case params.MaxContinuations < 0:
+        return 0
+    case params.MaxContinuations > 0:
+        return params.MaxContinuations
+    }
+    return c.maxContinuations
+}
+
+// continueTruncated resends reqBody while the answer stops at max_tokens,
+// stitching each continuation onto the last assistant message in the history
+// and onto response, so both hold the answer as one turn. Callers must hold
+// s.mu.
+func (s *Session) continueTruncated(ctx context.Context, reqBody Request, response *AnthropicResponse, limit int) (*AnthropicResponse, error) {
+    for i := 0; response.StopReason == StopReasonMaxTokens && i < limit && endsInText(response.Content); i++ {
+        logMessage("Response reached max_tokens; continuing (%d/%d)", i+1, limit)
+        // The API rejects a prefill ending in whitespace
+        last := &s.conversation[len(s.conversation)-1]
+        last.Content = trimTrailingText(last.Content)
+        reqBody.Messages = s.conversation
+
+        more, err := s.client.sendRequest(ctx, s, reqBody)
+        if err != nil {
+            logMessage("Chat continuation failed: %v", err)
+            return nil, err
+        }
+        if len(more.Content) > 0 {
+            last = &s.conversation[len(s.conversation)-1]
+            last.Content = stitchContinuation(last.Content, more.Content)
+        }
+
+        usage := response.Usage
+        usage.Add(more.Usage)
+        more.Content = stitchContinuation(response.Content, more.Content)
+        more.Usage = usage
+        response = more
+    }
+    return response, nil
+}
+
+// endsInText reports whether content ends with a text block Claude can continue
+func endsInText(content []MessageContent) bool {
+    n := len(content)
+    return n > 0 && content[n-1].Type == ContentTypeText && strings.TrimSpace(content[n-1].Text) != ""
+}
+
+// trimTrailingText returns content with trailing whitespace removed from its
+// final text block, copying rather than modifying the caller's slice
+func trimTrailingText(content []MessageContent) []MessageContent {
+    trimmed := append(content[:0:0], content...)
+    last := &trimmed[len(trimmed)-1]
+    last.Text = strings.TrimRight(last.Text, " \t\r\n")
+    return trimmed
+}
+
+// stitchContinuation joins a continuation onto the content it continues,
+// merging the text block that was cut off with the first block of more
+func stitchContinuation(content, more []MessageContent) []MessageContent {
+    stitched := trimTrailingText(content)
+    if len(more) > 0 && more[0].Type == ContentTypeText {
+        stitched[len(stitched)-1].Text += more[0].Text
+        more = more[1:]
+    }
+    return append(stitched, more...)
+}
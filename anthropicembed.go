@@ -0,0 +1,242 @@
+package anthropic
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "hash/fnv"
+    "io/ioutil"
+    "math"
+    "net/http"
+    "strings"
+    "unicode"
+)
+
+// EmbeddingInput tells an embedder whether it is embedding a search query or
+// a document to be searched; some models embed the two differently
+type EmbeddingInput string
+
+const (
+    EmbedQuery    EmbeddingInput = "query"
+    EmbedDocument EmbeddingInput = "document"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are. Anthropic has no embedding endpoint, so memory and
+// retrieval take one of these: VoyageEmbedder, HashingEmbedder, or an
+// adapter for another provider. Embed returns one vector per text, in order.
+type Embedder interface {
+    Embed(ctx context.Context, texts []string, input EmbeddingInput) ([][]float32, error)
+}
+
+// embedOne embeds a single text, checking the embedder returned a vector
+func embedOne(ctx context.Context, embedder Embedder, text string, input EmbeddingInput) ([]float32, error) {
+    vectors, err := embedder.Embed(ctx, []string{text}, input)
+    if err != nil {
+        return nil, err
+    }
+    if len(vectors) != 1 {
+        return nil, fmt.Errorf("embedder returned %d vectors for 1 text", len(vectors))
+    }
+    return vectors[0], nil
+}
+
+const (
+    defaultVoyageEndpoint = "https://api.voyageai.com/v1/embeddings"
+    defaultVoyageModel    = "voyage-3"
+    voyageBatchSize       = 128
+)
+
+// VoyageEmbedder embeds texts with the Voyage AI API, which Anthropic
+// recommends for embeddings
+type VoyageEmbedder struct {
+    APIKey     string
+    Model      string       // Defaults to voyage-3
+    Endpoint   string       // Defaults to the public embeddings endpoint
+    HTTPClient *http.Client // Defaults to http.DefaultClient
+}
+
+// NewVoyageEmbedder creates a VoyageEmbedder using the default model
+func NewVoyageEmbedder(apiKey string) *VoyageEmbedder {
+    return &VoyageEmbedder{APIKey: apiKey, Model: defaultVoyageModel}
+}
+
+type voyageRequest struct {
+    Input     []string       `json:"input"`
+    Model     string         `json:"model"`
+    InputType EmbeddingInput `json:"input_type,omitempty"`
+}
+
+type voyageResponse struct {
+    Data []struct {
+        Embedding []float32 `json:"embedding"`
+        Index     int       `json:"index"`
+    } `json:"data"`
+}
+
+// Embed sends the texts to Voyage AI in batches of up to 128
+func (e *VoyageEmbedder) Embed(ctx context.Context, texts []string, input EmbeddingInput) ([][]float32, error) {
+    vectors := make([][]float32, 0, len(texts))
+    for start := 0; start < len(texts); start += voyageBatchSize {
+        end := start + voyageBatchSize
+        if end > len(texts) {
+            end = len(texts)
+        }
+        batch, err := e.embedBatch(ctx, texts[start:end], input)
+        if err != nil {
+            return nil, err
+        }
+        vectors = append(vectors, batch...)
+    }
+    return vectors, nil
+}
+
+func (e *VoyageEmbedder) embedBatch(ctx context.Context, texts []string, input EmbeddingInput) ([][]float32, error) {
+    model := e.Model
+    if model == "" {
+        model = defaultVoyageModel
+    }
+    endpoint := e.Endpoint
+    if endpoint == "" {
+        endpoint = defaultVoyageEndpoint
+    }
+    httpClient := e.HTTPClient
+    if httpClient == nil {
+        httpClient = http.DefaultClient
+    }
+
+    jsonData, err := json.Marshal(voyageRequest{Input: texts, Model: model, InputType: input})
+    if err != nil {
+        return nil, fmt.Errorf("error marshaling embedding request: %w", err)
+    }
+    req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonData))
+    if err != nil {
+        return nil, fmt.Errorf("error creating request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Authorization", "Bearer "+e.APIKey)
+
+    logMessage("Embedding %d texts with %s", len(texts), model)
+    resp, err := httpClient.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("error sending embedding request: %w", err)
+    }
+    defer resp.Body.Close()
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("error reading embedding response: %w", err)
+    }
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("voyage API error: status %d: %s", resp.StatusCode, body)
+    }
+
+    var result voyageResponse
+    if err := json.Unmarshal(body, &result); err != nil {
+        return nil, fmt.Errorf("error parsing embedding response: %w", err)
+    }
+    vectors := make([][]float32, len(texts))
+    for _, d := range result.Data {
+        if d.Index >= 0 && d.Index < len(vectors) {
+            vectors[d.Index] = d.Embedding
+        }
+    }
+    for i, v := range vectors {
+        if v == nil {
+            return nil, fmt.Errorf("embedding response is missing text %d", i)
+        }
+    }
+    return vectors, nil
+}
+
+const defaultHashingDimensions = 1024
+
+// HashingEmbedder embeds texts locally by hashing their words and adjacent
+// word pairs into a fixed-size vector. It needs no model or network, and
+// relates texts only by the words they share, so it suits tests, offline
+// use, and small stores where keyword matching is enough.
+type HashingEmbedder struct {
+    Dimensions int // Defaults to 1024
+}
+
+// NewHashingEmbedder creates a HashingEmbedder; dimensions of 0 or less use
+// the default
+func NewHashingEmbedder(dimensions int) *HashingEmbedder {
+    if dimensions <= 0 {
+        dimensions = defaultHashingDimensions
+    }
+    return &HashingEmbedder{Dimensions: dimensions}
+}
+
+// Embed returns a unit vector per text; empty texts get a zero vector
+func (e *HashingEmbedder) Embed(ctx context.Context, texts []string, input EmbeddingInput) ([][]float32, error) {
+    dimensions := e.Dimensions
+    if dimensions <= 0 {
+        dimensions = defaultHashingDimensions
+    }
+    vectors := make([][]float32, len(texts))
+    for i, text := range texts {
+        vector := make([]float32, dimensions)
+        words := embeddingWords(text)
+        for j, word := range words {
+            addHashed(vector, word, 1)
+            if j > 0 {
+                addHashed(vector, words[j-1]+" "+word, 0.5)
+            }
+        }
+        normalize(vector)
+        vectors[i] = vector
+    }
+    return vectors, nil
+}
+
+// addHashed adds weight to the bucket chosen by the term's hash, with a sign
+// from another hash bit so collisions tend to cancel out
+func addHashed(vector []float32, term string, weight float32) {
+    h := fnv.New64a()
+    h.Write([]byte(term))
+    sum := h.Sum64()
+    if sum>>63 == 1 {
+        weight = -weight
+    }
+    vector[sum%uint64(len(vector))] += weight
+}
+
+// embeddingWords splits text into lower-cased words
+func embeddingWords(text string) []string {
+    return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+    })
+}
+
+func normalize(vector []float32) {
+    var norm float64
+    for _, x := range vector {
+        norm += float64(x) * float64(x)
+    }
+    if norm == 0 {
+        return
+    }
+    scale := float32(1 / math.Sqrt(norm))
+    for i := range vector {
+        vector[i] *= scale
+    }
+}
+
+// CosineSimilarity compares two embeddings; vectors of different lengths or
+// zero vectors score 0
+func CosineSimilarity(a, b []float32) float64 {
+    if len(a) != len(b) {
+        return 0
+    }
+    var dot, normA, normB float64
+    for i := range a {
+        dot += float64(a[i]) * float64(b[i])
+        normA += float64(a[i]) * float64(a[i])
+        normB += float64(b[i]) * float64(b[i])
+    }
+    if normA == 0 || normB == 0 {
+        return 0
+    }
+    return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,55 @@
+package anthropic
+
+// Role identifies the author of a message
+type Role string
+
+// ContentType identifies the kind of a content block
+type ContentType string
+
+// StopReason reports why Claude stopped generating
+type StopReason string
+
+var knownContentTypes = []ContentType{
+    ContentTypeText, ContentTypeToolUse, ContentTypeToolResult, ContentTypeThinking,
+    ContentTypeImage, ContentTypeDocument, ContentTypeMCPToolUse, ContentTypeMCPToolResult,
+    ContentTypeServerToolUse, ContentTypeWebSearchToolResult, ContentTypeWebSearchResult,
+    ContentTypeWebSearchToolResultError, ContentTypeCodeExecutionToolResult,
+    ContentTypeCodeExecutionResult, ContentTypeCodeExecutionOutput,
+    ContentTypeCodeExecutionToolResultError,
+}
+
+var knownStopReasons = []StopReason{
+    StopReasonToolUse, StopReasonEndTurn, StopReasonMaxTokens,
+    StopReasonStopSequence, StopReasonPauseTurn,
+}
+
+func (r Role) String() string        { return string(r) }
+func (t ContentType) String() string { return string(t) }
+func (s StopReason) String() string  { return string(s) }
+
+// Valid reports whether r is a role a message can have. RoleSystem is not:
+// system prompts are sent separately.
+func (r Role) Valid() bool {
+    return r == RoleUser || r == RoleAssistant
+}
+
+// Valid reports whether t is a content type known to this package. Responses
+// may carry newer types, which are still decoded and passed through.
+func (t ContentType) Valid() bool {
+    for _, known := range knownContentTypes {
+        if t == known {
+            return true
+        }
+    }
+    return false
+}
+
+// Valid reports whether s is a stop reason known to this package
+func (s StopReason) Valid() bool {
+    for _, known := range knownStopReasons {
+        if s == known {
+            return true
+        }
+    }
+    return false
+}
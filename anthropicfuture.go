@@ -0,0 +1,57 @@
+package anthropic
+
+import "context"
+
+// Future is the pending result of an asynchronous chat
+type Future struct {
+    done   chan struct{}
+    cancel context.CancelFunc
+    resp   *AnthropicResponse
+    err    error
+}
+
+// Done returns a channel that is closed once the result is available
+func (f *Future) Done() <-chan struct{} {
+    return f.done
+}
+
+// Result waits for the chat to finish and returns its outcome
+func (f *Future) Result() (*AnthropicResponse, error) {
+    <-f.done
+    return f.resp, f.err
+}
+
+// Cancel abandons the chat; Result then returns the context error unless
+// the chat had already finished
+func (f *Future) Cancel() {
+    f.cancel()
+}
+
+// ChatMeAsync starts ChatMe on the default session and returns without
+// waiting. Chats on one session run one at a time, so start independent
+// chats from separate sessions (see NewSession) to run them in parallel.
+func (c *AnthropicClient) ChatMeAsync(ctx context.Context, message string, params *MessageParams, opts ...RequestOption) *Future {
+    return c.session.ChatMeAsync(ctx, message, params, opts...)
+}
+
+// ChatMeAsync starts ChatMe in the background and returns a Future for its result
+func (s *Session) ChatMeAsync(ctx context.Context, message string, params *MessageParams, opts ...RequestOption) *Future {
+    ctx, cancel := context.WithCancel(ctx)
+    f := &Future{done: make(chan struct{}), cancel: cancel}
+    go func() {
+        defer close(f.done)
+        defer cancel()
+        f.resp, f.err = s.ChatMe(ctx, message, params, opts...)
+    }()
+    return f
+}
+
+// WaitAll waits for every future and returns their results in order
+func WaitAll(futures ...*Future) ([]*AnthropicResponse, []error) {
+    responses := make([]*AnthropicResponse, len(futures))
+    errs := make([]error, len(futures))
+    for i, f := range futures {
+        responses[i], errs[i] = f.Result()
+    }
+    return responses, errs
+}
@@ -0,0 +1,128 @@
+package anthropic
+
+import (
+    "context"
+    "errors"
+    "fmt"
+)
+
+// ErrGuardrailBlocked is matched by every *GuardrailError
+var ErrGuardrailBlocked = errors.New("blocked by guardrail")
+
+// Guardrail inspects text before it is sent or after it is received. It
+// returns allow=false to block the call, or allow=true with a non-empty
+// replacement to rewrite the text (masking a term, say); allow=true with an
+// empty replacement passes the text through. For a blocked call, replacement
+// is the reason reported in the GuardrailError.
+type Guardrail func(ctx context.Context, content string) (allow bool, replacement string, err error)
+
+// GuardrailError reports text a guardrail refused
+type GuardrailError struct {
+    Stage  string // "input" or "output"
+    Reason string
+}
+
+func (e *GuardrailError) Error() string {
+    if e.Reason == "" {
+        return fmt.Sprintf("%s blocked by guardrail", e.Stage)
+    }
+    return fmt.Sprintf("%s blocked by guardrail: %s", e.Stage, e.Reason)
+}
+
+func (e *GuardrailError) Is(target error) bool {
+    return target == ErrGuardrailBlocked
+}
+
+// WithInputGuardrail checks prompts before they are sent, in ChatMe,
+// EditAndRetry, both tool loops, ChatJSON, ExtractInto and Messages. A
+// blocked prompt is not added to the session history. It can be supplied
+// multiple times; guardrails run in registration order, each seeing the
+// previous one's replacement.
+func WithInputGuardrail(g Guardrail) ClientOption {
+    return func(c *AnthropicClient) {
+        if g != nil {
+            c.inputGuardrails = append(c.inputGuardrails, g)
+        }
+    }
+}
+
+// WithOutputGuardrail checks the text of every API response before it is
+// returned or recorded, so filtered output never reaches the history or end
+// users. When a response is blocked, the session history is rolled back to
+// before the call. Streamed events are held until the check passes, and each
+// text block is then delivered as one checked delta. It can be supplied
+// multiple times.
+func WithOutputGuardrail(g Guardrail) ClientOption {
+    return func(c *AnthropicClient) {
+        if g != nil {
+            c.outputGuardrails = append(c.outputGuardrails, g)
+        }
+    }
+}
+
+// runGuardrails passes content through each guardrail in turn
+func runGuardrails(ctx context.Context, stage string, guardrails []Guardrail, content string) (string, error) {
+    for _, g := range guardrails {
+        allow, replacement, err := g(ctx, content)
+        if err != nil {
+            return "", fmt.Errorf("%s guardrail failed: %w", stage, err)
+        }
+        if !allow {
+            logMessage("Guardrail blocked %s: %s", stage, replacement)
+            return "", &GuardrailError{Stage: stage, Reason: replacement}
+        }
+        if replacement != "" {
+            content = replacement
+        }
+    }
+    return content, nil
+}
+
+// checkInput applies the input guardrails to a prompt
+func (c *AnthropicClient) checkInput(ctx context.Context, prompt string) (string, error) {
+    if len(c.inputGuardrails) == 0 {
+        return prompt, nil
+    }
+    return runGuardrails(ctx, "input", c.inputGuardrails, prompt)
+}
+
+// checkInputMessage applies the input guardrails to the text of the last
+// message when the user sent it, returning a copy if any text changed
+func (c *AnthropicClient) checkInputMessage(ctx context.Context, messages []Message) ([]Message, error) {
+    if len(c.inputGuardrails) == 0 || len(messages) == 0 || messages[len(messages)-1].Role != RoleUser {
+        return messages, nil
+    }
+    last := messages[len(messages)-1]
+    content := make([]MessageContent, len(last.Content))
+    copy(content, last.Content)
+    for i := range content {
+        if content[i].Type != ContentTypeText {
+            continue
+        }
+        text, err := c.checkInput(ctx, content[i].Text)
+        if err != nil {
+            return nil, err
+        }
+        content[i].Text = text
+    }
+    checked := append(messages[:len(messages)-1:len(messages)-1], Message{Role: last.Role, Content: content})
+    return checked, nil
+}
+
+// checkOutput applies the output guardrails to each text block of resp
+func (c *AnthropicClient) checkOutput(ctx context.Context, resp *AnthropicResponse) error {
+    if len(c.outputGuardrails) == 0 {
+        return nil
+    }
+    for i := range resp.Content {
+        if resp.Content[i].Type != ContentTypeText {
+            continue
+        }
+        text, err := runGuardrails(ctx, "output", c.outputGuardrails, resp.Content[i].Text)
+        if err != nil {
+            return err
+        }
+        resp.Content[i].Text = text
+    }
+    return nil
+}
@@ -0,0 +1,61 @@
+package anthropic
+
+// Hooks lets observers (UI layers, persistence, analytics) react to conversation
+// changes without wrapping every client call. Any field may be left nil.
+// Hooks run synchronously on the calling goroutine, so they should return quickly.
+type Hooks struct {
+    // OnMessageAppended fires whenever a message is added to the conversation history
+    OnMessageAppended func(msg Message)
+
+    // OnToolCall fires before a tool handler is executed
+    OnToolCall func(call ToolUse)
+
+    // OnToolResult fires after a tool handler returns, with the tool_result block
+    // that will be sent back to Claude
+    OnToolResult func(call ToolUse, result MessageContent)
+
+    // OnResponse fires after every successful API response
+    OnResponse func(resp *AnthropicResponse)
+}
+
+// WithHooks registers a set of conversation event hooks. It can be supplied
+// multiple times; hooks are invoked in the order they were registered.
+func WithHooks(hooks Hooks) ClientOption {
+    return func(c *AnthropicClient) {
+        c.hooks = append(c.hooks, hooks)
+    }
+}
+
+// Internal dispatch helpers used throughout the client
+
+func (c *AnthropicClient) fireMessageAppended(msg Message) {
+    for _, h := range c.hooks {
+        if h.OnMessageAppended != nil {
+            h.OnMessageAppended(msg)
+        }
+    }
+}
+
+func (c *AnthropicClient) fireToolCall(call ToolUse) {
+    for _, h := range c.hooks {
+        if h.OnToolCall != nil {
+            h.OnToolCall(call)
+        }
+    }
+}
+
+func (c *AnthropicClient) fireToolResult(call ToolUse, result MessageContent) {
+    for _, h := range c.hooks {
+        if h.OnToolResult != nil {
+            h.OnToolResult(call, result)
+        }
+    }
+}
+
+func (c *AnthropicClient) fireResponse(resp *AnthropicResponse) {
+    for _, h := range c.hooks {
+        if h.OnResponse != nil {
+            h.OnResponse(resp)
+        }
+    }
+}
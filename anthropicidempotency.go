@@ -0,0 +1,135 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+)
+
+const defaultIdempotencyWindow = 10 * time.Minute
+
+// WithIdempotencyKey marks one call with a caller-chosen key identifying the
+// logical request, such as the ID of the upstream job or HTTP request that
+// triggered it. A second call with the same key, while the first is running
+// or within the idempotency window after it succeeded, returns the first
+// call's response instead of sending another request. Failed calls are not
+// remembered, so they can be retried. Keys apply to ChatMe, Messages and
+// ChatWithTools.
+func WithIdempotencyKey(key string) RequestOption {
+    return func(p *MessageParams) {
+        p.IdempotencyKey = key
+    }
+}
+
+// WithIdempotencyWindow sets how long successful responses are kept for
+// calls with an idempotency key; the default is 10 minutes
+func WithIdempotencyWindow(d time.Duration) ClientOption {
+    return func(c *AnthropicClient) {
+        if d > 0 {
+            c.idempotencyWindow = d
+        }
+    }
+}
+
+// idempotent runs call unless a call with the same key is running or
+// recently succeeded, in which case it returns that call's outcome. Calls
+// without a key always run.
+func (c *AnthropicClient) idempotent(ctx context.Context, key string, call func() (*AnthropicResponse, error)) (*AnthropicResponse, error) {
+    if key == "" {
+        return call()
+    }
+    window := c.idempotencyWindow
+    if window == 0 {
+        window = defaultIdempotencyWindow
+    }
+
+    prior, leader := c.idempotency.start(key)
+    if !leader {
+        logMessage("Duplicate request with idempotency key %s", key)
+        select {
+        case <-prior.done:
+        case <-ctx.Done():
+            return nil, ctx.Err()
+        }
+        if prior.err != nil {
+            return nil, prior.err
+        }
+        return copyResponse(prior.resp), nil
+    }
+
+    return c.runIdempotent(key, prior, window, call)
+}
+
+// runIdempotent runs the leading call for key. A panic is recorded as the
+// call's error, releasing the callers waiting on it, and then re-raised.
+func (c *AnthropicClient) runIdempotent(key string, prior *idempotentCall, window time.Duration, call func() (*AnthropicResponse, error)) (resp *AnthropicResponse, err error) {
+    finished := false
+    defer func() {
+        if finished {
+            return
+        }
+        r := recover()
+        if r == nil {
+            // The call ended with runtime.Goexit, which must not become a panic
+            c.idempotency.finish(key, prior, nil, fmt.Errorf("call with idempotency key %s did not return", key), window)
+            return
+        }
+        c.idempotency.finish(key, prior, nil, fmt.Errorf("call with idempotency key %s panicked: %v", key, r), window)
+        panic(r)
+    }()
+    resp, err = call()
+    finished = true
+    c.idempotency.finish(key, prior, resp, err, window)
+    return resp, err
+}
+
+// idempotencyTracker remembers the calls made with each idempotency key
+type idempotencyTracker struct {
+    mu    sync.Mutex
+    calls map[string]*idempotentCall
+}
+
+type idempotentCall struct {
+    done    chan struct{} // Closed when the call finishes
+    resp    *AnthropicResponse
+    err     error
+    expires time.Time // Zero while the call runs
+}
+
+// start returns the running or remembered call for key, or registers a new
+// one and reports that the caller should run it
+func (t *idempotencyTracker) start(key string) (*idempotentCall, bool) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    now := time.Now()
+    for k, call := range t.calls {
+        if !call.expires.IsZero() && now.After(call.expires) {
+            delete(t.calls, k)
+        }
+    }
+    if call, ok := t.calls[key]; ok {
+        return call, false
+    }
+    if t.calls == nil {
+        t.calls = make(map[string]*idempotentCall)
+    }
+    call := &idempotentCall{done: make(chan struct{})}
+    t.calls[key] = call
+    return call, true
+}
+
+func (t *idempotencyTracker) finish(key string, call *idempotentCall, resp *AnthropicResponse, err error, window time.Duration) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if err != nil {
+        delete(t.calls, key)
+    } else {
+        call.resp = copyResponse(resp)
+        call.expires = time.Now().Add(window)
+    }
+    call.err = err
+    close(call.done)
+}
@@ -0,0 +1,55 @@
+package anthropic
+
+// MCPServer describes a remote MCP server Claude may call server-side through
+// the MCP connector. Tools from these servers run on Anthropic's side; their
+// calls and results come back as mcp_tool_use and mcp_tool_result blocks.
+type MCPServer struct {
+    Type               string                `json:"type"` // Always "url"; set by the client when empty
+    URL                string                `json:"url"`
+    Name               string                `json:"name"`
+    AuthorizationToken string                `json:"authorization_token,omitempty"`
+    ToolConfiguration  *MCPToolConfiguration `json:"tool_configuration,omitempty"`
+}
+
+// MCPToolConfiguration restricts which tools of a remote MCP server are usable
+type MCPToolConfiguration struct {
+    Enabled      *bool    `json:"enabled,omitempty"`
+    AllowedTools []string `json:"allowed_tools,omitempty"`
+}
+
+// normalizeMCPServers fills in the default server type
+func normalizeMCPServers(servers []MCPServer) []MCPServer {
+    if len(servers) == 0 {
+        return nil
+    }
+    normalized := make([]MCPServer, len(servers))
+    for i, server := range servers {
+        if server.Type == "" {
+            server.Type = "url"
+        }
+        normalized[i] = server
+    }
+    return normalized
+}
+
+// MCPToolCalls returns the remote MCP tool calls Claude made in this response
+func (r *AnthropicResponse) MCPToolCalls() []MessageContent {
+    var calls []MessageContent
+    for _, block := range r.Content {
+        if block.Type == ContentTypeMCPToolUse {
+            calls = append(calls, block)
+        }
+    }
+    return calls
+}
+
+// MCPToolResults returns the results of remote MCP tool calls in this response
+func (r *AnthropicResponse) MCPToolResults() []MessageContent {
+    var results []MessageContent
+    for _, block := range r.Content {
+        if block.Type == ContentTypeMCPToolResult {
+            results = append(results, block)
+        }
+    }
+    return results
+}
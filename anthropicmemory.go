@@ -0,0 +1,189 @@
+package anthropic
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Memory is one remembered fact
+type Memory struct {
+    ID       string            `json:"id"`
+    Text     string            `json:"text"`
+    Metadata map[string]string `json:"metadata,omitempty"`
+    Created  time.Time         `json:"created"`
+    Score    float64           `json:"score,omitempty"` // Similarity to the query, set by Query
+}
+
+// MemoryStore keeps facts an agent has learned, for recall in later tasks.
+// Implementations backed by a vector database rank by embedding similarity;
+// Query returns at most limit memories, most similar first.
+type MemoryStore interface {
+    Add(ctx context.Context, memory Memory) (string, error)
+    Query(ctx context.Context, query string, limit int) ([]Memory, error)
+    Forget(ctx context.Context, id string) error
+}
+
+// InMemoryStore is a MemoryStore held in process memory. It ranks memories by
+// the cosine similarity of their embeddings.
+type InMemoryStore struct {
+    embedder Embedder
+    mu       sync.RWMutex
+    memories []storedMemory
+}
+
+type storedMemory struct {
+    Memory
+    vector []float32
+}
+
+// NewInMemoryStore creates an empty in-memory store embedding with embedder.
+// A nil embedder uses a HashingEmbedder, which matches memories by the words
+// they share with the query.
+func NewInMemoryStore(embedder Embedder) *InMemoryStore {
+    if embedder == nil {
+        embedder = NewHashingEmbedder(0)
+    }
+    return &InMemoryStore{embedder: embedder}
+}
+
+// Add stores a memory, assigning an ID and creation time when they are unset
+func (s *InMemoryStore) Add(ctx context.Context, memory Memory) (string, error) {
+    if strings.TrimSpace(memory.Text) == "" {
+        return "", fmt.Errorf("memory text must not be empty")
+    }
+    if memory.ID == "" {
+        memory.ID = newMemoryID()
+    }
+    if memory.Created.IsZero() {
+        memory.Created = time.Now()
+    }
+    memory.Score = 0
+    vector, err := embedOne(ctx, s.embedder, memory.Text, EmbedDocument)
+    if err != nil {
+        return "", fmt.Errorf("error embedding memory: %w", err)
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.memories = append(s.memories, storedMemory{Memory: memory, vector: vector})
+    return memory.ID, nil
+}
+
+// Query returns the memories most similar to query. Memories unrelated to
+// it, with a similarity of 0 or less, are left out.
+func (s *InMemoryStore) Query(ctx context.Context, query string, limit int) ([]Memory, error) {
+    if limit <= 0 {
+        return nil, nil
+    }
+    q, err := embedOne(ctx, s.embedder, query, EmbedQuery)
+    if err != nil {
+        return nil, fmt.Errorf("error embedding query: %w", err)
+    }
+
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    var matches []Memory
+    for _, stored := range s.memories {
+        if score := CosineSimilarity(q, stored.vector); score > 0 {
+            memory := stored.Memory
+            memory.Score = score
+            matches = append(matches, memory)
+        }
+    }
+    sort.SliceStable(matches, func(i, j int) bool {
+        return matches[i].Score > matches[j].Score
+    })
+    if len(matches) > limit {
+        matches = matches[:limit]
+    }
+    return matches, nil
+}
+
+// Forget removes a memory; unknown IDs are ignored
+func (s *InMemoryStore) Forget(ctx context.Context, id string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for i, stored := range s.memories {
+        if stored.ID == id {
+            s.memories = append(s.memories[:i], s.memories[i+1:]...)
+            break
+        }
+    }
+    return nil
+}
+
+func newMemoryID() string {
+    b := make([]byte, 8)
+    rand.Read(b)
+    return "mem_" + hex.EncodeToString(b)
+}
+
+// defaultMemoryRecall is how many memories an agent recalls per task
+const defaultMemoryRecall = 5
+
+// rememberTool lets an agent store facts worth keeping for later tasks
+type rememberTool struct {
+    store MemoryStore
+    agent string
+}
+
+func (t *rememberTool) Definition() Tool {
+    return Tool{
+        Name: "remember",
+        Description: "Save a fact worth knowing in future tasks, such as a user preference, a decision, " +
+            "or a finding that took effort to establish. State it so it makes sense on its own.",
+        InputSchema: InputSchema{
+            Type: "object",
+            Properties: map[string]Property{
+                "fact": {Type: "string", Description: "The fact to remember, as one self-contained sentence"},
+            },
+            Required: []string{"fact"},
+        },
+    }
+}
+
+func (t *rememberTool) Execute(ctx context.Context, input json.RawMessage) (ToolResult, error) {
+    var in struct {
+        Fact string `json:"fact"`
+    }
+    if err := json.Unmarshal(input, &in); err != nil {
+        return ToolResult{}, fmt.Errorf("invalid remember input: %w", err)
+    }
+    if _, err := t.store.Add(ctx, Memory{Text: in.Fact, Metadata: map[string]string{"agent": t.agent}}); err != nil {
+        return ToolResult{}, err
+    }
+    return ToolResult{Content: "Remembered."}, nil
+}
+
+// recallPrompt looks up the memories relevant to task and formats them for
+// the system prompt
+func (a *Agent) recallPrompt(ctx context.Context, task string) string {
+    limit := a.config.MemoryRecall
+    if limit == 0 {
+        limit = defaultMemoryRecall
+    }
+    memories, err := a.config.Memory.Query(ctx, task, limit)
+    if err != nil {
+        logMessage("Agent %s could not recall memories: %v", a.config.Name, err)
+        return ""
+    }
+    if len(memories) == 0 {
+        return ""
+    }
+    var b strings.Builder
+    b.WriteString("You remember these facts from earlier tasks:")
+    for _, memory := range memories {
+        b.WriteString("\n- ")
+        b.WriteString(memory.Text)
+    }
+    return b.String()
+}
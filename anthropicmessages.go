@@ -0,0 +1,89 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+)
+
+// Messages sends caller-managed conversation history in a single request and
+// returns Claude's reply. It is stateless: no session history is read or
+// recorded, so servers that store conversations themselves can append the
+// reply to their own copy. Usage still counts toward the client's totals and
+// budget. The system prompt follows params.System, then the client prompt.
+func (c *AnthropicClient) Messages(ctx context.Context, messages []Message, params *MessageParams) (*AnthropicResponse, error) {
+    messages, err := c.checkMessages(ctx, messages)
+    if err != nil {
+        return nil, err
+    }
+    params = c.resolveParams(params, nil)
+    return c.idempotent(ctx, params.IdempotencyKey, func() (*AnthropicResponse, error) {
+        return c.sendMessages(ctx, messages, params)
+    })
+}
+
+// MessagesStream is Messages with the reply delivered to handler event by
+// event as it is generated, as for ChatStream
+func (c *AnthropicClient) MessagesStream(ctx context.Context, messages []Message, params *MessageParams, handler StreamHandler) (*AnthropicResponse, error) {
+    messages, err := c.checkMessages(ctx, messages)
+    if err != nil {
+        return nil, err
+    }
+    params = c.resolveParams(params, nil)
+    ctx, cancel := callContext(ctx, params)
+    defer cancel()
+
+    resp, err := c.streamRequest(ctx, nil, c.messagesRequest(messages, params), handler)
+    if err != nil {
+        logMessage("Streaming messages request failed: %v", err)
+        return nil, err
+    }
+    return resp, nil
+}
+
+// checkMessages validates caller-supplied history and runs the input guardrails
+func (c *AnthropicClient) checkMessages(ctx context.Context, messages []Message) ([]Message, error) {
+    if len(messages) == 0 {
+        return nil, fmt.Errorf("messages must contain at least one message")
+    }
+    for i, msg := range messages {
+        if !msg.Role.Valid() {
+            return nil, fmt.Errorf("message %d has invalid role %q", i, msg.Role)
+        }
+    }
+    return c.checkInputMessage(ctx, messages)
+}
+
+func (c *AnthropicClient) sendMessages(ctx context.Context, messages []Message, params *MessageParams) (*AnthropicResponse, error) {
+    ctx, cancel := callContext(ctx, params)
+    defer cancel()
+
+    resp, err := c.sendRequest(ctx, nil, c.messagesRequest(messages, params))
+    if err != nil {
+        logMessage("Messages request failed: %v", err)
+        return nil, err
+    }
+    return resp, nil
+}
+
+// messagesRequest builds the request for caller-managed history
+func (c *AnthropicClient) messagesRequest(messages []Message, params *MessageParams) Request {
+    systemPrompt := params.System
+    if systemPrompt == "" {
+        systemPrompt = c.renderSystemPrompt(nil, params)
+    }
+
+    return Request{
+        Model:         params.Model,
+        System:        systemPrompt,
+        SystemBlocks:  params.SystemBlocks,
+        Messages:      messages,
+        MaxTokens:     params.MaxTokens,
+        Temperature:   params.Temperature,
+        TopP:          params.TopP,
+        TopK:          params.TopK,
+        StopSequences: params.StopSequences,
+        Tools:         params.Tools,
+        ToolChoice:    params.ToolChoice,
+        MCPServers:    normalizeMCPServers(params.MCPServers),
+    }
+}
@@ -0,0 +1,104 @@
+package anthropic
+
+import "strings"
+
+// ModelCapabilities describes the limits and features of a model family
+type ModelCapabilities struct {
+    ContextWindow   int  `json:"context_window"`    // Input plus output tokens
+    MaxOutputTokens int  `json:"max_output_tokens"` // Largest max_tokens the model accepts
+    Vision          bool `json:"vision"`            // Accepts image blocks
+    Thinking        bool `json:"thinking"`          // Supports extended thinking
+}
+
+const (
+    // defaultContextWindow is assumed for models missing from the table
+    defaultContextWindow = 200000
+
+    // defaultMaxTokens is sent when a request leaves MaxTokens unset, lowered
+    // to the model's output limit where that is smaller
+    defaultMaxTokens = 4096
+)
+
+// defaultModelCapabilities is the built-in capability table, keyed like the
+// pricing table by model name or family prefix. Override or extend it with
+// WithModelCapabilities.
+var defaultModelCapabilities = map[string]ModelCapabilities{
+    "claude-opus-4":     {ContextWindow: 200000, MaxOutputTokens: 32000, Vision: true, Thinking: true},
+    "claude-sonnet-4":   {ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Thinking: true},
+    "claude-3-7-sonnet": {ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Thinking: true},
+    "claude-3-5-sonnet": {ContextWindow: 200000, MaxOutputTokens: 8192, Vision: true},
+    "claude-3-5-haiku":  {ContextWindow: 200000, MaxOutputTokens: 8192, Vision: true},
+    "claude-3-opus":     {ContextWindow: 200000, MaxOutputTokens: 4096, Vision: true},
+    "claude-3-sonnet":   {ContextWindow: 200000, MaxOutputTokens: 4096, Vision: true},
+    "claude-3-haiku":    {ContextWindow: 200000, MaxOutputTokens: 4096, Vision: true},
+    "claude-2.1":        {ContextWindow: 200000, MaxOutputTokens: 4096},
+    "claude-2":          {ContextWindow: 100000, MaxOutputTokens: 4096},
+    "claude-instant":    {ContextWindow: 100000, MaxOutputTokens: 4096},
+}
+
+// DefaultModelCapabilities returns a copy of the built-in capability table
+func DefaultModelCapabilities() map[string]ModelCapabilities {
+    models := make(map[string]ModelCapabilities, len(defaultModelCapabilities))
+    for model, caps := range defaultModelCapabilities {
+        models[model] = caps
+    }
+    return models
+}
+
+// WithModelCapabilities adds or replaces entries in the client's capability
+// table, e.g. for new models or an extended output beta
+func WithModelCapabilities(models map[string]ModelCapabilities) ClientOption {
+    return func(c *AnthropicClient) {
+        if c.models == nil {
+            c.models = DefaultModelCapabilities()
+        }
+        for model, caps := range models {
+            c.models[model] = caps
+        }
+    }
+}
+
+// ModelCapabilities looks up a model in the client's capability table by
+// exact name or longest family prefix, reporting false for unknown models
+func (c *AnthropicClient) ModelCapabilities(model string) (ModelCapabilities, bool) {
+    models := c.models
+    if models == nil {
+        models = defaultModelCapabilities
+    }
+    return lookupModel(models, model)
+}
+
+func lookupModel(models map[string]ModelCapabilities, model string) (ModelCapabilities, bool) {
+    if caps, ok := models[model]; ok {
+        return caps, true
+    }
+    var best string
+    for prefix := range models {
+        if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+            best = prefix
+        }
+    }
+    if best == "" {
+        return ModelCapabilities{}, false
+    }
+    return models[best], true
+}
+
+// resolveMaxTokens fills in max_tokens when it is unset and lowers it to the
+// model's output limit when it is too high
+func (c *AnthropicClient) resolveMaxTokens(model string, maxTokens int) int {
+    caps, known := c.ModelCapabilities(model)
+    known = known && caps.MaxOutputTokens > 0
+    switch {
+    case maxTokens <= 0:
+        maxTokens = defaultMaxTokens
+        if known && caps.MaxOutputTokens < maxTokens {
+            maxTokens = caps.MaxOutputTokens
+        }
+        logMessage("MaxTokens unset; using %d for %s", maxTokens, model)
+    case known && maxTokens > caps.MaxOutputTokens:
+        logMessage("MaxTokens %d exceeds the %s output limit; using %d", maxTokens, model, caps.MaxOutputTokens)
+        maxTokens = caps.MaxOutputTokens
+    }
+    return maxTokens
+}
@@ -0,0 +1,214 @@
+package anthropic
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "regexp"
+    "strings"
+    "sync"
+    "time"
+)
+
+var toolNameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// Orchestrator lets a coordinator agent delegate sub-tasks to specialist
+// agents, each offered to it as a delegate_to_<name> tool, for pipelines
+// such as researcher, writer and critic. The whole run shares one budget.
+type Orchestrator struct {
+    coordinator *Agent
+    specialists []*Agent
+    budget      Budget
+    mu          sync.Mutex
+}
+
+// OrchestrationResult is the outcome of an orchestrated task
+type OrchestrationResult struct {
+    Output         string         // The coordinator's final answer
+    Steps          []*AgentResult // Every delegation in the order it finished, then the coordinator
+    Usage          ModelUsage     // Tokens and estimated cost across all agents
+    BudgetExceeded bool           // The run was cut short by the budget
+    Duration       time.Duration
+}
+
+// NewOrchestrator combines a coordinator with the specialists it may
+// delegate to. A zero budget leaves only the client's budget. Specialist
+// names must be unique.
+func NewOrchestrator(coordinator *Agent, budget Budget, specialists ...*Agent) (*Orchestrator, error) {
+    if coordinator == nil {
+        return nil, fmt.Errorf("orchestrator needs a coordinator agent")
+    }
+    seen := make(map[string]bool)
+    for _, agent := range specialists {
+        if agent == nil || agent == coordinator {
+            return nil, fmt.Errorf("specialists must be agents other than the coordinator")
+        }
+        name := delegateToolName(agent)
+        if seen[name] {
+            return nil, fmt.Errorf("duplicate specialist agent %s", agent.Name())
+        }
+        seen[name] = true
+    }
+    return &Orchestrator{coordinator: coordinator, specialists: specialists, budget: budget}, nil
+}
+
+// Run gives task to the coordinator and returns its answer with the combined
+// transcript. Once the budget is spent, further delegations are refused and
+// the coordinator is stopped after its current turn.
+func (o *Orchestrator) Run(ctx context.Context, task string, opts ...RequestOption) (*OrchestrationResult, error) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+
+    logMessage("Orchestrating task across %d specialists: %s", len(o.specialists), task)
+    run := &orchestration{budget: o.budget, start: time.Now()}
+    registry, err := o.registry(run)
+    if err != nil {
+        return nil, err
+    }
+    run.track(o.coordinator)
+
+    result, err := o.coordinator.run(ctx, task, registry, func(*AnthropicResponse) bool {
+        return run.exhausted()
+    }, opts)
+    if err != nil {
+        return nil, err
+    }
+    run.add(result)
+
+    run.mu.Lock()
+    defer run.mu.Unlock()
+    return &OrchestrationResult{
+        Output:         result.Output,
+        Steps:          run.steps,
+        Usage:          run.spentLocked(),
+        BudgetExceeded: run.over,
+        Duration:       time.Since(run.start),
+    }, nil
+}
+
+// registry builds the coordinator's tools for one run: its own tools plus a
+// delegation tool per specialist
+func (o *Orchestrator) registry(run *orchestration) (*ToolRegistry, error) {
+    delegates := make([]ToolHandler, 0, len(o.specialists))
+    for _, agent := range o.specialists {
+        run.track(agent)
+        delegates = append(delegates, &delegateTool{agent: agent, run: run})
+    }
+    // Like Run, a coordinator without tools of its own uses the client's
+    own := o.coordinator.config.Tools
+    if own == nil {
+        own = o.coordinator.client.registry
+    }
+    return own.extend(delegates...)
+}
+
+// orchestration tracks the agents and spending of one Run
+type orchestration struct {
+    mu     sync.Mutex
+    budget Budget
+    start  time.Time
+    before map[*Agent]ModelUsage // Usage of each agent's session when the run started
+    steps  []*AgentResult
+    over   bool
+}
+
+func (r *orchestration) track(agent *Agent) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.before == nil {
+        r.before = make(map[*Agent]ModelUsage)
+    }
+    if _, ok := r.before[agent]; !ok {
+        r.before[agent] = agent.session.UsageReport().Total
+    }
+}
+
+func (r *orchestration) add(result *AgentResult) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.steps = append(r.steps, result)
+}
+
+// exhausted reports whether the run has spent its budget
+func (r *orchestration) exhausted() bool {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if budgetError("orchestration", r.budget, UsageReport{Total: r.spentLocked()}) != nil {
+        r.over = true
+    }
+    return r.over
+}
+
+// spentLocked sums the usage of every agent since the run started. Callers
+// must hold r.mu.
+func (r *orchestration) spentLocked() ModelUsage {
+    total := ModelUsage{Priced: true}
+    for agent, before := range r.before {
+        u := usageSince(before, agent.session.UsageReport().Total)
+        total.Requests += u.Requests
+        total.InputTokens += u.InputTokens
+        total.OutputTokens += u.OutputTokens
+        total.CacheCreationInputTokens += u.CacheCreationInputTokens
+        total.CacheReadInputTokens += u.CacheReadInputTokens
+        total.CostUSD += u.CostUSD
+        total.Priced = total.Priced && u.Priced
+    }
+    return total
+}
+
+// delegateTool offers a specialist agent to the coordinator as a tool
+type delegateTool struct {
+    agent *Agent
+    run   *orchestration
+}
+
+type delegateInput struct {
+    Task string `json:"task"`
+}
+
+func delegateToolName(agent *Agent) string {
+    name := "delegate_to_" + strings.Trim(toolNameUnsafe.ReplaceAllString(agent.Name(), "_"), "_")
+    if len(name) > 64 {
+        name = name[:64]
+    }
+    return name
+}
+
+func (t *delegateTool) Definition() Tool {
+    description := t.agent.Description()
+    if description == "" {
+        description = fmt.Sprintf("The %s agent.", t.agent.Name())
+    }
+    return Tool{
+        Name:        delegateToolName(t.agent),
+        Description: description + " Give it a complete, self-contained task; it returns its answer.",
+        InputSchema: InputSchema{
+            Type: "object",
+            Properties: map[string]Property{
+                "task": {Type: "string", Description: "The sub-task for the agent, with all the context it needs"},
+            },
+            Required: []string{"task"},
+        },
+    }
+}
+
+func (t *delegateTool) Execute(ctx context.Context, input json.RawMessage) (ToolResult, error) {
+    var in delegateInput
+    if err := json.Unmarshal(input, &in); err != nil {
+        return ToolResult{}, fmt.Errorf("invalid delegation input: %w", err)
+    }
+    if t.run.exhausted() {
+        return ToolResult{Content: "The budget for this task is spent; answer with what you have.", IsError: true}, nil
+    }
+
+    result, err := t.agent.run(ctx, in.Task, t.agent.config.Tools, func(*AnthropicResponse) bool {
+        return t.run.exhausted()
+    }, nil)
+    if err != nil {
+        return ToolResult{}, err
+    }
+    t.run.add(result)
+    return ToolResult{Content: result.Output}, nil
+}
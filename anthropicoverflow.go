@@ -0,0 +1,176 @@
+package anthropic
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// ErrPromptTooLong is matched by every *PromptTooLongError
+var ErrPromptTooLong = errors.New("prompt is too long")
+
+// PromptTooLongError is returned when the API rejects a request for exceeding
+// the model's context window
+type PromptTooLongError struct {
+    Message string // The API's error message, including the token counts
+}
+
+func (e *PromptTooLongError) Error() string {
+    return "API error: invalid_request_error - " + e.Message
+}
+
+func (e *PromptTooLongError) Is(target error) bool {
+    return target == ErrPromptTooLong
+}
+
+// OverflowStrategy selects how a session recovers when its history no longer
+// fits the context window
+type OverflowStrategy string
+
+const (
+    OverflowFail      OverflowStrategy = ""          // Return the ErrPromptTooLong error (default)
+    OverflowTrim      OverflowStrategy = "trim"      // Drop the oldest turns
+    OverflowSummarize OverflowStrategy = "summarize" // Replace the oldest turns with a summary
+)
+
+const (
+    overflowSummaryMaxTokens = 500
+    overflowSummaryPrompt    = "Summarize the following conversation in a few short paragraphs. " +
+        "Keep names, numbers, decisions and open questions; the summary replaces the conversation " +
+        "as context for continuing it.\n\n"
+)
+
+// WithOverflowStrategy makes sessions recover from prompt-too-long errors by
+// shrinking their history to roughly half and retrying once. Summaries are
+// written with the title model (see WithTitleModel); if summarizing fails
+// the turns are dropped instead.
+func WithOverflowStrategy(strategy OverflowStrategy) ClientOption {
+    return func(c *AnthropicClient) {
+        switch strategy {
+        case OverflowFail, OverflowTrim, OverflowSummarize:
+            c.overflowStrategy = strategy
+        }
+    }
+}
+
+// sendHistory sends reqBody with the session history, shrinking the history
+// and retrying once if it no longer fits, and records the context usage of
+// the reply. The reply is streamed while ChatStreamWithTools is running.
+// Callers must hold s.mu.
+func (s *Session) sendHistory(ctx context.Context, reqBody Request) (*AnthropicResponse, error) {
+    send := s.client.sendRequest
+    if stream := s.stream; stream != nil {
+        send = func(ctx context.Context, session *Session, reqBody Request) (*AnthropicResponse, error) {
+            return s.client.streamRequest(ctx, session, reqBody, stream)
+        }
+    }
+    reqBody.Messages = s.conversation
+    resp, err := send(ctx, s, reqBody)
+    if errors.Is(err, ErrPromptTooLong) && s.shrinkHistory(ctx) {
+        reqBody.Messages = s.conversation
+        resp, err = send(ctx, s, reqBody)
+    }
+    if err != nil {
+        return nil, err
+    }
+    s.measureContext(reqBody.Model, resp)
+    return resp, nil
+}
+
+// shrinkHistory applies the overflow strategy, reporting whether the history
+// changed. Callers must hold s.mu.
+func (s *Session) shrinkHistory(ctx context.Context) bool {
+    if s.client.overflowStrategy == OverflowFail {
+        return false
+    }
+    cut := overflowCut(s.conversation)
+    if cut == 0 {
+        logMessage("Prompt too long, but the history cannot be shortened")
+        return false
+    }
+
+    kept := append([]Message{}, s.conversation[cut:]...)
+    if s.client.overflowStrategy == OverflowSummarize {
+        summary, err := s.summarizeTurns(ctx, s.conversation[:cut])
+        if err != nil {
+            logMessage("Summarizing history failed, dropping turns instead: %v", err)
+        } else {
+            first := kept[0]
+            first.Content = append([]MessageContent{{
+                Type: ContentTypeText,
+                Text: "Summary of the earlier conversation:\n" + summary,
+            }}, first.Content...)
+            kept[0] = first
+        }
+    }
+
+    logMessage("Prompt too long; removed %d of %d messages (%s)", cut, len(s.conversation), s.client.overflowStrategy)
+    s.conversation = kept
+    return true
+}
+
+// overflowCut picks where to cut the history: the first user turn past the
+// midpoint that is not a tool result, so tool calls stay paired with their
+// results. It falls back to the last such turn, and returns 0 when only the
+// first turn qualifies.
+func overflowCut(conversation []Message) int {
+    cut := 0
+    for i := 1; i < len(conversation); i++ {
+        if conversation[i].Role != RoleUser || hasToolResult(conversation[i].Content) {
+            continue
+        }
+        cut = i
+        if i >= len(conversation)/2 {
+            break
+        }
+    }
+    return cut
+}
+
+func hasToolResult(content []MessageContent) bool {
+    for _, c := range content {
+        if c.Type == ContentTypeToolResult {
+            return true
+        }
+    }
+    return false
+}
+
+// summarizeTurns asks the title model for a summary of messages
+func (s *Session) summarizeTurns(ctx context.Context, messages []Message) (string, error) {
+    transcript := conversationTranscript(messages, len(messages))
+    if transcript == "" {
+        return "", fmt.Errorf("no text to summarize")
+    }
+    model := s.client.titleModel
+    if model == "" {
+        model = defaultTitleModel
+    }
+
+    resp, err := s.client.sendRequest(ctx, s, Request{
+        Model: model,
+        Messages: []Message{{
+            Role: RoleUser,
+            Content: []MessageContent{{
+                Type: ContentTypeText,
+                Text: overflowSummaryPrompt + transcript,
+            }},
+        }},
+        MaxTokens: overflowSummaryMaxTokens,
+    })
+    if err != nil {
+        return "", fmt.Errorf("summary request error: %w", err)
+    }
+
+    var summary strings.Builder
+    for _, content := range resp.Content {
+        if content.Type == ContentTypeText {
+            summary.WriteString(content.Text)
+        }
+    }
+    if strings.TrimSpace(summary.String()) == "" {
+        return "", fmt.Errorf("model returned an empty summary")
+    }
+    return summary.String(), nil
+}
@@ -0,0 +1,83 @@
+package anthropic
+
+// Float returns a pointer to f, for setting the optional float parameters of
+// MessageParams, e.g. Temperature: anthropic.Float(0)
+func Float(f float64) *float64 {
+    return &f
+}
+
+// Int returns a pointer to i, for setting the optional integer parameters of
+// MessageParams, e.g. TopK: anthropic.Int(40)
+func Int(i int) *int {
+    return &i
+}
+
+// Merge returns p with every field set in override taking its place, so a
+// partial MessageParams (only MaxTokens, say) can be layered over defaults.
+// A field counts as set when it is non-zero: non-empty strings, non-zero
+// numbers, non-nil pointers, slices and maps. An empty but non-nil slice
+// such as Tools: []Tool{} therefore clears the default.
+func (p MessageParams) Merge(override *MessageParams) MessageParams {
+    if override == nil {
+        return p
+    }
+    o := override
+    if o.Model != "" {
+        p.Model = o.Model
+    }
+    if o.MaxTokens != 0 {
+        p.MaxTokens = o.MaxTokens
+    }
+    if o.Temperature != nil {
+        p.Temperature = o.Temperature
+    }
+    if o.TopP != nil {
+        p.TopP = o.TopP
+    }
+    if o.TopK != nil {
+        p.TopK = o.TopK
+    }
+    if o.StopSequences != nil {
+        p.StopSequences = o.StopSequences
+    }
+    if o.Metadata != nil {
+        p.Metadata = o.Metadata
+    }
+    if o.System != "" {
+        p.System = o.System
+    }
+    if o.SystemBlocks != nil {
+        p.SystemBlocks = o.SystemBlocks
+    }
+    if o.Tools != nil {
+        p.Tools = o.Tools
+    }
+    if o.ToolChoice != nil {
+        p.ToolChoice = o.ToolChoice
+    }
+    if o.MCPServers != nil {
+        p.MCPServers = o.MCPServers
+    }
+    if o.MaxToolIterations != 0 {
+        p.MaxToolIterations = o.MaxToolIterations
+    }
+    if o.StopWhen != nil {
+        p.StopWhen = o.StopWhen
+    }
+    if o.MaxOutputRepairs != 0 {
+        p.MaxOutputRepairs = o.MaxOutputRepairs
+    }
+    if o.MaxContinuations != 0 {
+        p.MaxContinuations = o.MaxContinuations
+    }
+    if o.Timeout != 0 {
+        p.Timeout = o.Timeout
+    }
+    if o.Priority != PriorityInteractive {
+        p.Priority = o.Priority
+    }
+    if o.IdempotencyKey != "" {
+        p.IdempotencyKey = o.IdempotencyKey
+    }
+    return p
+}
@@ -0,0 +1,73 @@
+package anthropic
+
+import (
+    "fmt"
+    "sort"
+    "sync"
+)
+
+// DefaultPersona is the neutral persona new clients start with
+const DefaultPersona = "assistant"
+
+// personas holds the named system prompt templates WithDefaultPersona can select
+var (
+    personasMu sync.RWMutex
+    personas   = map[string]*SystemPromptTemplate{
+        DefaultPersona: defaultSystemTemplate,
+    }
+)
+
+// RegisterPersona adds a named system prompt template that clients can select
+// with WithDefaultPersona. The text is parsed as a SystemPromptTemplate, so it
+// can list the request's tools with {{toolList .Tools}}. Registering an
+// existing name replaces it.
+func RegisterPersona(name, text string) error {
+    if name == "" {
+        return fmt.Errorf("persona name must not be empty")
+    }
+    tmpl, err := ParseSystemPrompt(text)
+    if err != nil {
+        return fmt.Errorf("invalid persona %s: %w", name, err)
+    }
+    personasMu.Lock()
+    defer personasMu.Unlock()
+
+    personas[name] = tmpl
+    return nil
+}
+
+// Persona returns a registered persona's template
+func Persona(name string) (*SystemPromptTemplate, bool) {
+    personasMu.RLock()
+    defer personasMu.RUnlock()
+
+    tmpl, ok := personas[name]
+    return tmpl, ok
+}
+
+// Personas returns the registered persona names in sorted order
+func Personas() []string {
+    personasMu.RLock()
+    defer personasMu.RUnlock()
+
+    names := make([]string, 0, len(personas))
+    for name := range personas {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// WithDefaultPersona uses a registered persona as the client system prompt.
+// An unknown name is logged and the current prompt kept.
+func WithDefaultPersona(name string) ClientOption {
+    return func(c *AnthropicClient) {
+        tmpl, ok := Persona(name)
+        if !ok {
+            logMessage("Unknown persona %q, keeping the current system prompt", name)
+            return
+        }
+        c.systemTemplate = tmpl
+        c.systemPrompt = tmpl.String()
+    }
+}
@@ -0,0 +1,215 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+    "regexp"
+    "strings"
+    "sync"
+)
+
+// PIIPattern describes one kind of personal data to mask. Validate, when set,
+// filters regexp matches, e.g. to drop digit runs that fail a checksum.
+type PIIPattern struct {
+    Name     string // Used in the mask, e.g. EMAIL gives [EMAIL] or [EMAIL_1]
+    Regexp   *regexp.Regexp
+    Validate func(match string) bool
+}
+
+// Built-in patterns. Credit cards must pass the Luhn check, and are matched
+// before phone numbers so long digit runs are not half-masked as a phone.
+var (
+    EmailPattern = PIIPattern{
+        Name:   "EMAIL",
+        Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+    }
+    CreditCardPattern = PIIPattern{
+        Name:     "CREDIT_CARD",
+        Regexp:   regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+        Validate: luhnValid,
+    }
+    PhonePattern = PIIPattern{
+        Name:   "PHONE",
+        Regexp: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{3}\) ?|\b\d{3}[ .-]?)\d{3}[ .-]?\d{4}\b`),
+    }
+)
+
+// DefaultPIIPatterns returns the built-in patterns in the order they are applied
+func DefaultPIIPatterns() []PIIPattern {
+    return []PIIPattern{EmailPattern, CreditCardPattern, PhonePattern}
+}
+
+// piiToken matches the tokens a tokenizing redactor produces
+var piiToken = regexp.MustCompile(`\[[A-Z][A-Z0-9_]*_\d+\]`)
+
+// PIIRedactorConfig configures a PIIRedactor
+type PIIRedactorConfig struct {
+    Patterns []PIIPattern // nil uses DefaultPIIPatterns; append custom patterns to those for both
+
+    // Tokenize replaces each distinct value with a numbered token ([EMAIL_1])
+    // that Restore maps back, instead of a plain [EMAIL] mask. Tool calls
+    // can then act on the real values; see ToolMiddleware.
+    Tokenize bool
+}
+
+// PIIRedactor masks personal data in text. With tokenization it remembers
+// the values it replaced, so it is safe for concurrent use but should be
+// scoped to the conversations that may see its tokens.
+type PIIRedactor struct {
+    patterns []PIIPattern
+    tokenize bool
+
+    mu       sync.Mutex
+    tokens   map[string]string // Original value to token
+    values   map[string]string // Token to original value
+    counters map[string]int
+}
+
+// NewPIIRedactor creates a redactor
+func NewPIIRedactor(cfg PIIRedactorConfig) *PIIRedactor {
+    patterns := cfg.Patterns
+    if patterns == nil {
+        patterns = DefaultPIIPatterns()
+    }
+    return &PIIRedactor{
+        patterns: patterns,
+        tokenize: cfg.Tokenize,
+        tokens:   make(map[string]string),
+        values:   make(map[string]string),
+        counters: make(map[string]int),
+    }
+}
+
+// Redact masks every pattern match in text
+func (r *PIIRedactor) Redact(text string) string {
+    for _, p := range r.patterns {
+        text = p.Regexp.ReplaceAllStringFunc(text, func(match string) string {
+            if piiToken.MatchString(match) || (p.Validate != nil && !p.Validate(match)) {
+                return match
+            }
+            return r.mask(p.Name, match)
+        })
+    }
+    return text
+}
+
+// Restore replaces tokens produced by Redact with the original values.
+// Unknown tokens, and text from a non-tokenizing redactor, are left as is.
+func (r *PIIRedactor) Restore(text string) string {
+    if !r.tokenize {
+        return text
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    return piiToken.ReplaceAllStringFunc(text, func(token string) string {
+        if value, ok := r.values[token]; ok {
+            return value
+        }
+        return token
+    })
+}
+
+func (r *PIIRedactor) mask(name, value string) string {
+    if !r.tokenize {
+        return "[" + name + "]"
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if token, ok := r.tokens[value]; ok {
+        return token
+    }
+    r.counters[name]++
+    token := fmt.Sprintf("[%s_%d]", name, r.counters[name])
+    r.tokens[value] = token
+    r.values[token] = value
+    return token
+}
+
+// RedactAudit masks the request and response bodies of an audit record; its
+// signature matches AuditConfig.Redact
+func (r *PIIRedactor) RedactAudit(record *AuditRecord) {
+    if redacted, err := RedactJSONStrings(record.Request, r.Redact); err == nil {
+        record.Request = redacted
+    }
+    if redacted, err := RedactJSONStrings(record.Response, r.Redact); err == nil {
+        record.Response = redacted
+    }
+}
+
+// ToolMiddleware restores tokenized values in tool inputs before the handler
+// runs, so tools act on the real data, and masks the handler's result before
+// it is sent back to Claude
+func (r *PIIRedactor) ToolMiddleware() ToolMiddleware {
+    return func(next ToolExecutor) ToolExecutor {
+        return func(ctx context.Context, call ToolUse) (ToolResult, error) {
+            if restored, err := RedactJSONStrings(call.Input, r.Restore); err == nil {
+                call.Input = restored
+            }
+            result, err := next(ctx, call)
+            result.Content = r.Redact(result.Content)
+            return result, err
+        }
+    }
+}
+
+// WithPIIRedaction masks personal data in the messages sent to the API. The
+// session history keeps the original text; only the outgoing copy is
+// redacted. System prompts are sent unchanged. Add r.ToolMiddleware() as
+// well when tokenizing, so tools receive the real values.
+func WithPIIRedaction(r *PIIRedactor) ClientOption {
+    return func(c *AnthropicClient) {
+        c.piiRedactor = r
+    }
+}
+
+// redactMessages returns a copy of messages with user-visible text redacted
+func (r *PIIRedactor) redactMessages(messages []Message) []Message {
+    redacted := make([]Message, len(messages))
+    for i, msg := range messages {
+        redacted[i] = Message{Role: msg.Role, Content: r.redactContent(msg.Content)}
+    }
+    return redacted
+}
+
+func (r *PIIRedactor) redactContent(contents []MessageContent) []MessageContent {
+    if contents == nil {
+        return nil
+    }
+    redacted := make([]MessageContent, len(contents))
+    for i, content := range contents {
+        switch content.Type {
+        case ContentTypeText:
+            content.Text = r.Redact(content.Text)
+        case ContentTypeToolUse:
+            if input, err := RedactJSONStrings(content.Input, r.Redact); err == nil {
+                content.Input = input
+            }
+        case ContentTypeToolResult:
+            content.Content = r.Redact(content.Content)
+            content.ContentBlocks = r.redactContent(content.ContentBlocks)
+        }
+        redacted[i] = content
+    }
+    return redacted
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum
+func luhnValid(s string) bool {
+    digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+    sum := 0
+    double := false
+    for i := len(digits) - 1; i >= 0; i-- {
+        d := int(digits[i] - '0')
+        if double {
+            d *= 2
+            if d > 9 {
+                d -= 9
+            }
+        }
+        sum += d
+        double = !double
+    }
+    return sum%10 == 0
+}
@@ -0,0 +1,70 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "time"
+)
+
+const defaultModelsEndpoint = "https://api.anthropic.com/v1/models"
+
+// PingResult reports the outcome of a Ping
+type PingResult struct {
+    Latency       time.Duration // Round trip of the check request
+    StatusCode    int           // HTTP status of the response
+    Authenticated bool          // The API accepted the client's credentials
+    RequestID     string        // request-id header, for support tickets
+}
+
+// Ping checks that the API is reachable with the client's credentials by
+// listing a single model, which costs no tokens. It is meant for readiness
+// probes. The error is nil only when the API answered successfully; the
+// result is returned whenever a response arrived, so callers can tell bad
+// credentials (Authenticated false) from an outage.
+func (c *AnthropicClient) Ping(ctx context.Context) (*PingResult, error) {
+    if err := c.inflight.begin(); err != nil {
+        return nil, err
+    }
+    defer c.inflight.end()
+
+    if c.requestTimeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+        defer cancel()
+    }
+    req, err := http.NewRequestWithContext(ctx, "GET", defaultModelsEndpoint+"?limit=1", nil)
+    if err != nil {
+        return nil, fmt.Errorf("error creating request: %w", err)
+    }
+    req.Header.Set("anthropic-version", "2023-06-01")
+    if err := c.setAuthHeaders(ctx, req); err != nil {
+        logMessage("Error setting credentials: %v", err)
+        return nil, err
+    }
+
+    start := time.Now()
+    resp, err := c.httpClient.Do(req)
+    if err != nil {
+        logMessage("Ping failed: %v", err)
+        return nil, fmt.Errorf("error sending request: %w", err)
+    }
+    defer resp.Body.Close()
+    body, err := ioutil.ReadAll(resp.Body)
+    result := &PingResult{
+        Latency:       time.Since(start),
+        StatusCode:    resp.StatusCode,
+        Authenticated: resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden,
+        RequestID:     resp.Header.Get("request-id"),
+    }
+    if err != nil {
+        return result, fmt.Errorf("error reading response: %w", err)
+    }
+    logMessage("Ping returned status %d in %s", resp.StatusCode, result.Latency)
+
+    if resp.StatusCode != http.StatusOK {
+        return result, responseError(resp.StatusCode, body)
+    }
+    return result, nil
+}
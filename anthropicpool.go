@@ -0,0 +1,126 @@
+package anthropic
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "sync"
+)
+
+// ErrUnknownTenant is returned when a ClientPool has no client for a tenant
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// TenantConfig describes one tenant of a ClientPool
+type TenantConfig struct {
+    APIKey            string         // The tenant's own key; empty uses the base client's credentials
+    APIKeyProvider    APIKeyProvider // Fetches the tenant's key per request; overrides APIKey
+    RequestsPerMinute int            // Per-tenant rate limit; 0 shares the base client's limit
+    Budget            Budget         // Per-tenant spending limit; zero means unlimited
+    Options           []ClientOption // Any other per-tenant settings, applied last
+}
+
+// ClientPool serves many tenants, each with their own credentials, rate
+// limit and budget, from one service. Every tenant client is derived from a
+// base client with With, so all tenants share one HTTP transport and
+// connection pool. Each tenant gets its own response cache. It is safe for
+// concurrent use.
+//
+//     pool := anthropic.NewClientPool(anthropic.NewClient("", anthropic.WithDefaultParams(defaults)))
+//     pool.AddTenant("acme", anthropic.TenantConfig{APIKey: acmeKey, RequestsPerMinute: 50, Budget: anthropic.Budget{MaxUSD: 100}})
+//
+//     client, err := pool.Client("acme")
+type ClientPool struct {
+    mu      sync.RWMutex
+    base    *AnthropicClient
+    tenants map[string]*AnthropicClient
+}
+
+// NewClientPool creates a pool whose tenants inherit base's settings
+func NewClientPool(base *AnthropicClient) *ClientPool {
+    return &ClientPool{
+        base:    base,
+        tenants: make(map[string]*AnthropicClient),
+    }
+}
+
+// AddTenant creates the client for a tenant, replacing any existing one. A
+// replaced tenant starts again with empty usage totals.
+func (p *ClientPool) AddTenant(id string, cfg TenantConfig) (*AnthropicClient, error) {
+    if id == "" {
+        return nil, fmt.Errorf("tenant ID must not be empty")
+    }
+    if cfg.RequestsPerMinute < 0 {
+        return nil, fmt.Errorf("tenant %s: requests per minute must not be negative", id)
+    }
+
+    var opts []ClientOption
+    if cfg.APIKey != "" {
+        opts = append(opts, WithAPIKey(cfg.APIKey))
+    }
+    if cfg.APIKeyProvider != nil {
+        opts = append(opts, WithAPIKeyProvider(cfg.APIKeyProvider))
+    }
+    if cfg.RequestsPerMinute > 0 {
+        opts = append(opts, WithRateLimit(cfg.RequestsPerMinute))
+    }
+    opts = append(opts, WithBudget(cfg.Budget))
+    opts = append(opts, cfg.Options...)
+
+    client := p.base.With(opts...)
+    // Tenants never answer from each other's cached responses
+    if client.cache == p.base.cache {
+        client.cache = p.base.cache.empty()
+    }
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    p.tenants[id] = client
+    logMessage("Added tenant %s to client pool", id)
+    return client, nil
+}
+
+// Client returns a tenant's client, or an error wrapping ErrUnknownTenant
+func (p *ClientPool) Client(id string) (*AnthropicClient, error) {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+
+    client, ok := p.tenants[id]
+    if !ok {
+        return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, id)
+    }
+    return client, nil
+}
+
+// RemoveTenant drops a tenant's client. Calls already in progress finish.
+func (p *ClientPool) RemoveTenant(id string) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    delete(p.tenants, id)
+}
+
+// Tenants returns the tenant IDs in sorted order
+func (p *ClientPool) Tenants() []string {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+
+    ids := make([]string, 0, len(p.tenants))
+    for id := range p.tenants {
+        ids = append(ids, id)
+    }
+    sort.Strings(ids)
+    return ids
+}
+
+// UsageReports returns each tenant's cumulative usage, keyed by tenant ID
+func (p *ClientPool) UsageReports() map[string]UsageReport {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+
+    reports := make(map[string]UsageReport, len(p.tenants))
+    for id, client := range p.tenants {
+        reports[id] = client.UsageReport()
+    }
+    return reports
+}
@@ -0,0 +1,86 @@
+package anthropic
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// ErrInvalidRequest is matched by every *RequestValidationError
+var ErrInvalidRequest = errors.New("invalid request")
+
+// RequestValidationError lists the problems found in a request before it was
+// sent, in place of the API's 400 response
+type RequestValidationError struct {
+    Model    string
+    Problems []string
+}
+
+func (e *RequestValidationError) Error() string {
+    return fmt.Sprintf("invalid request for %s: %s", e.Model, strings.Join(e.Problems, "; "))
+}
+
+func (e *RequestValidationError) Is(target error) bool {
+    return target == ErrInvalidRequest
+}
+
+// WithStrictValidation rejects requests whose MaxTokens exceeds the model's
+// output limit instead of lowering it to the limit
+func WithStrictValidation() ClientOption {
+    return func(c *AnthropicClient) {
+        c.strictValidation = true
+    }
+}
+
+// validateRequest checks a request against the model's capabilities. Models
+// missing from the capability table are only checked for well-formed messages.
+func (c *AnthropicClient) validateRequest(req Request) error {
+    var problems []string
+    if req.Model == "" {
+        problems = append(problems, "model is not set")
+    }
+    if len(req.Messages) == 0 {
+        problems = append(problems, "messages must contain at least one message")
+    }
+
+    caps, known := c.ModelCapabilities(req.Model)
+    if known && c.strictValidation && caps.MaxOutputTokens > 0 && req.MaxTokens > caps.MaxOutputTokens {
+        problems = append(problems, fmt.Sprintf("max_tokens %d exceeds the model's limit of %d", req.MaxTokens, caps.MaxOutputTokens))
+    }
+
+    var images, thinking bool
+    for i, msg := range req.Messages {
+        if len(msg.Content) == 0 {
+            problems = append(problems, fmt.Sprintf("message %d has no content", i))
+        }
+        for j, content := range msg.Content {
+            if content.Type == ContentTypeText && content.Text == "" {
+                problems = append(problems, fmt.Sprintf("message %d block %d is an empty text block", i, j))
+            }
+        }
+        images = images || containsType(msg.Content, ContentTypeImage)
+        thinking = thinking || containsType(msg.Content, ContentTypeThinking)
+    }
+    if known && images && !caps.Vision {
+        problems = append(problems, "the model does not accept images")
+    }
+    if known && thinking && !caps.Thinking {
+        problems = append(problems, "the model does not support extended thinking")
+    }
+
+    if len(problems) > 0 {
+        return &RequestValidationError{Model: req.Model, Problems: problems}
+    }
+    return nil
+}
+
+// containsType reports whether contents, or the blocks nested in their tool
+// results, include a block of type t
+func containsType(contents []MessageContent, t ContentType) bool {
+    for _, content := range contents {
+        if content.Type == t || containsType(content.ContentBlocks, t) {
+            return true
+        }
+    }
+    return false
+}
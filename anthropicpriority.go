@@ -0,0 +1,42 @@
+package anthropic
+
+import "context"
+
+// Priority ranks a request for rate-limit capacity. When a client's rate
+// limit (see WithRateLimit) leaves requests waiting, interactive requests
+// are sent before batch ones, whenever they were queued.
+type Priority int
+
+const (
+    PriorityInteractive Priority = iota // Default; a user is waiting on the answer
+    PriorityBatch                       // Background work that can wait
+)
+
+const priorityLevels = int(PriorityBatch) + 1
+
+// WithPriority sets the priority class of one call
+func WithPriority(p Priority) RequestOption {
+    return func(params *MessageParams) {
+        params.Priority = p
+    }
+}
+
+type priorityKey struct{}
+
+// withPriority attaches p to ctx for the rate limiter
+func withPriority(ctx context.Context, p Priority) context.Context {
+    return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// requestPriority returns the priority attached to ctx, clamped to the
+// known classes
+func requestPriority(ctx context.Context) Priority {
+    p, _ := ctx.Value(priorityKey{}).(Priority)
+    if p < 0 {
+        return PriorityInteractive
+    }
+    if int(p) >= priorityLevels {
+        return PriorityBatch
+    }
+    return p
+}
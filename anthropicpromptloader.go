@@ -0,0 +1,135 @@
+package anthropic
+
+import (
+    "bufio"
+    "fmt"
+    "io/fs"
+    "path"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// promptFileExtensions are the files LoadPrompts reads; others are skipped
+var promptFileExtensions = []string{".txt", ".md", ".prompt", ".tmpl"}
+
+// promptSectionHeader matches a "[section]" line starting a named section
+var promptSectionHeader = regexp.MustCompile(`^\[([A-Za-z0-9_.-]+)\]\s*$`)
+
+// LoadPromptLibrary creates a library from the prompt files in fsys (see
+// PromptLibrary.LoadPrompts)
+func LoadPromptLibrary(fsys fs.FS) (*PromptLibrary, error) {
+    l := NewPromptLibrary()
+    if err := l.LoadPrompts(fsys); err != nil {
+        return nil, err
+    }
+    return l, nil
+}
+
+// LoadPrompts reads every .txt, .md, .prompt and .tmpl file in fsys, which
+// may be an embed.FS so prompts ship inside the binary:
+//
+//     //go:embed prompts
+//     var promptFiles embed.FS
+//
+//     sub, _ := fs.Sub(promptFiles, "prompts")
+//     library, err := anthropic.LoadPromptLibrary(sub)
+//
+// Prompts use the same name:section addressing as messagefile. A file's path
+// without its extension is its name, and "[section]" lines split it into
+// sections: systemprompt.txt containing "[systempromptmain]" defines
+// "systemprompt:systempromptmain". Text before the first header, if any, is
+// the prompt named by the file alone. Every loaded prompt can also be
+// included in the others as a partial, e.g. {{template "common:signature" .}}.
+func (l *PromptLibrary) LoadPrompts(fsys fs.FS) error {
+    texts := map[string]string{}
+    err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return err
+        }
+        ext := path.Ext(p)
+        if d.IsDir() || !containsString(promptFileExtensions, ext) {
+            return nil
+        }
+        data, err := fs.ReadFile(fsys, p)
+        if err != nil {
+            return fmt.Errorf("error reading prompt file %s: %w", p, err)
+        }
+        sections, err := parsePromptSections(strings.TrimSuffix(p, ext), string(data))
+        if err != nil {
+            return fmt.Errorf("error parsing prompt file %s: %w", p, err)
+        }
+        for address, text := range sections {
+            if _, exists := texts[address]; exists {
+                return fmt.Errorf("prompt %s is defined more than once", address)
+            }
+            texts[address] = text
+        }
+        return nil
+    })
+    if err != nil {
+        return err
+    }
+
+    addresses := make([]string, 0, len(texts))
+    for address := range texts {
+        addresses = append(addresses, address)
+    }
+    sort.Strings(addresses)
+
+    // Register every prompt as a partial first so prompts can include each
+    // other regardless of file order
+    for _, address := range addresses {
+        if err := l.AddPartial(address, texts[address]); err != nil {
+            return err
+        }
+    }
+    for _, address := range addresses {
+        if _, err := l.Define(address, "", texts[address]); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// parsePromptSections splits a prompt file into its name:section prompts
+func parsePromptSections(name, data string) (map[string]string, error) {
+    sections := map[string]string{}
+    address := name
+    var body strings.Builder
+
+    flush := func() error {
+        text := strings.TrimSpace(body.String())
+        body.Reset()
+        if address == name && text == "" {
+            return nil // No text before the first header
+        }
+        if _, exists := sections[address]; exists {
+            return fmt.Errorf("section %s appears more than once", address)
+        }
+        sections[address] = text
+        return nil
+    }
+
+    scanner := bufio.NewScanner(strings.NewReader(data))
+    scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+    for scanner.Scan() {
+        line := scanner.Text()
+        if m := promptSectionHeader.FindStringSubmatch(line); m != nil {
+            if err := flush(); err != nil {
+                return nil, err
+            }
+            address = name + ":" + m[1]
+            continue
+        }
+        body.WriteString(line)
+        body.WriteByte('\n')
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+    if err := flush(); err != nil {
+        return nil, err
+    }
+    return sections, nil
+}
@@ -0,0 +1,279 @@
+package anthropic
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+    "text/template"
+    "text/template/parse"
+)
+
+// PromptTemplate is a reusable user-message prompt with named variables,
+// written in text/template syntax ({{.customer}}). The variables it uses are
+// checked against those it declares when it is defined, and against those
+// supplied when it is rendered, so a renamed variable fails loudly instead of
+// rendering an empty string.
+type PromptTemplate struct {
+    Name    string
+    Version string
+    vars    []string // Sorted variable names
+    tmpl    *template.Template
+}
+
+// NewPromptTemplate parses a standalone prompt. When vars are given they must
+// match the variables the text references exactly; otherwise the referenced
+// variables become the declared set.
+func NewPromptTemplate(name, text string, vars ...string) (*PromptTemplate, error) {
+    return newPromptTemplate(name, "", text, nil, vars)
+}
+
+func newPromptTemplate(name, version, text string, partials map[string]string, vars []string) (*PromptTemplate, error) {
+    root := template.New(name).Option("missingkey=error")
+    for partialName, partialText := range partials {
+        if _, err := root.New(partialName).Parse(partialText); err != nil {
+            return nil, fmt.Errorf("invalid partial %s: %w", partialName, err)
+        }
+    }
+    tmpl, err := root.New(name).Parse(text)
+    if err != nil {
+        return nil, fmt.Errorf("invalid prompt %s: %w", name, err)
+    }
+
+    used := map[string]bool{}
+    if err := collectTemplateVars(tmpl, tmpl.Tree.Root, used, map[string]bool{}); err != nil {
+        return nil, fmt.Errorf("invalid prompt %s: %w", name, err)
+    }
+    if len(vars) > 0 {
+        declared := map[string]bool{}
+        for _, v := range vars {
+            declared[v] = true
+            if !used[v] {
+                return nil, fmt.Errorf("invalid prompt %s: declared variable %q is never used", name, v)
+            }
+        }
+        for v := range used {
+            if !declared[v] {
+                return nil, fmt.Errorf("invalid prompt %s: variable %q is used but not declared", name, v)
+            }
+        }
+    }
+
+    names := make([]string, 0, len(used))
+    for v := range used {
+        names = append(names, v)
+    }
+    sort.Strings(names)
+    return &PromptTemplate{Name: name, Version: version, vars: names, tmpl: tmpl}, nil
+}
+
+// Vars returns the prompt's variable names in sorted order
+func (p *PromptTemplate) Vars() []string {
+    vars := make([]string, len(p.vars))
+    copy(vars, p.vars)
+    return vars
+}
+
+// Render fills in the prompt. Every variable must be supplied, and supplying
+// one the prompt does not use is an error.
+func (p *PromptTemplate) Render(vars map[string]interface{}) (string, error) {
+    var missing, unused []string
+    for _, v := range p.vars {
+        if _, ok := vars[v]; !ok {
+            missing = append(missing, v)
+        }
+    }
+    for v := range vars {
+        if !containsString(p.vars, v) {
+            unused = append(unused, v)
+        }
+    }
+    if len(missing) > 0 {
+        return "", fmt.Errorf("prompt %s: missing variables: %s", p.Name, strings.Join(missing, ", "))
+    }
+    if len(unused) > 0 {
+        sort.Strings(unused)
+        return "", fmt.Errorf("prompt %s: unused variables: %s", p.Name, strings.Join(unused, ", "))
+    }
+
+    var sb strings.Builder
+    if err := p.tmpl.Execute(&sb, vars); err != nil {
+        return "", fmt.Errorf("error rendering prompt %s: %w", p.Name, err)
+    }
+    return sb.String(), nil
+}
+
+// Message renders the prompt as a user message
+func (p *PromptTemplate) Message(vars map[string]interface{}) (Message, error) {
+    text, err := p.Render(vars)
+    if err != nil {
+        return Message{}, err
+    }
+    return Message{Role: RoleUser, Content: []MessageContent{{Type: ContentTypeText, Text: text}}}, nil
+}
+
+// collectTemplateVars records the top-level fields a template references,
+// following {{template}} calls into partials. Inside range and with blocks
+// dot is rebound, so only their pipelines are inspected.
+func collectTemplateVars(tmpl *template.Template, node parse.Node, used map[string]bool, visiting map[string]bool) error {
+    switch n := node.(type) {
+    case *parse.ListNode:
+        if n == nil {
+            return nil
+        }
+        for _, child := range n.Nodes {
+            if err := collectTemplateVars(tmpl, child, used, visiting); err != nil {
+                return err
+            }
+        }
+    case *parse.ActionNode:
+        return collectTemplateVars(tmpl, n.Pipe, used, visiting)
+    case *parse.PipeNode:
+        if n == nil {
+            return nil
+        }
+        for _, cmd := range n.Cmds {
+            for _, arg := range cmd.Args {
+                if err := collectTemplateVars(tmpl, arg, used, visiting); err != nil {
+                    return err
+                }
+            }
+        }
+    case *parse.FieldNode:
+        used[n.Ident[0]] = true
+    case *parse.VariableNode:
+        if len(n.Ident) > 1 && n.Ident[0] == "$" {
+            used[n.Ident[1]] = true
+        }
+    case *parse.ChainNode:
+        return collectTemplateVars(tmpl, n.Node, used, visiting)
+    case *parse.IfNode:
+        return collectBranchVars(tmpl, &n.BranchNode, true, used, visiting)
+    case *parse.RangeNode:
+        return collectBranchVars(tmpl, &n.BranchNode, false, used, visiting)
+    case *parse.WithNode:
+        return collectBranchVars(tmpl, &n.BranchNode, false, used, visiting)
+    case *parse.TemplateNode:
+        if err := collectTemplateVars(tmpl, n.Pipe, used, visiting); err != nil {
+            return err
+        }
+        partial := tmpl.Lookup(n.Name)
+        if partial == nil || partial.Tree == nil {
+            return fmt.Errorf("undefined partial %q", n.Name)
+        }
+        if visiting[n.Name] {
+            return nil
+        }
+        visiting[n.Name] = true
+        defer delete(visiting, n.Name)
+        return collectTemplateVars(tmpl, partial.Tree.Root, used, visiting)
+    }
+    return nil
+}
+
+func collectBranchVars(tmpl *template.Template, n *parse.BranchNode, sameDot bool, used map[string]bool, visiting map[string]bool) error {
+    if err := collectTemplateVars(tmpl, n.Pipe, used, visiting); err != nil {
+        return err
+    }
+    if sameDot {
+        if err := collectTemplateVars(tmpl, n.List, used, visiting); err != nil {
+            return err
+        }
+    }
+    // The else branch of range and with runs with the original dot
+    return collectTemplateVars(tmpl, n.ElseList, used, visiting)
+}
+
+// PromptLibrary is a registry of versioned prompts sharing a set of partials,
+// so the same prompts can be reused from CLI and server code. It is safe for
+// concurrent use.
+type PromptLibrary struct {
+    mu       sync.RWMutex
+    partials map[string]string
+    prompts  map[string][]*PromptTemplate // Versions in registration order
+}
+
+// NewPromptLibrary creates an empty library
+func NewPromptLibrary() *PromptLibrary {
+    return &PromptLibrary{
+        partials: make(map[string]string),
+        prompts:  make(map[string][]*PromptTemplate),
+    }
+}
+
+// AddPartial defines a snippet prompts can include with {{template "name" .}}.
+// Partials must be added before the prompts that use them.
+func (l *PromptLibrary) AddPartial(name, text string) error {
+    if _, err := template.New(name).Parse(text); err != nil {
+        return fmt.Errorf("invalid partial %s: %w", name, err)
+    }
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    l.partials[name] = text
+    return nil
+}
+
+// Define parses and registers a version of a prompt. The most recently
+// defined version becomes the one Get returns; redefining an existing
+// version is an error.
+func (l *PromptLibrary) Define(name, version, text string, vars ...string) (*PromptTemplate, error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    for _, existing := range l.prompts[name] {
+        if existing.Version == version {
+            return nil, fmt.Errorf("prompt %s version %q is already defined", name, version)
+        }
+    }
+    prompt, err := newPromptTemplate(name, version, text, l.partials, vars)
+    if err != nil {
+        return nil, err
+    }
+    l.prompts[name] = append(l.prompts[name], prompt)
+    return prompt, nil
+}
+
+// Get returns the latest version of a prompt. "name@version" selects a
+// specific version.
+func (l *PromptLibrary) Get(name string) (*PromptTemplate, bool) {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+
+    name, version, pinned := strings.Cut(name, "@")
+    versions := l.prompts[name]
+    if len(versions) == 0 {
+        return nil, false
+    }
+    if !pinned {
+        return versions[len(versions)-1], true
+    }
+    for _, prompt := range versions {
+        if prompt.Version == version {
+            return prompt, true
+        }
+    }
+    return nil, false
+}
+
+// Render renders a prompt from the library by name (see Get)
+func (l *PromptLibrary) Render(name string, vars map[string]interface{}) (string, error) {
+    prompt, ok := l.Get(name)
+    if !ok {
+        return "", fmt.Errorf("prompt %s is not defined", name)
+    }
+    return prompt.Render(vars)
+}
+
+// Names returns the names of the defined prompts in sorted order
+func (l *PromptLibrary) Names() []string {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+
+    names := make([]string, 0, len(l.prompts))
+    for name := range l.prompts {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
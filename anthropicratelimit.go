@@ -0,0 +1,94 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+)
+
+// WithRateLimit caps the client at requestsPerMinute API requests. Requests
+// over the limit wait for capacity (or for their context to end) rather than
+// failing; bursts of up to a minute's allowance are let through at once.
+// Clients derived with With share the limit unless they set their own.
+func WithRateLimit(requestsPerMinute int) ClientOption {
+    return func(c *AnthropicClient) {
+        if requestsPerMinute > 0 {
+            c.rateLimiter = newRateLimiter(requestsPerMinute)
+        }
+    }
+}
+
+// rateLimiter is a token bucket refilled at a fixed rate
+type rateLimiter struct {
+    mu       sync.Mutex
+    interval time.Duration // Time to earn one request
+    capacity float64
+    tokens   float64
+    last     time.Time
+    waiting  [priorityLevels]int // Waiters per priority class
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+    return &rateLimiter{
+        interval: time.Minute / time.Duration(perMinute),
+        capacity: float64(perMinute),
+        tokens:   float64(perMinute),
+        last:     time.Now(),
+    }
+}
+
+// wait blocks until a request may be sent. A request only takes capacity
+// when no request of a higher priority (see requestPriority) is waiting.
+func (l *rateLimiter) wait(ctx context.Context) error {
+    priority := requestPriority(ctx)
+    l.mu.Lock()
+    l.waiting[priority]++
+    l.mu.Unlock()
+    defer func() {
+        l.mu.Lock()
+        l.waiting[priority]--
+        l.mu.Unlock()
+    }()
+
+    for {
+        l.mu.Lock()
+        now := time.Now()
+        l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+        if l.tokens > l.capacity {
+            l.tokens = l.capacity
+        }
+        l.last = now
+        outranked := l.outranked(priority)
+        if l.tokens >= 1 && !outranked {
+            l.tokens--
+            l.mu.Unlock()
+            return nil
+        }
+        delay := time.Duration((1 - l.tokens) * float64(l.interval))
+        if outranked {
+            // Let the waiting higher-priority requests go first
+            delay = l.interval
+        }
+        l.mu.Unlock()
+
+        timer := time.NewTimer(delay)
+        select {
+        case <-ctx.Done():
+            timer.Stop()
+            return fmt.Errorf("waiting for rate limit: %w", ctx.Err())
+        case <-timer.C:
+        }
+    }
+}
+
+// outranked reports whether requests of a higher priority than p are
+// waiting. Callers must hold l.mu.
+func (l *rateLimiter) outranked(p Priority) bool {
+    for higher := PriorityInteractive; higher < p; higher++ {
+        if l.waiting[higher] > 0 {
+            return true
+        }
+    }
+    return false
+}
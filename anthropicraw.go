@@ -0,0 +1,82 @@
+package anthropic
+
+import (
+    "bytes"
+    "context"
+    "io/ioutil"
+    "net/http"
+    "sync"
+)
+
+// RawResponse holds the HTTP response behind an API call, for inspecting
+// status codes and headers the package does not model (gateway headers,
+// rate limit details). Response.Body can be read again; it replays Body.
+// One RawResponse may be shared by concurrent calls, e.g. across BulkChat;
+// while they run, read it through its methods rather than its fields.
+type RawResponse struct {
+    Response *http.Response
+    Body     []byte
+
+    mu sync.Mutex
+}
+
+// StatusCode returns the HTTP status, or 0 if no response was received
+func (r *RawResponse) StatusCode() int {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.Response == nil {
+        return 0
+    }
+    return r.Response.StatusCode
+}
+
+// Header returns the response headers, or nil if no response was received
+func (r *RawResponse) Header() http.Header {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.Response == nil {
+        return nil
+    }
+    return r.Response.Header
+}
+
+// Bytes returns the response body, or nil if no response was received
+func (r *RawResponse) Bytes() []byte {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    return r.Body
+}
+
+type rawResponseKey struct{}
+
+// WithRawResponse returns a context that records the raw HTTP response of
+// each API request made with it into raw, including error responses:
+//
+//     var raw anthropic.RawResponse
+//     resp, err := client.ChatMe(anthropic.WithRawResponse(ctx, &raw), "Hello", nil)
+//     log.Println(raw.StatusCode(), raw.Header().Get("request-id"))
+//
+// Calls that make several requests, such as the tool loops, or several calls
+// sharing ctx, leave the last response in raw. For a successful stream, Body
+// is empty.
+func WithRawResponse(ctx context.Context, raw *RawResponse) context.Context {
+    return context.WithValue(ctx, rawResponseKey{}, raw)
+}
+
+// captureRawResponse stores resp and its already-read body in the context's
+// RawResponse, if any
+func captureRawResponse(ctx context.Context, resp *http.Response, body []byte) {
+    raw, ok := ctx.Value(rawResponseKey{}).(*RawResponse)
+    if !ok || raw == nil {
+        return
+    }
+    clone := *resp
+    clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+    raw.mu.Lock()
+    defer raw.mu.Unlock()
+    raw.Response = &clone
+    raw.Body = body
+}
@@ -0,0 +1,257 @@
+package anthropic
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "sync"
+)
+
+// ToolHandlerFunc is the signature shared by all tool handlers. It receives the
+// raw JSON input Claude supplied and returns the tool_result content.
+type ToolHandlerFunc func(context.Context, json.RawMessage) (string, error)
+
+// ToolRegistry keeps each tool's definition together with its implementation,
+// replacing hand-maintained parallel []Tool and handler maps. Registration order
+// is preserved in the generated Tools slice. A ToolRegistry is safe for concurrent use.
+type ToolRegistry struct {
+    mu    sync.RWMutex
+    order []string
+    tools map[string]registeredTool
+}
+
+type registeredTool struct {
+    def      Tool
+    handler  ToolHandler
+    terminal bool // Calling the tool ends the tool loop
+}
+
+// NewToolRegistry creates an empty registry
+func NewToolRegistry() *ToolRegistry {
+    return &ToolRegistry{
+        tools: make(map[string]registeredTool),
+    }
+}
+
+// RegisterTool validates the tool definition and adds it with its handler.
+// Registering a name twice is an error; use Unregister first to replace a tool.
+func (r *ToolRegistry) RegisterTool(def Tool, handler ToolHandlerFunc) error {
+    if handler == nil {
+        return fmt.Errorf("cannot register tool %s: handler is nil", def.Name)
+    }
+    return r.Register(NewFuncTool(def, handler))
+}
+
+// Register adds a ToolHandler, using its Definition for validation and naming
+func (r *ToolRegistry) Register(handler ToolHandler) error {
+    if handler == nil {
+        return fmt.Errorf("cannot register tool: handler is nil")
+    }
+    def := handler.Definition()
+    if err := validateToolDefinition(def); err != nil {
+        return fmt.Errorf("cannot register tool: %w", err)
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if _, exists := r.tools[def.Name]; exists {
+        return fmt.Errorf("tool %s is already registered", def.Name)
+    }
+    r.tools[def.Name] = registeredTool{def: def, handler: handler}
+    r.order = append(r.order, def.Name)
+    logMessage("Registered tool '%s'", def.Name)
+    return nil
+}
+
+// Unregister removes a tool from the registry, closing its handler if it
+// implements io.Closer. It reports whether the tool existed.
+func (r *ToolRegistry) Unregister(name string) bool {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    t, exists := r.tools[name]
+    if !exists {
+        return false
+    }
+    if err := closeToolHandler(t.handler); err != nil {
+        logMessage("Error closing tool '%s': %v", name, err)
+    }
+    delete(r.tools, name)
+    for i, n := range r.order {
+        if n == name {
+            r.order = append(r.order[:i], r.order[i+1:]...)
+            break
+        }
+    }
+    return true
+}
+
+// MarkTerminal flags registered tools as terminal: when Claude calls one (for
+// example submit_answer), the tool loop executes it and returns immediately
+// instead of sending the result back for another model turn. The call is
+// available as AnthropicResponse.TerminalCall.
+func (r *ToolRegistry) MarkTerminal(names ...string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    for _, name := range names {
+        t, exists := r.tools[name]
+        if !exists {
+            return fmt.Errorf("cannot mark tool %s as terminal: not registered", name)
+        }
+        t.terminal = true
+        r.tools[name] = t
+    }
+    return nil
+}
+
+// extend returns a copy of the registry, which may be nil, with extra tools
+// added; definitions and terminal flags of the existing tools carry over
+func (r *ToolRegistry) extend(extra ...ToolHandler) (*ToolRegistry, error) {
+    extended := NewToolRegistry()
+    if r != nil {
+        r.mu.RLock()
+        for _, name := range r.order {
+            extended.order = append(extended.order, name)
+            extended.tools[name] = r.tools[name]
+        }
+        r.mu.RUnlock()
+    }
+    for _, handler := range extra {
+        if err := extended.Register(handler); err != nil {
+            return nil, err
+        }
+    }
+    return extended, nil
+}
+
+// IsTerminal reports whether a registered tool ends the tool loop
+func (r *ToolRegistry) IsTerminal(name string) bool {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    return r.tools[name].terminal
+}
+
+// Handler returns the handler for a registered tool
+func (r *ToolRegistry) Handler(name string) (ToolHandler, bool) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    t, exists := r.tools[name]
+    return t.handler, exists
+}
+
+// Definition returns the definition a registered tool is advertised with
+func (r *ToolRegistry) Definition(name string) (Tool, bool) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    t, exists := r.tools[name]
+    return t.def, exists
+}
+
+// Close releases every registered handler that implements io.Closer and
+// returns the first error encountered.
+func (r *ToolRegistry) Close() error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    var firstErr error
+    for _, name := range r.order {
+        if err := closeToolHandler(r.tools[name].handler); err != nil {
+            logMessage("Error closing tool '%s': %v", name, err)
+            if firstErr == nil {
+                firstErr = fmt.Errorf("error closing tool %s: %w", name, err)
+            }
+        }
+    }
+    return firstErr
+}
+
+// Names returns the registered tool names in registration order
+func (r *ToolRegistry) Names() []string {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    names := make([]string, len(r.order))
+    copy(names, r.order)
+    return names
+}
+
+// Tools returns the tool definitions for use in MessageParams.Tools
+func (r *ToolRegistry) Tools() []Tool {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    tools := make([]Tool, 0, len(r.order))
+    for _, name := range r.order {
+        tools = append(tools, r.tools[name].def)
+    }
+    return tools
+}
+
+// Handlers returns the handler map expected by ChatWithTools and AChatWithTools.
+// Results flagged IsError are surfaced as handler errors.
+func (r *ToolRegistry) Handlers() map[string]func(context.Context, json.RawMessage) (string, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    handlers := make(map[string]func(context.Context, json.RawMessage) (string, error), len(r.tools))
+    for name, t := range r.tools {
+        handler := t.handler
+        handlers[name] = func(ctx context.Context, input json.RawMessage) (string, error) {
+            result, err := handler.Execute(ctx, input)
+            if err != nil {
+                return "", err
+            }
+            if result.IsError {
+                return "", errors.New(result.Content)
+            }
+            return result.Content, nil
+        }
+    }
+    return handlers
+}
+
+// WithToolRegistry attaches a registry to the client. The tool loops fall back to
+// the registry's definitions when a call supplies none, and to its handlers for
+// any tool missing from the call's handler map.
+func WithToolRegistry(registry *ToolRegistry) ClientOption {
+    return func(c *AnthropicClient) {
+        c.registry = registry
+    }
+}
+
+// isTerminalTool reports whether calling the named tool ends the tool loop
+func (c *AnthropicClient) isTerminalTool(ctx context.Context, name string) bool {
+    registry := c.registryFor(ctx)
+    return registry != nil && registry.IsTerminal(name)
+}
+
+type registryKey struct{}
+
+// withRegistry makes the tool loops on ctx use registry in place of the
+// client's, for callers such as Agent that bring their own tools
+func withRegistry(ctx context.Context, registry *ToolRegistry) context.Context {
+    return context.WithValue(ctx, registryKey{}, registry)
+}
+
+// registryFor returns the registry for tool calls made on ctx
+func (c *AnthropicClient) registryFor(ctx context.Context) *ToolRegistry {
+    if registry, ok := ctx.Value(registryKey{}).(*ToolRegistry); ok {
+        return registry
+    }
+    return c.registry
+}
+
+// resolveTools picks the tool definitions for a tool loop, preferring those
+// passed to the call over the client's registry.
+func (c *AnthropicClient) resolveTools(params *MessageParams) []Tool {
+    if len(params.Tools) == 0 && c.registry != nil {
+        return c.registry.Tools()
+    }
+    return params.Tools
+}
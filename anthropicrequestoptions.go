@@ -0,0 +1,92 @@
+package anthropic
+
+import (
+    "context"
+    "time"
+)
+
+// RequestOption overrides a parameter for a single call, on top of the
+// MessageParams passed to it and the client defaults:
+//
+//     resp, err := client.ChatMe(ctx, "Summarize this", nil,
+//         anthropic.WithModel("claude-3-5-haiku-20241022"),
+//         anthropic.WithMaxTokens(256))
+//
+// The caller's MessageParams are never modified.
+type RequestOption func(*MessageParams)
+
+// WithModel sets the model for one call
+func WithModel(model string) RequestOption {
+    return func(p *MessageParams) {
+        p.Model = model
+    }
+}
+
+// WithMaxTokens sets the output token limit for one call
+func WithMaxTokens(n int) RequestOption {
+    return func(p *MessageParams) {
+        p.MaxTokens = n
+    }
+}
+
+// WithTemperature sets the sampling temperature for one call
+func WithTemperature(t float64) RequestOption {
+    return func(p *MessageParams) {
+        p.Temperature = Float(t)
+    }
+}
+
+// WithTools replaces the tools offered for one call
+func WithTools(tools ...Tool) RequestOption {
+    return func(p *MessageParams) {
+        p.Tools = tools
+    }
+}
+
+// WithSystem sets the system prompt for one call, taking precedence over the
+// session and client prompts
+func WithSystem(prompt string) RequestOption {
+    return func(p *MessageParams) {
+        p.System = prompt
+    }
+}
+
+// WithTimeout bounds one call, including every request it makes, on top of
+// any deadline already on its context
+func WithTimeout(d time.Duration) RequestOption {
+    return func(p *MessageParams) {
+        p.Timeout = d
+    }
+}
+
+// callContext applies the call's timeout and priority, if any, to ctx
+func callContext(ctx context.Context, params *MessageParams) (context.Context, context.CancelFunc) {
+    if params.Priority != PriorityInteractive {
+        ctx = withPriority(ctx, params.Priority)
+    }
+    if params.Timeout > 0 {
+        return context.WithTimeout(ctx, params.Timeout)
+    }
+    return ctx, func() {}
+}
+
+// resolveParams returns the parameters for a call: params merged over the
+// client defaults (see MessageParams.Merge), with opts applied. Neither params
+// nor the defaults are modified, and callers must not modify the result. The
+// default System is left out, since it is a client-level prompt that session
+// prompts outrank; renderSystemPrompt supplies it.
+func (c *AnthropicClient) resolveParams(params *MessageParams, opts []RequestOption) *MessageParams {
+    if params == nil && len(opts) == 0 && c.defaultParams.System == "" {
+        return &c.defaultParams
+    }
+    resolved := c.defaultParams.Merge(params)
+    if params == nil || params.System == "" {
+        resolved.System = ""
+    }
+    for _, opt := range opts {
+        if opt != nil {
+            opt(&resolved)
+        }
+    }
+    return &resolved
+}
@@ -0,0 +1,127 @@
+package anthropic
+
+import (
+    "fmt"
+    "strings"
+)
+
+// ConversationError lists the ways a message history breaks the API's
+// ordering rules
+type ConversationError struct {
+    Problems []string
+}
+
+func (e *ConversationError) Error() string {
+    return "invalid conversation: " + strings.Join(e.Problems, "; ")
+}
+
+func (e *ConversationError) Is(target error) bool {
+    return target == ErrInvalidRequest
+}
+
+// ValidateConversation checks that messages start with a user turn, that
+// user and assistant turns alternate, and that every tool_use is answered by
+// a tool_result in the next message (and every tool_result answers one).
+// A final assistant turn is allowed as a prefill. It returns a
+// *ConversationError listing every problem.
+func ValidateConversation(messages []Message) error {
+    var problems []string
+    if len(messages) > 0 && messages[0].Role != RoleUser {
+        problems = append(problems, "conversation must start with a user message")
+    }
+
+    for i, msg := range messages {
+        if i > 0 && msg.Role == messages[i-1].Role {
+            problems = append(problems, fmt.Sprintf("messages %d and %d are both from the %s", i-1, i, msg.Role))
+        }
+
+        var pending map[string]bool
+        if i > 0 && messages[i-1].Role == RoleAssistant {
+            pending = toolUseIDs(messages[i-1].Content)
+        }
+        for _, content := range msg.Content {
+            if content.Type != ContentTypeToolResult {
+                continue
+            }
+            if msg.Role != RoleUser || !pending[content.ToolUseID] {
+                problems = append(problems, fmt.Sprintf("message %d has a tool_result for unknown tool_use %s", i, content.ToolUseID))
+            }
+            delete(pending, content.ToolUseID)
+        }
+        if msg.Role == RoleUser && len(pending) > 0 {
+            for _, content := range messages[i-1].Content {
+                if content.Type == ContentTypeToolUse && pending[content.ID] {
+                    problems = append(problems, fmt.Sprintf("tool_use %s in message %d has no tool_result in message %d", content.ID, i-1, i))
+                }
+            }
+        }
+    }
+
+    if len(problems) > 0 {
+        return &ConversationError{Problems: problems}
+    }
+    return nil
+}
+
+// RepairConversation returns a copy of messages with consecutive messages
+// from the same role merged into one, and tool_result blocks moved to the
+// front of each user message as the API requires. Messages with no content
+// are dropped. The input is not modified.
+func RepairConversation(messages []Message) []Message {
+    repaired := make([]Message, 0, len(messages))
+    for _, msg := range messages {
+        if len(msg.Content) == 0 {
+            continue
+        }
+        n := len(repaired)
+        if n > 0 && repaired[n-1].Role == msg.Role {
+            repaired[n-1].Content = append(repaired[n-1].Content, msg.Content...)
+            continue
+        }
+        repaired = append(repaired, Message{Role: msg.Role, Content: append([]MessageContent{}, msg.Content...)})
+    }
+
+    for i := range repaired {
+        if repaired[i].Role == RoleUser {
+            repaired[i].Content = toolResultsFirst(repaired[i].Content)
+        }
+    }
+    return repaired
+}
+
+// WithConversationRepair runs RepairConversation on the messages of every
+// request, and rejects requests that still break the ordering rules with a
+// *ConversationError instead of sending them. The session history itself
+// is left as recorded.
+func WithConversationRepair() ClientOption {
+    return func(c *AnthropicClient) {
+        c.repairConversation = true
+    }
+}
+
+func toolUseIDs(contents []MessageContent) map[string]bool {
+    ids := make(map[string]bool)
+    for _, content := range contents {
+        if content.Type == ContentTypeToolUse {
+            ids[content.ID] = true
+        }
+    }
+    return ids
+}
+
+// toolResultsFirst reorders contents so tool_result blocks come first,
+// keeping the relative order of the rest
+func toolResultsFirst(contents []MessageContent) []MessageContent {
+    ordered := make([]MessageContent, 0, len(contents))
+    for _, content := range contents {
+        if content.Type == ContentTypeToolResult {
+            ordered = append(ordered, content)
+        }
+    }
+    for _, content := range contents {
+        if content.Type != ContentTypeToolResult {
+            ordered = append(ordered, content)
+        }
+    }
+    return ordered
+}
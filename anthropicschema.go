@@ -0,0 +1,255 @@
+package anthropic
+
+import (
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "strings"
+    "time"
+)
+
+var (
+    timeType       = reflect.TypeOf(time.Time{})
+    rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// base64Note describes []byte fields, which encoding/json sends as base64 strings
+const base64Note = "base64-encoded bytes"
+
+// NewToolFromStruct builds a Tool whose InputSchema is derived from the fields of
+// struct type T, so the schema cannot drift from the type the handler unmarshals.
+//
+// Field names follow `json` tags; fields tagged `json:"-"` and unexported fields
+// are skipped. Fields are required unless they are pointers, marked omitempty, or
+// tagged `jsonschema:"optional"`. The `jsonschema` tag also accepts `required`,
+// `description=...` and repeated `enum=...` entries, and descriptions containing
+// commas can be given in a separate `jsonschema_description` tag. []byte fields
+// are described as base64 strings, matching how encoding/json encodes them:
+//
+//     type WeatherInput struct {
+//         Location string `json:"location" jsonschema:"description=City or region"`
+//         Unit     string `json:"unit,omitempty" jsonschema:"enum=celsius,enum=fahrenheit"`
+//     }
+//
+//     tool, err := anthropic.NewToolFromStruct[WeatherInput]("get_weather", "Gets current weather")
+func NewToolFromStruct[T any](name, description string) (Tool, error) {
+    schema, err := inputSchemaFor(reflect.TypeOf((*T)(nil)).Elem())
+    if err != nil {
+        return Tool{}, fmt.Errorf("cannot build schema for tool %s: %w", name, err)
+    }
+
+    tool := Tool{
+        Name:        name,
+        Description: description,
+        InputSchema: schema,
+    }
+    if err := validateToolDefinition(tool); err != nil {
+        return Tool{}, err
+    }
+    return tool, nil
+}
+
+// inputSchemaFor reflects over a struct type to produce a top-level object schema
+func inputSchemaFor(t reflect.Type) (InputSchema, error) {
+    for t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    if t.Kind() != reflect.Struct {
+        return InputSchema{}, fmt.Errorf("input type must be a struct, got %s", t.Kind())
+    }
+
+    obj, err := propertyForType(t, map[reflect.Type]bool{})
+    if err != nil {
+        return InputSchema{}, err
+    }
+    return InputSchema{
+        Type:       "object",
+        Properties: obj.Properties,
+        Required:   obj.Required,
+    }, nil
+}
+
+// propertyForType maps a Go type onto a JSON Schema property. The seen set
+// guards against infinitely recursive struct types.
+func propertyForType(t reflect.Type, seen map[reflect.Type]bool) (Property, error) {
+    for t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+
+    switch {
+    case t == timeType:
+        return Property{Type: "string", Description: "RFC 3339 timestamp"}, nil
+    case t == rawMessageType:
+        return Property{}, nil
+    }
+
+    switch t.Kind() {
+    case reflect.String:
+        return Property{Type: "string"}, nil
+    case reflect.Bool:
+        return Property{Type: "boolean"}, nil
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+        reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        return Property{Type: "integer"}, nil
+    case reflect.Float32, reflect.Float64:
+        return Property{Type: "number"}, nil
+    case reflect.Slice, reflect.Array:
+        if isByteSlice(t) {
+            return Property{Type: "string", Description: base64Note}, nil
+        }
+        items, err := propertyForType(t.Elem(), seen)
+        if err != nil {
+            return Property{}, err
+        }
+        return Property{Type: "array", Items: &items}, nil
+    case reflect.Map:
+        if t.Key().Kind() != reflect.String {
+            return Property{}, fmt.Errorf("map keys must be strings, got %s", t.Key().Kind())
+        }
+        return Property{Type: "object"}, nil
+    case reflect.Interface:
+        return Property{}, nil
+    case reflect.Struct:
+        if seen[t] {
+            return Property{}, fmt.Errorf("recursive type %s is not supported", t)
+        }
+        seen[t] = true
+        defer delete(seen, t)
+
+        obj := Property{Type: "object", Properties: map[string]Property{}}
+        if err := addStructFields(&obj, t, seen, false); err != nil {
+            return Property{}, err
+        }
+        return obj, nil
+    default:
+        return Property{}, fmt.Errorf("unsupported field type %s", t)
+    }
+}
+
+// addStructFields adds each exported field of t to obj, flattening embedded
+// structs. Fields promoted through an embedded pointer may be absent, so
+// optional marks them all as not required.
+func addStructFields(obj *Property, t reflect.Type, seen map[reflect.Type]bool, optional bool) error {
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        // Like encoding/json, the exported fields of an embedded struct are
+        // promoted even when its type is unexported
+        if !field.IsExported() && !(field.Anonymous && derefType(field.Type).Kind() == reflect.Struct) {
+            continue
+        }
+
+        name, omitEmpty, skip := jsonFieldName(field)
+        if skip {
+            continue
+        }
+
+        // Embedded structs without an explicit json name are flattened like encoding/json does
+        if field.Anonymous && field.Tag.Get("json") == "" {
+            if embedded := derefType(field.Type); embedded.Kind() == reflect.Struct {
+                if seen[embedded] {
+                    return fmt.Errorf("recursive type %s is not supported", embedded)
+                }
+                seen[embedded] = true
+                err := addStructFields(obj, embedded, seen, optional || field.Type.Kind() == reflect.Ptr)
+                delete(seen, embedded)
+                if err != nil {
+                    return err
+                }
+                continue
+            }
+        }
+
+        prop, err := propertyForType(field.Type, seen)
+        if err != nil {
+            return fmt.Errorf("field %s: %w", field.Name, err)
+        }
+
+        required := !omitEmpty && field.Type.Kind() != reflect.Ptr
+        for _, opt := range splitTagOptions(field.Tag.Get("jsonschema")) {
+            key, value, _ := strings.Cut(opt, "=")
+            switch key {
+            case "required":
+                required = true
+            case "optional":
+                required = false
+            case "description":
+                prop.Description = describeField(field.Type, value)
+            case "enum":
+                prop.Enum = append(prop.Enum, value)
+            }
+        }
+        if desc := field.Tag.Get("jsonschema_description"); desc != "" {
+            prop.Description = describeField(field.Type, desc)
+        }
+
+        obj.Properties[name] = prop
+        if required && !optional {
+            obj.Required = append(obj.Required, name)
+        }
+    }
+    return nil
+}
+
+// derefType returns the type t points to, through any number of pointers
+func derefType(t reflect.Type) reflect.Type {
+    for t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    return t
+}
+
+// isByteSlice reports whether t is a []byte, which encoding/json encodes as a
+// base64 string rather than an array
+func isByteSlice(t reflect.Type) bool {
+    return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// describeField returns a field's tagged description, keeping the base64
+// note for []byte fields so Claude still knows how to encode them
+func describeField(t reflect.Type, desc string) string {
+    if isByteSlice(derefType(t)) {
+        return desc + " (" + base64Note + ")"
+    }
+    return desc
+}
+
+// jsonFieldName applies encoding/json naming rules to a struct field
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+    tag := field.Tag.Get("json")
+    if tag == "-" {
+        return "", false, true
+    }
+    name, opts, _ := strings.Cut(tag, ",")
+    if name == "" {
+        name = field.Name
+    }
+    for _, opt := range strings.Split(opts, ",") {
+        if opt == "omitempty" || opt == "omitzero" {
+            omitEmpty = true
+        }
+    }
+    return name, omitEmpty, false
+}
+
+// splitTagOptions splits a jsonschema tag on commas, honoring escaped commas
+// (written `\\,` inside the struct tag literal)
+func splitTagOptions(tag string) []string {
+    if tag == "" {
+        return nil
+    }
+    var opts []string
+    var current strings.Builder
+    for i := 0; i < len(tag); i++ {
+        switch {
+        case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+            current.WriteByte(',')
+            i++
+        case tag[i] == ',':
+            opts = append(opts, current.String())
+            current.Reset()
+        default:
+            current.WriteByte(tag[i])
+        }
+    }
+    return append(opts, current.String())
+}
@@ -0,0 +1,143 @@
+package anthropic
+
+import (
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+    "time"
+)
+
+type schemaAddress struct {
+    City    string `json:"city"`
+    Country string `json:"country,omitempty"`
+}
+
+type schemaAudit struct {
+    CreatedBy string `json:"created_by"`
+}
+
+type schemaInput struct {
+    schemaAddress
+    *schemaAudit
+
+    Name     string          `json:"name" jsonschema:"description=Who to greet"`
+    Age      int             `json:"age,omitempty"`
+    Nickname *string         `json:"nickname"`
+    Unit     string          `json:"unit" jsonschema:"enum=celsius,enum=fahrenheit"`
+    Tags     []string        `json:"tags"`
+    Avatar   []byte          `json:"avatar"`
+    Photo    []byte          `json:"photo,omitempty" jsonschema_description:"Profile photo"`
+    Home     schemaAddress   `json:"home"`
+    Seen     time.Time       `json:"seen"`
+    Extra    map[string]int  `json:"extra,omitempty"`
+    Skipped  string          `json:"-"`
+    hidden   string
+}
+
+type schemaNode struct {
+    *schemaNode
+    X int `json:"x"`
+}
+
+type schemaTree struct {
+    Value    int          `json:"value"`
+    Children []schemaTree `json:"children"`
+}
+
+type schemaParent struct {
+    Child *schemaParent `json:"child"`
+}
+
+type schemaBadMap struct {
+    Lookup map[int]string `json:"lookup"`
+}
+
+func TestInputSchemaFor(t *testing.T) {
+    schema, err := inputSchemaFor(reflect.TypeOf(schemaInput{}))
+    if err != nil {
+        t.Fatalf("inputSchemaFor: %v", err)
+    }
+
+    props := []struct {
+        name string
+        want Property
+    }{
+        // Embedded structs are flattened
+        {"city", Property{Type: "string"}},
+        {"country", Property{Type: "string"}},
+        {"created_by", Property{Type: "string"}},
+        {"name", Property{Type: "string", Description: "Who to greet"}},
+        {"age", Property{Type: "integer"}},
+        {"nickname", Property{Type: "string"}},
+        {"unit", Property{Type: "string", Enum: []string{"celsius", "fahrenheit"}}},
+        {"tags", Property{Type: "array", Items: &Property{Type: "string"}}},
+        // []byte is encoded as a base64 string, not an array of integers
+        {"avatar", Property{Type: "string", Description: base64Note}},
+        {"photo", Property{Type: "string", Description: "Profile photo (" + base64Note + ")"}},
+        {"home", Property{
+            Type:       "object",
+            Properties: map[string]Property{"city": {Type: "string"}, "country": {Type: "string"}},
+            Required:   []string{"city"},
+        }},
+        {"seen", Property{Type: "string", Description: "RFC 3339 timestamp"}},
+        {"extra", Property{Type: "object"}},
+    }
+    for _, tt := range props {
+        got, ok := schema.Properties[tt.name]
+        if !ok {
+            t.Errorf("property %s missing", tt.name)
+            continue
+        }
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("property %s = %+v, want %+v", tt.name, got, tt.want)
+        }
+    }
+    if len(schema.Properties) != len(props) {
+        t.Errorf("got %d properties, want %d: %v", len(schema.Properties), len(props), schema.Properties)
+    }
+
+    // Pointers, omitempty fields and fields promoted through *schemaAudit are optional
+    required := append([]string(nil), schema.Required...)
+    sort.Strings(required)
+    want := []string{"avatar", "city", "home", "name", "seen", "tags", "unit"}
+    if !reflect.DeepEqual(required, want) {
+        t.Errorf("required = %v, want %v", required, want)
+    }
+}
+
+func TestInputSchemaForErrors(t *testing.T) {
+    tests := []struct {
+        name string
+        typ  reflect.Type
+        want string
+    }{
+        {"embedded recursion", reflect.TypeOf(schemaNode{}), "recursive type"},
+        {"slice recursion", reflect.TypeOf(schemaTree{}), "recursive type"},
+        {"pointer recursion", reflect.TypeOf(schemaParent{}), "recursive type"},
+        {"non-string map keys", reflect.TypeOf(schemaBadMap{}), "map keys must be strings"},
+        {"not a struct", reflect.TypeOf(""), "input type must be a struct"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := inputSchemaFor(tt.typ)
+            if err == nil || !strings.Contains(err.Error(), tt.want) {
+                t.Fatalf("inputSchemaFor(%s) = %v, want an error containing %q", tt.typ, err, tt.want)
+            }
+        })
+    }
+}
+
+func TestNewToolFromStruct(t *testing.T) {
+    tool, err := NewToolFromStruct[schemaInput]("greet", "Greets someone")
+    if err != nil {
+        t.Fatalf("NewToolFromStruct: %v", err)
+    }
+    if tool.Name != "greet" || tool.Description != "Greets someone" || tool.InputSchema.Type != "object" {
+        t.Errorf("tool = %+v", tool)
+    }
+
+    if _, err := NewToolFromStruct[schemaNode]("node", "Recursive"); err == nil {
+        t.Error("NewToolFromStruct accepted a recursive type")
+    }
+}
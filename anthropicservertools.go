@@ -0,0 +1,177 @@
+package anthropic
+
+import (
+    "encoding/json"
+)
+
+// Anthropic-defined tool types. These tools have a fixed schema, so they are
+// sent as {type, name, ...} without description or input_schema.
+const (
+    ToolTypeCustom        = "custom"
+    ToolTypeWebSearch     = "web_search_20250305"
+    ToolTypeTextEditor    = "text_editor_20250124"
+    ToolTypeBash          = "bash_20250124"
+    ToolTypeCodeExecution = "code_execution_20250522"
+)
+
+// Content types for server-side tool execution
+const (
+    ContentTypeServerToolUse                ContentType = "server_tool_use"
+    ContentTypeWebSearchToolResult          ContentType = "web_search_tool_result"
+    ContentTypeWebSearchResult              ContentType = "web_search_result"
+    ContentTypeWebSearchToolResultError     ContentType = "web_search_tool_result_error"
+    ContentTypeCodeExecutionToolResult      ContentType = "code_execution_tool_result"
+    ContentTypeCodeExecutionResult          ContentType = "code_execution_result"
+    ContentTypeCodeExecutionOutput          ContentType = "code_execution_output"
+    ContentTypeCodeExecutionToolResultError ContentType = "code_execution_tool_result_error"
+
+    CitationTypeWebSearchResultLocation = "web_search_result_location"
+
+    // StopReasonPauseTurn indicates a long-running server tool turn was paused;
+    // sending the conversation back as-is lets Claude continue it.
+    StopReasonPauseTurn StopReason = "pause_turn"
+)
+
+// maxPauseContinuations bounds how often a paused turn is resumed automatically
+const maxPauseContinuations = 5
+
+// UserLocation localizes web search results
+type UserLocation struct {
+    Type     string `json:"type"` // Always "approximate"
+    City     string `json:"city,omitempty"`
+    Region   string `json:"region,omitempty"`
+    Country  string `json:"country,omitempty"` // ISO 3166-1 alpha-2 code
+    Timezone string `json:"timezone,omitempty"` // IANA time zone, e.g. "America/Chicago"
+}
+
+// WebSearchOptions configures the server-side web search tool
+type WebSearchOptions struct {
+    MaxUses        int      // Maximum searches per request; 0 means no limit
+    AllowedDomains []string // Only return results from these domains
+    BlockedDomains []string // Never return results from these domains
+    UserLocation   *UserLocation
+}
+
+// Citation points at the source backing part of a text block
+type Citation struct {
+    Type           string `json:"type"`
+    CitedText      string `json:"cited_text,omitempty"`
+    URL            string `json:"url,omitempty"`
+    Title          string `json:"title,omitempty"`
+    EncryptedIndex string `json:"encrypted_index,omitempty"`
+}
+
+// ServerToolUsage counts server-side tool invocations billed per use
+type ServerToolUsage struct {
+    WebSearchRequests int `json:"web_search_requests,omitempty"`
+}
+
+// WebSearchTool returns the server-side web search tool. Searches run on
+// Anthropic's side; results arrive as web_search_tool_result blocks and the
+// answer text carries citations.
+func WebSearchTool(opts WebSearchOptions) Tool {
+    if opts.UserLocation != nil && opts.UserLocation.Type == "" {
+        location := *opts.UserLocation
+        location.Type = "approximate"
+        opts.UserLocation = &location
+    }
+    return Tool{
+        Type:           ToolTypeWebSearch,
+        Name:           "web_search",
+        MaxUses:        opts.MaxUses,
+        AllowedDomains: opts.AllowedDomains,
+        BlockedDomains: opts.BlockedDomains,
+        UserLocation:   opts.UserLocation,
+    }
+}
+
+// TextEditorTool returns the Anthropic-defined text editor tool. Unlike web
+// search it runs client-side: Claude sends tool_use calls with a "command"
+// (view, create, str_replace, insert, undo_edit) that a local handler such as
+// toolcatalog.NewTextEditor must execute.
+func TextEditorTool() Tool {
+    return Tool{Type: ToolTypeTextEditor, Name: "str_replace_editor"}
+}
+
+// BashTool returns the Anthropic-defined bash tool. It runs client-side: Claude
+// sends tool_use calls with a "command" (or "restart": true) for a local
+// handler such as toolcatalog.NewBash to execute.
+func BashTool() Tool {
+    return Tool{Type: ToolTypeBash, Name: "bash"}
+}
+
+// CodeExecutionTool returns the server-side code execution tool. Claude runs
+// Python in an Anthropic-hosted sandbox; the client adds the required beta
+// header automatically. Outcomes are available via CodeExecutionResults.
+func CodeExecutionTool() Tool {
+    return Tool{Type: ToolTypeCodeExecution, Name: "code_execution"}
+}
+
+// CodeExecutionResult is the outcome of one server-side code execution
+type CodeExecutionResult struct {
+    ToolUseID  string
+    Stdout     string
+    Stderr     string
+    ReturnCode int
+    FileIDs    []string // Files the code created, retrievable with the Files API
+    ErrorCode  string   // Set when the execution itself failed (e.g. "unavailable")
+}
+
+// CodeExecutionResults returns the outcome of every code execution in the response
+func (r *AnthropicResponse) CodeExecutionResults() []CodeExecutionResult {
+    var results []CodeExecutionResult
+    for _, block := range BlocksOf[CodeExecutionToolResultBlock](r.Blocks()) {
+        results = append(results, block.CodeExecutionResult)
+    }
+    return results
+}
+
+// hasBuiltinType reports whether the tool is an Anthropic-defined tool type,
+// whose schema is implied by its type rather than sent with the request
+func (t Tool) hasBuiltinType() bool {
+    return t.Type != "" && t.Type != ToolTypeCustom
+}
+
+// toolAlias has Tool's fields without its JSON methods
+type toolAlias Tool
+
+// MarshalJSON omits description and input_schema for Anthropic-defined tools
+func (t Tool) MarshalJSON() ([]byte, error) {
+    if !t.hasBuiltinType() {
+        return json.Marshal(toolAlias(t))
+    }
+    return json.Marshal(struct {
+        toolAlias
+        Description string       `json:"description,omitempty"`
+        InputSchema *InputSchema `json:"input_schema,omitempty"`
+    }{
+        toolAlias: toolAlias(t),
+    })
+}
+
+// Citations returns every citation attached to the response's text blocks
+func (r *AnthropicResponse) Citations() []Citation {
+    var citations []Citation
+    for _, block := range r.Content {
+        citations = append(citations, block.Citations...)
+    }
+    return citations
+}
+
+// WebSearchResults returns the individual search results from every
+// web_search_tool_result block in the response. Failed searches are skipped;
+// their error code is on the result block's ContentBlocks.
+func (r *AnthropicResponse) WebSearchResults() []MessageContent {
+    var results []MessageContent
+    for _, block := range r.Content {
+        if block.Type != ContentTypeWebSearchToolResult {
+            continue
+        }
+        for _, result := range block.ContentBlocks {
+            if result.Type == ContentTypeWebSearchResult {
+                results = append(results, result)
+            }
+        }
+    }
+    return results
+}
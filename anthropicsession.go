@@ -0,0 +1,390 @@
+package anthropic
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "sync"
+)
+
+// SessionOption defines functions that can modify session configuration
+type SessionOption func(*Session)
+
+// Session holds one conversation (history plus optional system prompt) on top of a
+// shared AnthropicClient, so a multi-tenant server can serve many personas and
+// users from a single client instance. Session methods are safe for concurrent
+// use; turns within one session are serialized.
+type Session struct {
+    client       *AnthropicClient
+    mu           sync.Mutex
+    id           string
+    conversation []Message
+    systemPrompt string    // Overrides the client system prompt when set
+    userName     string    // Rendered into system prompt templates
+    promptVars   map[string]string
+    title        string    // Cached result of GenerateTitle
+    usage        usageTracker
+    budget       Budget
+    stream       StreamHandler // Receives the tool loop's turns during ChatStreamWithTools
+
+    // Context window tracking, from the last response to the history
+    contextModel  string
+    contextTokens int
+    contextLen    int  // Messages covered by contextTokens
+    contextWarned bool // Warning fired and usage still above the threshold
+}
+
+// WithSessionID assigns a caller-chosen identifier to the session
+func WithSessionID(id string) SessionOption {
+    return func(s *Session) {
+        if id != "" {
+            s.id = id
+        }
+    }
+}
+
+// WithSessionSystemPrompt sets a system prompt for this session only
+func WithSessionSystemPrompt(prompt string) SessionOption {
+    return func(s *Session) {
+        s.systemPrompt = prompt
+    }
+}
+
+// NewSession creates an independent conversation that shares the client's
+// configuration and HTTP transport.
+func (c *AnthropicClient) NewSession(opts ...SessionOption) *Session {
+    s := &Session{
+        client: c,
+        id:     newSessionID(),
+    }
+    for _, opt := range opts {
+        opt(s)
+    }
+    logMessage("Created session %s", s.id)
+    return s
+}
+
+// ID returns the session identifier
+func (s *Session) ID() string {
+    return s.id
+}
+
+// UpdateSystemPrompt overrides the system prompt for this session
+func (s *Session) UpdateSystemPrompt(prompt string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    logMessage("Updating system prompt for session %s", s.id)
+    s.systemPrompt = prompt
+}
+
+// GetSystemPrompt returns the system prompt this session sends, falling back to
+// the client's system prompt when the session has none of its own.
+func (s *Session) GetSystemPrompt() string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    return s.resolveSystemPrompt(nil)
+}
+
+// GetConversation returns a copy of the session's conversation history
+func (s *Session) GetConversation() []Message {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    history := make([]Message, len(s.conversation))
+    copy(history, s.conversation)
+    return history
+}
+
+// SetConversation replaces the session's history with a copy of messages,
+// e.g. one saved earlier from GetConversation. Histories that break the
+// ordering rules are rejected with a *ConversationError.
+func (s *Session) SetConversation(messages []Message) error {
+    if err := ValidateConversation(messages); err != nil {
+        return err
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    logMessage("Replacing conversation for session %s (%d messages)", s.id, len(messages))
+    s.conversation = append([]Message(nil), messages...)
+    s.title = ""
+    s.contextTokens, s.contextLen, s.contextWarned = 0, 0, false
+    return nil
+}
+
+// Reset clears the session's conversation history
+func (s *Session) Reset() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    logMessage("Resetting conversation for session %s", s.id)
+    s.conversation = nil
+    s.title = ""
+    s.contextTokens, s.contextLen, s.contextWarned = 0, 0, false
+}
+
+// ChatMe sends a user message within this session and records the reply in its history
+func (s *Session) ChatMe(ctx context.Context, message string, params *MessageParams, opts ...RequestOption) (*AnthropicResponse, error) {
+    params = s.client.resolveParams(params, opts)
+    return s.client.idempotent(ctx, params.IdempotencyKey, func() (*AnthropicResponse, error) {
+        return s.chatMe(ctx, message, params)
+    })
+}
+
+func (s *Session) chatMe(ctx context.Context, message string, params *MessageParams) (*AnthropicResponse, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    ctx, cancel := callContext(ctx, params)
+    defer cancel()
+
+    logMessage("Starting chat interaction with message: %s", message)
+    message, err := s.client.checkInput(ctx, message)
+    if err != nil {
+        return nil, err
+    }
+    history := s.guardHistory()
+
+    content := []MessageContent{{
+        Type: ContentTypeText,
+        Text: message,
+    }}
+
+    // Add user message to conversation history
+    s.addMessageToConversation(RoleUser, content)
+    logMessage("Added user message to conversation")
+    logJSON("Current conversation state", s.conversation)
+
+    s.trimConversationHistory()
+
+    resp, err := s.completeConversation(ctx, params)
+    s.restoreOnBlock(history, err)
+    return resp, err
+}
+
+// Regenerate drops the last assistant turn from the conversation history and
+// requests a fresh response to the preceding user message. Different sampling
+// parameters can be supplied through params to vary the new answer.
+func (s *Session) Regenerate(ctx context.Context, params *MessageParams, opts ...RequestOption) (*AnthropicResponse, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    params = s.client.resolveParams(params, opts)
+    ctx, cancel := callContext(ctx, params)
+    defer cancel()
+
+    logMessage("Regenerating last assistant response")
+    history := s.guardHistory()
+
+    if !s.dropLastAssistantTurn() {
+        logMessage("No assistant response available to regenerate")
+        return nil, fmt.Errorf("no assistant response to regenerate")
+    }
+    logJSON("Conversation state after dropping assistant turn", s.conversation)
+
+    resp, err := s.completeConversation(ctx, params)
+    s.restoreOnBlock(history, err)
+    return resp, err
+}
+
+// EditAndRetry replaces the text of the Nth user turn (zero-based, counting only
+// messages the user typed, not tool results), discards every message that
+// followed it, and requests a new assistant response.
+func (s *Session) EditAndRetry(ctx context.Context, index int, message string, params *MessageParams, opts ...RequestOption) (*AnthropicResponse, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    params = s.client.resolveParams(params, opts)
+    ctx, cancel := callContext(ctx, params)
+    defer cancel()
+
+    logMessage("Editing user message %d and retrying", index)
+
+    pos := s.userTurnPosition(index)
+    if pos < 0 {
+        logMessage("User message %d not found in conversation", index)
+        return nil, fmt.Errorf("user message %d not found in conversation", index)
+    }
+    message, err := s.client.checkInput(ctx, message)
+    if err != nil {
+        return nil, err
+    }
+    history := s.guardHistory()
+
+    s.conversation = s.conversation[:pos]
+    s.addMessageToConversation(RoleUser, []MessageContent{{
+        Type: ContentTypeText,
+        Text: message,
+    }})
+    logJSON("Conversation state after edit", s.conversation)
+
+    resp, err := s.completeConversation(ctx, params)
+    s.restoreOnBlock(history, err)
+    return resp, err
+}
+
+// completeConversation sends the current conversation history to the API and
+// records the assistant's reply in the history.
+func (s *Session) completeConversation(ctx context.Context, params *MessageParams) (*AnthropicResponse, error) {
+    // Prepare request with complete message history
+    reqBody := Request{
+        Model:         params.Model,
+        System:        s.resolveSystemPrompt(params),
+        SystemBlocks:  params.SystemBlocks,
+        Messages:      s.conversation,
+        MaxTokens:     params.MaxTokens,
+        Temperature:   params.Temperature,
+        TopP:          params.TopP,
+        TopK:          params.TopK,
+        StopSequences: params.StopSequences,
+        Tools:         params.Tools,
+        ToolChoice:    params.ToolChoice,
+        MCPServers:    normalizeMCPServers(params.MCPServers),
+    }
+
+    // Send request and handle any errors
+    response, err := s.sendHistory(ctx, reqBody)
+    if err != nil {
+        logMessage("Chat request failed: %v", err)
+        return nil, err
+    }
+
+    // Process and store assistant's response
+    if len(response.Content) > 0 {
+        logMessage("Adding assistant response to conversation")
+        s.addMessageToConversation(RoleAssistant, response.Content)
+        s.trimConversationHistory()
+        logJSON("Updated conversation state", s.conversation)
+    }
+
+    // Resume turns paused by long-running server tools, returning the whole turn
+    var earlier []MessageContent
+    usage := response.Usage
+    for i := 0; response.StopReason == StopReasonPauseTurn && i < maxPauseContinuations; i++ {
+        logMessage("Turn paused by server tool; continuing (%d/%d)", i+1, maxPauseContinuations)
+        earlier = append(earlier, response.Content...)
+        reqBody.Messages = s.conversation
+        response, err = s.client.sendRequest(ctx, s, reqBody)
+        if err != nil {
+            logMessage("Chat continuation failed: %v", err)
+            return nil, err
+        }
+        if len(response.Content) > 0 {
+            s.addMessageToConversation(RoleAssistant, response.Content)
+            s.trimConversationHistory()
+        }
+        usage.Add(response.Usage)
+    }
+    if len(earlier) > 0 {
+        response.Content = append(earlier, response.Content...)
+        response.Usage = usage
+    }
+
+    return s.continueTruncated(ctx, reqBody, response, s.client.continuationLimit(params))
+}
+
+// resolveSystemPrompt applies the system prompt hierarchy: params > session > client
+func (s *Session) resolveSystemPrompt(params *MessageParams) string {
+    if params != nil && params.System != "" {
+        return params.System
+    }
+    if s.systemPrompt != "" {
+        return s.systemPrompt
+    }
+    return s.client.renderSystemPrompt(s, params)
+}
+
+// Conversation management methods with logging. Callers must hold s.mu.
+
+func (s *Session) addMessageToConversation(role Role, content []MessageContent) {
+    logMessage("Adding message to conversation (role: %s)", role)
+    msg := Message{
+        Role:    role,
+        Content: content,
+    }
+    s.conversation = append(s.conversation, msg)
+    s.client.fireMessageAppended(msg)
+}
+
+// guardHistory copies the history so restoreOnBlock can put it back if an
+// output guardrail blocks the call. It is nil without output guardrails.
+func (s *Session) guardHistory() []Message {
+    if len(s.client.outputGuardrails) == 0 {
+        return nil
+    }
+    return append([]Message{}, s.conversation...)
+}
+
+// restoreOnBlock rolls the history back when err is a guardrail block
+func (s *Session) restoreOnBlock(history []Message, err error) {
+    if len(s.client.outputGuardrails) > 0 && errors.Is(err, ErrGuardrailBlocked) {
+        logMessage("Response blocked by guardrail; restoring conversation")
+        s.conversation = history
+    }
+}
+
+func (s *Session) trimConversationHistory() {
+    maxConvLength := s.client.maxConvLength
+    if maxConvLength > 0 && len(s.conversation) > maxConvLength {
+        logMessage("Trimming conversation to max length: %d", maxConvLength)
+        s.conversation = s.conversation[len(s.conversation)-maxConvLength:]
+    }
+}
+
+// dropLastAssistantTurn removes the trailing assistant message(s) from the
+// conversation. It reports false when the conversation does not end with an
+// assistant turn.
+func (s *Session) dropLastAssistantTurn() bool {
+    end := len(s.conversation)
+    for end > 0 && s.conversation[end-1].Role == RoleAssistant {
+        end--
+    }
+    if end == len(s.conversation) || end == 0 {
+        return false
+    }
+    s.conversation = s.conversation[:end]
+    return true
+}
+
+// userTurnPosition maps a zero-based user turn index to its position in the
+// conversation slice, skipping user messages that only carry tool results.
+// It returns -1 when no such turn exists.
+func (s *Session) userTurnPosition(index int) int {
+    if index < 0 {
+        return -1
+    }
+    count := 0
+    for i, msg := range s.conversation {
+        if msg.Role != RoleUser || isToolResultMessage(msg) {
+            continue
+        }
+        if count == index {
+            return i
+        }
+        count++
+    }
+    return -1
+}
+
+// isToolResultMessage reports whether a message consists solely of tool results
+func isToolResultMessage(msg Message) bool {
+    if len(msg.Content) == 0 {
+        return false
+    }
+    for _, content := range msg.Content {
+        if content.Type != ContentTypeToolResult {
+            return false
+        }
+    }
+    return true
+}
+
+// newSessionID generates a random identifier for sessions created without one
+func newSessionID() string {
+    buf := make([]byte, 8)
+    if _, err := rand.Read(buf); err != nil {
+        return fmt.Sprintf("session-%p", buf)
+    }
+    return hex.EncodeToString(buf)
+}
@@ -0,0 +1,95 @@
+package anthropic
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+)
+
+// ErrClientClosed is returned for requests and tool calls started after Close
+var ErrClientClosed = errors.New("client is closed")
+
+// Close stops the client accepting new requests and tool calls, waits for
+// those in progress to finish, and closes idle pooled connections. If ctx
+// ends first, Close returns its error and the remaining work carries on.
+// Conversations in the middle of a tool loop fail with ErrClientClosed at
+// their next request. Clients derived with With are closed separately; the
+// tool registry, which may be shared, is left open.
+func (c *AnthropicClient) Close(ctx context.Context) error {
+    logMessage("Closing client")
+    done := c.inflight.close()
+    select {
+    case <-done:
+    case <-ctx.Done():
+        logMessage("Client close interrupted with work still running: %v", ctx.Err())
+        return fmt.Errorf("error waiting for in-flight work: %w", ctx.Err())
+    }
+    c.httpClient.CloseIdleConnections()
+    logMessage("Client closed")
+    return nil
+}
+
+// Close closes every tenant client, returning the first error
+func (p *ClientPool) Close(ctx context.Context) error {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+
+    var firstErr error
+    for id, client := range p.tenants {
+        if err := client.Close(ctx); err != nil && firstErr == nil {
+            firstErr = fmt.Errorf("tenant %s: %w", id, err)
+        }
+    }
+    return firstErr
+}
+
+// inflightTracker counts the requests and tool calls in progress
+type inflightTracker struct {
+    mu     sync.Mutex
+    closed bool
+    active int
+    done   chan struct{} // Closed once the client is closed and idle
+}
+
+// begin registers a unit of work, failing once the client is closed
+func (t *inflightTracker) begin() error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.closed {
+        return ErrClientClosed
+    }
+    t.active++
+    return nil
+}
+
+func (t *inflightTracker) end() {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    t.active--
+    if t.closed && t.active == 0 && t.done != nil {
+        select {
+        case <-t.done:
+        default:
+            close(t.done)
+        }
+    }
+}
+
+// close refuses further work and returns a channel closed when the work in
+// progress has finished
+func (t *inflightTracker) close() <-chan struct{} {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    t.closed = true
+    if t.done == nil {
+        t.done = make(chan struct{})
+        if t.active == 0 {
+            close(t.done)
+        }
+    }
+    return t.done
+}
@@ -0,0 +1,106 @@
+package anthropic
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "io"
+    "sync"
+)
+
+// maxSSELine bounds a single line of a stream
+const maxSSELine = 16 << 20
+
+var sseDataField = []byte("data:")
+
+// sseReader splits a server-sent event stream into event payloads. Lines are
+// read in place from a pooled bufio.Reader and only the data fields are
+// copied, into a buffer reused across events, so a stream costs no
+// allocations per line and never holds more than one event in memory.
+type sseReader struct {
+    r    *bufio.Reader
+    line []byte // Lines longer than the read buffer
+    data []byte // Data of the event being read
+}
+
+var sseReaders = sync.Pool{
+    New: func() interface{} {
+        return &sseReader{r: bufio.NewReaderSize(nil, 32<<10)}
+    },
+}
+
+func newSSEReader(r io.Reader) *sseReader {
+    s := sseReaders.Get().(*sseReader)
+    s.r.Reset(r)
+    return s
+}
+
+// release returns s to the pool; s must not be used afterwards
+func (s *sseReader) release() {
+    s.r.Reset(nil)
+    if cap(s.line) > maxPooledBuffer || cap(s.data) > maxPooledBuffer {
+        return
+    }
+    s.line, s.data = s.line[:0], s.data[:0]
+    sseReaders.Put(s)
+}
+
+// next returns the data of the next event, joining multi-line data with
+// newlines. The result is only valid until the next call. Other fields and
+// comments are skipped. It returns io.EOF once the stream ends.
+func (s *sseReader) next() ([]byte, error) {
+    s.data = s.data[:0]
+    for {
+        line, err := s.readLine()
+        if err != nil {
+            if err == io.EOF && len(s.data) > 0 {
+                return s.data, nil
+            }
+            return nil, err
+        }
+        if len(line) == 0 {
+            // A blank line ends the event
+            if len(s.data) > 0 {
+                return s.data, nil
+            }
+            continue
+        }
+        if !bytes.HasPrefix(line, sseDataField) {
+            continue
+        }
+        value := line[len(sseDataField):]
+        if len(value) > 0 && value[0] == ' ' {
+            value = value[1:]
+        }
+        if len(s.data) > 0 {
+            s.data = append(s.data, '\n')
+        }
+        s.data = append(s.data, value...)
+    }
+}
+
+// readLine returns the next line without its line ending. The result is
+// only valid until the next read.
+func (s *sseReader) readLine() ([]byte, error) {
+    line, err := s.r.ReadSlice('\n')
+    if err == bufio.ErrBufferFull {
+        s.line = append(s.line[:0], line...)
+        for err == bufio.ErrBufferFull {
+            if len(s.line) > maxSSELine {
+                return nil, errors.New("stream line too long")
+            }
+            line, err = s.r.ReadSlice('\n')
+            s.line = append(s.line, line...)
+        }
+        line = s.line
+    }
+    if err == io.EOF && len(line) > 0 {
+        // A final line without a line ending
+        err = nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    line = bytes.TrimSuffix(line, []byte("\n"))
+    return bytes.TrimSuffix(line, []byte("\r")), nil
+}
@@ -0,0 +1,77 @@
+package anthropic
+
+import (
+    "bufio"
+    "bytes"
+    "fmt"
+    "io"
+    "strings"
+    "testing"
+)
+
+// recordedStream returns a streamed reply as the API sends it: the message
+// events around a couple of hundred text deltas, with pings in between
+func recordedStream() []byte {
+    var b bytes.Buffer
+    b.WriteString("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"model\":\"claude-3-5-sonnet-20241022\",\"content\":[],\"stop_reason\":null,\"usage\":{\"input_tokens\":512,\"output_tokens\":1}}}\n\n")
+    b.WriteString("event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")
+    for i := 0; i < 200; i++ {
+        if i%50 == 0 {
+            b.WriteString("event: ping\ndata: {\"type\":\"ping\"}\n\n")
+        }
+        fmt.Fprintf(&b, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"word %d of the reply \"}}\n\n", i)
+    }
+    b.WriteString("event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n")
+    b.WriteString("event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":600}}\n\n")
+    b.WriteString("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+    return b.Bytes()
+}
+
+func BenchmarkSSEReader(b *testing.B) {
+    stream := recordedStream()
+    b.SetBytes(int64(len(stream)))
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        events := newSSEReader(bytes.NewReader(stream))
+        for {
+            if _, err := events.next(); err != nil {
+                if err != io.EOF {
+                    b.Fatal(err)
+                }
+                break
+            }
+        }
+        events.release()
+    }
+}
+
+// BenchmarkSSEScanner splits the same stream with a bufio.Scanner, collecting
+// each event's data the way a line-at-a-time reader would
+func BenchmarkSSEScanner(b *testing.B) {
+    stream := recordedStream()
+    b.SetBytes(int64(len(stream)))
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        scanner := bufio.NewScanner(bytes.NewReader(stream))
+        scanner.Buffer(make([]byte, 64<<10), maxSSELine)
+        var data []string
+        for scanner.Scan() {
+            line := scanner.Text()
+            if line == "" {
+                if len(data) > 0 {
+                    _ = strings.Join(data, "\n")
+                    data = data[:0]
+                }
+                continue
+            }
+            if strings.HasPrefix(line, "data:") {
+                data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+            }
+        }
+        if err := scanner.Err(); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
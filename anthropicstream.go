@@ -0,0 +1,424 @@
+package anthropic
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "net/http"
+    "sync/atomic"
+    "time"
+)
+
+// ErrStreamStalled is returned when a stream receives no event within the
+// idle timeout (see WithStreamIdleTimeout)
+var ErrStreamStalled = errors.New("stream stalled")
+
+// Stream event types, as sent in the SSE "type" field
+const (
+    StreamMessageStart      = "message_start"
+    StreamContentBlockStart = "content_block_start"
+    StreamContentBlockDelta = "content_block_delta"
+    StreamContentBlockStop  = "content_block_stop"
+    StreamMessageDelta      = "message_delta"
+    StreamMessageStop       = "message_stop"
+    StreamPing              = "ping"
+    StreamError             = "error"
+
+    // StreamToolResult is sent by ChatStreamWithTools, not the API, after
+    // each tool call with the tool_result block in ContentBlock
+    StreamToolResult = "tool_result"
+)
+
+// StreamEvent is one server-sent event of a streaming response
+type StreamEvent struct {
+    Type         string             `json:"type"`
+    Message      *AnthropicResponse `json:"message,omitempty"`       // message_start
+    Index        int                `json:"index"`                   // Content block the event applies to
+    ContentBlock *MessageContent    `json:"content_block,omitempty"` // content_block_start
+    Delta        StreamDelta        `json:"delta"`                   // content_block_delta and message_delta
+    Usage        *Usage             `json:"usage,omitempty"`         // message_delta; output tokens so far
+    Error        *StreamErrorDetail `json:"error,omitempty"`         // error
+}
+
+// StreamDelta carries the incremental part of a delta event. Which fields are
+// set depends on Type: text_delta, input_json_delta, thinking_delta, or
+// signature_delta for content blocks; message deltas set StopReason.
+type StreamDelta struct {
+    Type         string     `json:"type,omitempty"`
+    Text         string     `json:"text,omitempty"`
+    PartialJSON  string     `json:"partial_json,omitempty"`
+    Thinking     string     `json:"thinking,omitempty"`
+    Signature    string     `json:"signature,omitempty"`
+    StopReason   StopReason `json:"stop_reason,omitempty"`
+    StopSequence string     `json:"stop_sequence,omitempty"`
+}
+
+// StreamErrorDetail describes an error reported mid-stream, e.g. overloaded_error
+type StreamErrorDetail struct {
+    Type    string `json:"type"`
+    Message string `json:"message"`
+}
+
+// StreamHandler receives each event of a stream as it arrives. It runs on
+// the calling goroutine, so a slow handler slows the stream.
+type StreamHandler func(event StreamEvent)
+
+// TextHandler adapts a function receiving text deltas into a StreamHandler,
+// for the common case of printing the answer as it is written
+func TextHandler(fn func(text string)) StreamHandler {
+    return func(event StreamEvent) {
+        if event.Type == StreamContentBlockDelta && event.Delta.Type == "text_delta" {
+            fn(event.Delta.Text)
+        }
+    }
+}
+
+// WithStreamIdleTimeout aborts a stream with ErrStreamStalled when no event
+// arrives for d, e.g. because a proxy silently dropped the connection. The
+// API sends ping events while Claude is working, so a healthy stream never
+// goes quiet for long. The timeout starts once the response headers arrive.
+func WithStreamIdleTimeout(d time.Duration) ClientOption {
+    return func(c *AnthropicClient) {
+        if d > 0 {
+            c.streamIdleTimeout = d
+        }
+    }
+}
+
+// ChatStream is ChatMe with the answer streamed to handler on the default session
+func (c *AnthropicClient) ChatStream(ctx context.Context, message string, params *MessageParams, handler StreamHandler, opts ...RequestOption) (*AnthropicResponse, error) {
+    return c.session.ChatStream(ctx, message, params, handler, opts...)
+}
+
+// ChatStream sends a user message like ChatMe, delivering the answer to
+// handler event by event as it is generated. It returns the assembled
+// response, which is recorded in the history. Output guardrails run on the
+// assembled response, after its deltas have reached handler. If ctx is
+// cancelled mid-stream, the history is left as it was before the call.
+func (s *Session) ChatStream(ctx context.Context, message string, params *MessageParams, handler StreamHandler, opts ...RequestOption) (*AnthropicResponse, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    params = s.client.resolveParams(params, opts)
+    ctx, cancel := callContext(ctx, params)
+    defer cancel()
+
+    logMessage("Starting streaming chat with message: %s", message)
+    message, err := s.client.checkInput(ctx, message)
+    if err != nil {
+        return nil, err
+    }
+    history := s.guardHistory()
+    snapshot := append([]Message{}, s.conversation...)
+
+    s.addMessageToConversation(RoleUser, []MessageContent{{
+        Type: ContentTypeText,
+        Text: message,
+    }})
+    s.trimConversationHistory()
+
+    reqBody := Request{
+        Model:         params.Model,
+        System:        s.resolveSystemPrompt(params),
+        SystemBlocks:  params.SystemBlocks,
+        Messages:      s.conversation,
+        MaxTokens:     params.MaxTokens,
+        Temperature:   params.Temperature,
+        TopP:          params.TopP,
+        TopK:          params.TopK,
+        StopSequences: params.StopSequences,
+        Tools:         params.Tools,
+        ToolChoice:    params.ToolChoice,
+        MCPServers:    normalizeMCPServers(params.MCPServers),
+    }
+    resp, err := s.client.streamRequest(ctx, s, reqBody, handler)
+    if err != nil {
+        logMessage("Streaming chat failed: %v", err)
+        s.restoreOnBlock(history, err)
+        s.restoreOnCancel(ctx, snapshot, err)
+        return nil, err
+    }
+    s.measureContext(reqBody.Model, resp)
+
+    if len(resp.Content) > 0 {
+        s.addMessageToConversation(RoleAssistant, resp.Content)
+        s.trimConversationHistory()
+    }
+    return resp, nil
+}
+
+// ChatStreamWithTools runs AChatWithTools on the default session with each
+// model turn streamed to handler
+func (c *AnthropicClient) ChatStreamWithTools(
+    ctx context.Context,
+    message string,
+    params *MessageParams,
+    handlers map[string]func(context.Context, json.RawMessage) (string, error),
+    handler StreamHandler,
+    opts ...RequestOption,
+) (*AnthropicResponse, error) {
+    return c.session.ChatStreamWithTools(ctx, message, params, handlers, handler, opts...)
+}
+
+// ChatStreamWithTools runs the tool loop of AChatWithTools, streaming each
+// model turn to handler as it is generated. Between turns handler receives a
+// StreamToolResult event for every tool call, so a frontend can show the
+// calls and their results as they happen. If ctx is cancelled part way, the
+// history is left as it was before the call rather than ending on an
+// unanswered tool call.
+func (s *Session) ChatStreamWithTools(
+    ctx context.Context,
+    message string,
+    params *MessageParams,
+    handlers map[string]func(context.Context, json.RawMessage) (string, error),
+    handler StreamHandler,
+    opts ...RequestOption,
+) (*AnthropicResponse, error) {
+    if handler == nil {
+        handler = func(StreamEvent) {}
+    }
+    return s.toolChat(ctx, message, params, handlers, handler, opts)
+}
+
+// restoreOnCancel rolls the history back to snapshot when a streamed call
+// was cancelled through ctx. Callers must hold s.mu.
+func (s *Session) restoreOnCancel(ctx context.Context, snapshot []Message, err error) {
+    if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+        logMessage("Stream cancelled; restoring conversation for session %s", s.id)
+        s.conversation = snapshot
+    }
+}
+
+// streamRequest is sendRequest for a streaming call: the events are passed
+// to handler as they arrive and assembled into the returned response
+func (c *AnthropicClient) streamRequest(ctx context.Context, session *Session, reqBody Request, handler StreamHandler) (*AnthropicResponse, error) {
+    if err := c.inflight.begin(); err != nil {
+        logMessage("Refusing request: %v", err)
+        return nil, err
+    }
+    defer c.inflight.end()
+
+    streamCtx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    reqBody.Stream = true
+    buf, jsonData, err := c.encodeRequest(&reqBody)
+    if err != nil {
+        return nil, err
+    }
+    defer buf.release()
+    req, err := c.prepareRequest(streamCtx, session, &reqBody, buf, jsonData)
+    if err != nil {
+        return nil, err
+    }
+
+    var stalled int32
+    // The request timeout only covers the wait for the response to start
+    var timedOut int32
+    started := func() bool { return false }
+    if timeout := c.requestTimeout; timeout > 0 {
+        timer := time.AfterFunc(timeout, func() {
+            atomic.StoreInt32(&timedOut, 1)
+            cancel()
+        })
+        started = timer.Stop
+    }
+    abortError := func(err error) error {
+        if atomic.LoadInt32(&timedOut) == 1 {
+            return fmt.Errorf("error sending request: no response within %s: %w", c.requestTimeout, context.DeadlineExceeded)
+        }
+        if atomic.LoadInt32(&stalled) == 1 {
+            logMessage("Stream stalled: no event for %s", c.streamIdleTimeout)
+            return fmt.Errorf("%w: no event for %s", ErrStreamStalled, c.streamIdleTimeout)
+        }
+        return err
+    }
+
+    logMessage("Sending streaming request to Anthropic API")
+    start := time.Now()
+    record := AuditRecord{Time: start, Model: reqBody.Model, Request: jsonData}
+    resp, err := c.httpClient.Do(req)
+    started()
+    if err != nil {
+        logMessage("API request failed: %v", err)
+        err = abortError(fmt.Errorf("error sending request: %w", err))
+        record.Error, record.Latency = err.Error(), time.Since(start)
+        c.auditExchange(ctx, session, record)
+        return nil, err
+    }
+    defer resp.Body.Close()
+    record.RequestID = resp.Header.Get("request-id")
+    record.StatusCode = resp.StatusCode
+
+    if resp.StatusCode != http.StatusOK {
+        body, err := ioutil.ReadAll(resp.Body)
+        if err != nil {
+            err = abortError(fmt.Errorf("error reading response: %w", err))
+        } else {
+            err = responseError(resp.StatusCode, body)
+        }
+        captureRawResponse(ctx, resp, body)
+        record.Response, record.Error, record.Latency = body, err.Error(), time.Since(start)
+        c.auditExchange(ctx, session, record)
+        return nil, err
+    }
+    captureRawResponse(ctx, resp, nil)
+
+    // The idle timer cancels the request when the stream goes quiet. It is
+    // armed only once the response has started, so a slow first byte is left
+    // to the request timeout rather than reported as a stall.
+    idle := func() {}
+    if timeout := c.streamIdleTimeout; timeout > 0 {
+        timer := time.AfterFunc(timeout, func() {
+            atomic.StoreInt32(&stalled, 1)
+            cancel()
+        })
+        defer timer.Stop()
+        idle = func() { timer.Reset(timeout) }
+    }
+
+    // Output guardrails must pass before any text reaches the handler, so the
+    // events are held back and replayed once the response has been checked
+    deliver := handler
+    var held []StreamEvent
+    if len(c.outputGuardrails) > 0 && handler != nil {
+        deliver = func(event StreamEvent) { held = append(held, event) }
+    }
+
+    var assembled AnthropicResponse
+    err = readStream(resp.Body, func(event StreamEvent) error {
+        idle()
+        if err := assembleStream(&assembled, event); err != nil {
+            return err
+        }
+        if event.Type != StreamPing && deliver != nil {
+            deliver(event)
+        }
+        return nil
+    })
+    record.Latency = time.Since(start)
+    if err != nil {
+        err = abortError(err)
+        logMessage("Stream failed: %v", err)
+        record.Error = err.Error()
+        c.auditExchange(ctx, session, record)
+        return nil, err
+    }
+
+    record.Response, _ = json.Marshal(&assembled)
+    result, err := c.finishResponse(ctx, session, reqBody, record, &assembled)
+    if err != nil || held == nil {
+        return result, err
+    }
+    replayChecked(held, result, handler)
+    return result, nil
+}
+
+// replayChecked passes held events to handler after the output guardrails
+// have run. Each text block's deltas are replaced by a single delta carrying
+// the checked text, so redactions reach the handler too.
+func replayChecked(events []StreamEvent, resp *AnthropicResponse, handler StreamHandler) {
+    for _, event := range events {
+        switch event.Type {
+        case StreamContentBlockDelta:
+            if event.Delta.Type == "text_delta" {
+                continue
+            }
+        case StreamContentBlockStop:
+            if event.Index >= 0 && event.Index < len(resp.Content) {
+                block := resp.Content[event.Index]
+                if block.Type == ContentTypeText && block.Text != "" {
+                    handler(StreamEvent{
+                        Type:  StreamContentBlockDelta,
+                        Index: event.Index,
+                        Delta: StreamDelta{Type: "text_delta", Text: block.Text},
+                    })
+                }
+            }
+        }
+        handler(event)
+    }
+}
+
+// readStream parses server-sent events from r, passing each data payload to
+// fn until the stream ends, fn fails, or a message_stop arrives
+func readStream(r io.Reader, fn func(StreamEvent) error) error {
+    events := newSSEReader(r)
+    defer events.release()
+    for {
+        data, err := events.next()
+        if err == io.EOF {
+            return fmt.Errorf("stream ended before message_stop")
+        }
+        if err != nil {
+            return fmt.Errorf("error reading stream: %w", err)
+        }
+        var event StreamEvent
+        if err := json.Unmarshal(data, &event); err != nil {
+            return fmt.Errorf("error parsing stream event: %w", err)
+        }
+        if err := fn(event); err != nil {
+            return err
+        }
+        if event.Type == StreamMessageStop {
+            return nil
+        }
+    }
+}
+
+// assembleStream applies one event to the response being built
+func assembleStream(resp *AnthropicResponse, event StreamEvent) error {
+    switch event.Type {
+    case StreamMessageStart:
+        if event.Message != nil {
+            *resp = *event.Message
+        }
+    case StreamContentBlockStart:
+        if event.ContentBlock == nil || event.Index != len(resp.Content) {
+            return fmt.Errorf("unexpected content block %d in stream", event.Index)
+        }
+        block := *event.ContentBlock
+        // Tool inputs start as {} and arrive as input_json_delta fragments
+        block.Input = nil
+        resp.Content = append(resp.Content, block)
+    case StreamContentBlockDelta:
+        if event.Index < 0 || event.Index >= len(resp.Content) {
+            return fmt.Errorf("delta for unknown content block %d in stream", event.Index)
+        }
+        block := &resp.Content[event.Index]
+        block.Text += event.Delta.Text
+        block.Thinking += event.Delta.Thinking
+        block.Input = append(block.Input, event.Delta.PartialJSON...)
+        if event.Delta.Signature != "" {
+            block.Signature = event.Delta.Signature
+        }
+    case StreamContentBlockStop:
+        if event.Index >= 0 && event.Index < len(resp.Content) {
+            block := &resp.Content[event.Index]
+            if isToolUseType(block.Type) && len(block.Input) == 0 {
+                block.Input = json.RawMessage("{}")
+            }
+        }
+    case StreamMessageDelta:
+        resp.StopReason = event.Delta.StopReason
+        resp.StopSequence = event.Delta.StopSequence
+        if event.Usage != nil {
+            resp.Usage.OutputTokens = event.Usage.OutputTokens
+            if event.Usage.ServerToolUse != nil {
+                resp.Usage.ServerToolUse = event.Usage.ServerToolUse
+            }
+        }
+    case StreamError:
+        if event.Error != nil {
+            return fmt.Errorf("API error: %s - %s", event.Error.Type, event.Error.Message)
+        }
+        return fmt.Errorf("API error in stream")
+    }
+    return nil
+}
+
+func isToolUseType(t ContentType) bool {
+    return t == ContentTypeToolUse || t == ContentTypeServerToolUse || t == ContentTypeMCPToolUse
+}
@@ -0,0 +1,235 @@
+package anthropic
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "reflect"
+)
+
+const (
+    // structuredOutputTool is the synthetic tool Claude is forced to call for
+    // structured output; its input is the answer
+    structuredOutputTool = "respond"
+
+    defaultOutputRepairs = 2
+)
+
+// OutputValidationError is returned when structured output still fails
+// validation after every repair attempt
+type OutputValidationError struct {
+    Attempts int             // Requests made, including the first
+    Output   json.RawMessage // Last output Claude produced, if any
+    Err      error           // Why the last output was rejected
+}
+
+func (e *OutputValidationError) Error() string {
+    return fmt.Sprintf("structured output invalid after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *OutputValidationError) Unwrap() error { return e.Err }
+
+// ExtractOptions configures ExtractInto
+type ExtractOptions struct {
+    Params     *MessageParams // Request parameters; nil uses the client defaults
+    MaxRetries int            // Correction turns after malformed output; overrides Params.MaxOutputRepairs
+}
+
+// ExtractInto asks Claude to fill in a T from prompt. The output schema is
+// derived from T as for NewToolFromStruct, Claude is forced to answer through
+// it, and the result is unmarshaled into T. When the output does not match
+// the schema or cannot be unmarshaled, the error is sent back to Claude as a
+// correction turn and the request is retried up to opts.MaxRetries times.
+//
+//     type Invoice struct {
+//         Number string  `json:"number"`
+//         Total  float64 `json:"total"`
+//     }
+//
+//     invoice, err := anthropic.ExtractInto[Invoice](ctx, client, text, nil)
+func ExtractInto[T any](ctx context.Context, client *AnthropicClient, prompt string, opts *ExtractOptions) (T, error) {
+    var result T
+    schema, err := inputSchemaFor(reflect.TypeOf((*T)(nil)).Elem())
+    if err != nil {
+        return result, fmt.Errorf("cannot build schema for %T: %w", result, err)
+    }
+
+    var params *MessageParams
+    retries := 0
+    if opts != nil {
+        params = opts.Params
+        retries = opts.MaxRetries
+    }
+    if retries == 0 {
+        retries = client.outputRepairLimit(params)
+    } else if retries < 0 {
+        retries = 0
+    }
+
+    decode := func(input json.RawMessage) error {
+        if err := ValidateAgainstSchema(schema, input); err != nil {
+            return err
+        }
+        var out T
+        if err := json.Unmarshal(input, &out); err != nil {
+            return fmt.Errorf("output cannot be decoded: %w", err)
+        }
+        result = out
+        return nil
+    }
+
+    session := client.session
+    session.mu.Lock()
+    systemPrompt := session.resolveSystemPrompt(params)
+    session.mu.Unlock()
+
+    if _, err := client.chatStructured(ctx, session, prompt, systemPrompt, schema, params, retries, decode); err != nil {
+        return result, err
+    }
+    return result, nil
+}
+
+// ChatJSON asks Claude for machine-readable output matching schema on the
+// client's default session. See Session.ChatJSON.
+func (c *AnthropicClient) ChatJSON(
+    ctx context.Context,
+    prompt string,
+    schema InputSchema,
+    params *MessageParams,
+) (json.RawMessage, error) {
+    return c.session.ChatJSON(ctx, prompt, schema, params)
+}
+
+// ChatJSON asks Claude for machine-readable output matching schema. Claude is
+// forced to call a synthetic "respond" tool whose input_schema is schema, and
+// the tool input is returned as the answer. Output that fails validation
+// against schema is sent back with the violations for correction, up to
+// params.MaxOutputRepairs times (default 2), before an *OutputValidationError
+// is returned. Like ChatWithTools it does not read or update the conversation
+// history; params.Tools and params.ToolChoice are ignored.
+func (s *Session) ChatJSON(
+    ctx context.Context,
+    prompt string,
+    schema InputSchema,
+    params *MessageParams,
+) (json.RawMessage, error) {
+    s.mu.Lock()
+    systemPrompt := s.resolveSystemPrompt(params)
+    s.mu.Unlock()
+
+    return s.client.chatJSON(ctx, s, prompt, systemPrompt, schema, params)
+}
+
+func (c *AnthropicClient) chatJSON(
+    ctx context.Context,
+    session *Session,
+    prompt string,
+    systemPrompt string,
+    schema InputSchema,
+    params *MessageParams,
+) (json.RawMessage, error) {
+    validate := func(input json.RawMessage) error {
+        return ValidateAgainstSchema(schema, input)
+    }
+    return c.chatStructured(ctx, session, prompt, systemPrompt, schema, params, c.outputRepairLimit(params), validate)
+}
+
+// outputRepairLimit resolves how many correction turns structured output gets
+func (c *AnthropicClient) outputRepairLimit(params *MessageParams) int {
+    params = c.resolveParams(params, nil)
+    switch {
+    case params.MaxOutputRepairs < 0:
+        return 0
+    case params.MaxOutputRepairs > 0:
+        return params.MaxOutputRepairs
+    }
+    return defaultOutputRepairs
+}
+
+// chatStructured forces Claude to answer through the respond tool. Output that
+// check rejects is answered with an is_error tool result describing the
+// problem, and the request is retried up to retries times.
+func (c *AnthropicClient) chatStructured(
+    ctx context.Context,
+    session *Session,
+    prompt string,
+    systemPrompt string,
+    schema InputSchema,
+    params *MessageParams,
+    retries int,
+    check func(json.RawMessage) error,
+) (json.RawMessage, error) {
+    prompt, err := c.checkInput(ctx, prompt)
+    if err != nil {
+        return nil, err
+    }
+    params = c.resolveParams(params, nil)
+    ctx, cancel := callContext(ctx, params)
+    defer cancel()
+    tool := Tool{
+        Name:        structuredOutputTool,
+        Description: "Give your answer by calling this tool. Its input is your complete response.",
+        InputSchema: schema,
+    }
+    if err := validateToolDefinition(tool); err != nil {
+        return nil, fmt.Errorf("invalid output schema: %w", err)
+    }
+
+    reqBody := newToolLoopRequest(params, systemPrompt, []Tool{tool})
+    reqBody.ToolChoice = &ToolChoice{Type: ToolChoiceTool, Name: tool.Name}
+    messages := []Message{{
+        Role: RoleUser,
+        Content: []MessageContent{{
+            Type: ContentTypeText,
+            Text: prompt,
+        }},
+    }}
+
+    for attempt := 0; ; attempt++ {
+        reqBody.Messages = messages
+        resp, err := c.sendRequest(ctx, session, reqBody)
+        if err != nil {
+            return nil, fmt.Errorf("structured output request error: %w", err)
+        }
+
+        input, err := structuredOutput(resp)
+        if err == nil && check != nil {
+            err = check(input)
+        }
+        if err == nil {
+            return input, nil
+        }
+        if attempt >= retries {
+            return nil, &OutputValidationError{Attempts: attempt + 1, Output: input, Err: err}
+        }
+
+        logMessage("Structured output rejected (attempt %d/%d): %v", attempt+1, retries+1, err)
+        if len(resp.Content) > 0 {
+            messages = append(messages, Message{Role: RoleAssistant, Content: resp.Content})
+        }
+        messages = append(messages, correctionMessage(resp, err))
+    }
+}
+
+// correctionMessage tells Claude why its structured output was rejected,
+// answering the respond call when there is one
+func correctionMessage(resp *AnthropicResponse, err error) Message {
+    text := fmt.Sprintf("Your output failed validation: %v. Call the %s tool again with corrected input.", err, structuredOutputTool)
+    for _, content := range resp.Content {
+        if content.Type == ContentTypeToolUse && content.Name == structuredOutputTool {
+            result := ToolResult{Content: text, IsError: true}
+            return Message{Role: RoleUser, Content: []MessageContent{result.toolResultBlock(content.ID)}}
+        }
+    }
+    return Message{Role: RoleUser, Content: []MessageContent{{Type: ContentTypeText, Text: text}}}
+}
+
+// structuredOutput extracts the input of the synthetic respond tool call
+func structuredOutput(resp *AnthropicResponse) (json.RawMessage, error) {
+    for _, content := range resp.Content {
+        if content.Type == ContentTypeToolUse && content.Name == structuredOutputTool {
+            return content.Input, nil
+        }
+    }
+    return nil, fmt.Errorf("response contains no %s tool call (stop reason: %s)", structuredOutputTool, resp.StopReason)
+}
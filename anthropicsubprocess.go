@@ -0,0 +1,253 @@
+package anthropic
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os/exec"
+    "sync"
+    "time"
+)
+
+const (
+    defaultSubprocessTimeout = 30 * time.Second
+    subprocessMaxLineBytes   = 16 << 20
+)
+
+// SubprocessConfig configures an external tool process. The process speaks a
+// line-delimited JSON protocol over stdin/stdout:
+//
+//     request:  {"id": 1, "name": "get_weather", "input": {...}}
+//     response: {"id": 1, "content": "...", "is_error": false}
+//
+// A response may carry "error" instead of content to report a failure. Anything
+// written to stderr is forwarded to the package log.
+type SubprocessConfig struct {
+    Command     string
+    Args        []string
+    Dir         string
+    Env         []string      // Full environment; nil inherits the parent's
+    CallTimeout time.Duration // Per-call timeout; defaults to 30s
+    MaxRestarts int           // Consecutive restarts allowed before giving up; 0 means unlimited
+}
+
+type subprocessRequest struct {
+    ID    int64           `json:"id"`
+    Name  string          `json:"name"`
+    Input json.RawMessage `json:"input"`
+}
+
+type subprocessResponse struct {
+    ID      int64  `json:"id"`
+    Content string `json:"content"`
+    IsError bool   `json:"is_error"`
+    Error   string `json:"error"`
+}
+
+// NewSubprocessTool returns a ToolHandler backed by a long-running child process,
+// letting tools be written in Python, Node, or any other language. The process
+// is started on first use and restarted if it crashes or a call times out.
+// Calls are serialized. Close stops the process.
+func NewSubprocessTool(def Tool, cfg SubprocessConfig) (ToolHandler, error) {
+    if err := validateToolDefinition(def); err != nil {
+        return nil, err
+    }
+    if cfg.Command == "" {
+        return nil, fmt.Errorf("subprocess tool %s: command is required", def.Name)
+    }
+    if cfg.CallTimeout <= 0 {
+        cfg.CallTimeout = defaultSubprocessTimeout
+    }
+    return &subprocessTool{def: def, cfg: cfg}, nil
+}
+
+type subprocessTool struct {
+    def Tool
+    cfg SubprocessConfig
+
+    mu       sync.Mutex
+    proc     *childProcess
+    nextID   int64
+    restarts int
+    closed   bool
+}
+
+// childProcess is one running instance of the tool executable
+type childProcess struct {
+    cmd   *exec.Cmd
+    stdin io.WriteCloser
+    lines chan []byte  // Closed when stdout reaches EOF
+    done  chan struct{}
+}
+
+func (t *subprocessTool) Definition() Tool {
+    return t.def
+}
+
+func (t *subprocessTool) Execute(ctx context.Context, input json.RawMessage) (ToolResult, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.closed {
+        return ToolResult{}, fmt.Errorf("subprocess tool %s is closed", t.def.Name)
+    }
+    if err := t.ensureRunning(); err != nil {
+        return ToolResult{}, err
+    }
+
+    t.nextID++
+    if len(input) == 0 {
+        input = json.RawMessage("{}")
+    }
+    line, err := json.Marshal(subprocessRequest{ID: t.nextID, Name: t.def.Name, Input: input})
+    if err != nil {
+        return ToolResult{}, fmt.Errorf("error encoding subprocess request: %w", err)
+    }
+    if _, err := t.proc.stdin.Write(append(line, '\n')); err != nil {
+        t.stop()
+        return ToolResult{}, fmt.Errorf("error writing to subprocess: %w", err)
+    }
+
+    timer := time.NewTimer(t.cfg.CallTimeout)
+    defer timer.Stop()
+
+    for {
+        select {
+        case out, ok := <-t.proc.lines:
+            if !ok {
+                logMessage("Subprocess for tool '%s' exited during a call", t.def.Name)
+                t.stop()
+                return ToolResult{}, fmt.Errorf("subprocess for tool %s exited unexpectedly", t.def.Name)
+            }
+            var resp subprocessResponse
+            if err := json.Unmarshal(out, &resp); err != nil {
+                logMessage("Ignoring malformed subprocess output: %s", out)
+                continue
+            }
+            if resp.ID != t.nextID {
+                logMessage("Ignoring stale subprocess response %d", resp.ID)
+                continue
+            }
+            t.restarts = 0
+            if resp.Error != "" {
+                return ToolResult{}, fmt.Errorf("%s", resp.Error)
+            }
+            return ToolResult{Content: resp.Content, IsError: resp.IsError}, nil
+
+        case <-timer.C:
+            // The protocol state is unknown after a timeout, so restart the process
+            logMessage("Subprocess call for tool '%s' timed out after %s", t.def.Name, t.cfg.CallTimeout)
+            t.stop()
+            return ToolResult{}, fmt.Errorf("subprocess call timed out after %s", t.cfg.CallTimeout)
+
+        case <-ctx.Done():
+            t.stop()
+            return ToolResult{}, ctx.Err()
+        }
+    }
+}
+
+// Close stops the child process. Further calls fail.
+func (t *subprocessTool) Close() error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    t.closed = true
+    t.stop()
+    return nil
+}
+
+// ensureRunning starts the child process if it is not running. Callers must hold t.mu.
+func (t *subprocessTool) ensureRunning() error {
+    if t.proc != nil {
+        select {
+        case <-t.proc.done:
+            logMessage("Subprocess for tool '%s' has exited; restarting", t.def.Name)
+            t.stop()
+        default:
+            return nil
+        }
+    }
+
+    if t.nextID > 0 {
+        if t.cfg.MaxRestarts > 0 && t.restarts >= t.cfg.MaxRestarts {
+            return fmt.Errorf("subprocess for tool %s exceeded %d restarts", t.def.Name, t.cfg.MaxRestarts)
+        }
+        t.restarts++
+    }
+
+    cmd := exec.Command(t.cfg.Command, t.cfg.Args...)
+    cmd.Dir = t.cfg.Dir
+    cmd.Env = t.cfg.Env
+    stdin, err := cmd.StdinPipe()
+    if err != nil {
+        return fmt.Errorf("error creating subprocess stdin: %w", err)
+    }
+    stdout, err := cmd.StdoutPipe()
+    if err != nil {
+        return fmt.Errorf("error creating subprocess stdout: %w", err)
+    }
+    stderr, err := cmd.StderrPipe()
+    if err != nil {
+        return fmt.Errorf("error creating subprocess stderr: %w", err)
+    }
+    if err := cmd.Start(); err != nil {
+        return fmt.Errorf("error starting subprocess for tool %s: %w", t.def.Name, err)
+    }
+    logMessage("Started subprocess for tool '%s' (pid %d)", t.def.Name, cmd.Process.Pid)
+
+    proc := &childProcess{
+        cmd:   cmd,
+        stdin: stdin,
+        lines: make(chan []byte, 16),
+        done:  make(chan struct{}),
+    }
+
+    // cmd.Wait closes the pipes, so it must only run once both readers are done
+    var readers sync.WaitGroup
+    readers.Add(2)
+    go func() {
+        defer readers.Done()
+        scanner := bufio.NewScanner(stdout)
+        scanner.Buffer(make([]byte, 64*1024), subprocessMaxLineBytes)
+        for scanner.Scan() {
+            line := append([]byte(nil), scanner.Bytes()...)
+            proc.lines <- line
+        }
+        close(proc.lines)
+    }()
+    go func() {
+        defer readers.Done()
+        scanner := bufio.NewScanner(stderr)
+        for scanner.Scan() {
+            logMessage("[%s stderr] %s", t.def.Name, scanner.Text())
+        }
+    }()
+    go func() {
+        readers.Wait()
+        cmd.Wait()
+        close(proc.done)
+    }()
+
+    t.proc = proc
+    return nil
+}
+
+// stop kills the child process if one is running. Callers must hold t.mu.
+func (t *subprocessTool) stop() {
+    if t.proc == nil {
+        return
+    }
+    t.proc.stdin.Close()
+    if t.proc.cmd.Process != nil {
+        t.proc.cmd.Process.Kill()
+    }
+    // Drain stdout so the reader goroutine can exit
+    go func(lines chan []byte) {
+        for range lines {
+        }
+    }(t.proc.lines)
+    t.proc = nil
+}
@@ -0,0 +1,223 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "sync"
+)
+
+const (
+    defaultSummaryConcurrency = 4
+    // Share of the context window a chunk may fill, leaving room for the
+    // instructions and the summary itself
+    summaryChunkShare = 0.5
+    // Bytes per token when sizing chunks, as for estimateTokens
+    summaryBytesPerToken = 4
+)
+
+// SummaryStyle selects the shape of a summary
+type SummaryStyle string
+
+const (
+    SummaryParagraph SummaryStyle = "paragraph" // Flowing prose
+    SummaryBullets   SummaryStyle = "bullets"   // A bulleted list of key points
+    SummaryOneLine   SummaryStyle = "one_line"  // A single sentence
+)
+
+// SummarizeOptions configures Summarize
+type SummarizeOptions struct {
+    Params *MessageParams // Request parameters; nil uses the client defaults
+    Style  SummaryStyle   // Defaults to SummaryParagraph
+    Words  int            // Target length of the summary in words; 0 leaves it to Claude
+    Focus  string         // What the summary should concentrate on, e.g. "decisions and action items"
+
+    // ChunkTokens caps the estimated tokens of text sent per request. Longer
+    // input is split, each chunk summarized, and the chunk summaries
+    // combined. 0 uses half the model's context window.
+    ChunkTokens int
+    Concurrency int // Chunks summarized at once; defaults to 4
+}
+
+// SummaryResult is the outcome of Summarize
+type SummaryResult struct {
+    Summary string
+    Chunks  int        // Pieces the input was split into; 1 when it fit in one request
+    Usage   ModelUsage // Requests, tokens and estimated cost of every round
+}
+
+// Summarize condenses text in one call. Text too long for a single request
+// is summarized map-reduce style: each chunk is summarized on its own, then
+// the partial summaries are combined, in further rounds if they are still
+// too long. Usage counts toward the client's totals and budget.
+func (c *AnthropicClient) Summarize(ctx context.Context, text string, opts *SummarizeOptions) (*SummaryResult, error) {
+    if strings.TrimSpace(text) == "" {
+        return nil, fmt.Errorf("cannot summarize empty text")
+    }
+    if opts == nil {
+        opts = &SummarizeOptions{}
+    }
+    params := *c.resolveParams(opts.Params, nil)
+    params.Tools, params.ToolChoice, params.MCPServers = nil, nil, nil
+
+    chunkBytes := opts.ChunkTokens * summaryBytesPerToken
+    if chunkBytes <= 0 {
+        window := defaultContextWindow
+        if caps, ok := c.ModelCapabilities(params.Model); ok && caps.ContextWindow > 0 {
+            window = caps.ContextWindow
+        }
+        chunkBytes = int(float64(window)*summaryChunkShare) * summaryBytesPerToken
+    }
+    concurrency := opts.Concurrency
+    if concurrency <= 0 {
+        concurrency = defaultSummaryConcurrency
+    }
+
+    var usage usageTracker
+    summarize := func(ctx context.Context, prompt, part string) (string, error) {
+        p := params
+        p.System = prompt
+        resp, err := c.sendMessages(ctx, []Message{{
+            Role:    RoleUser,
+            Content: []MessageContent{{Type: ContentTypeText, Text: part}},
+        }}, &p)
+        if err != nil {
+            return "", err
+        }
+        usage.record(resp.Model, resp.Usage)
+        summary := strings.TrimSpace(resp.Text())
+        if summary == "" {
+            return "", fmt.Errorf("model returned an empty summary")
+        }
+        return summary, nil
+    }
+
+    chunks := splitText(text, chunkBytes)
+    logMessage("Summarizing %d bytes in %d chunks", len(text), len(chunks))
+    result := &SummaryResult{Chunks: len(chunks)}
+    parts, size := chunks, len(text)
+    for len(parts) > 1 {
+        partials, err := summarizeParts(ctx, parts, concurrency, func(ctx context.Context, part string) (string, error) {
+            return summarize(ctx, partialSummaryPrompt(opts.Focus), part)
+        })
+        if err != nil {
+            return nil, fmt.Errorf("summarization error: %w", err)
+        }
+        combined := strings.Join(partials, "\n\n")
+        if len(combined) >= size {
+            return nil, fmt.Errorf("summarization error: chunk summaries are no shorter than their input")
+        }
+        parts, size = splitText(combined, chunkBytes), len(combined)
+    }
+
+    summary, err := summarize(ctx, summaryPrompt(opts, len(chunks) > 1), parts[0])
+    if err != nil {
+        return nil, fmt.Errorf("summarization error: %w", err)
+    }
+    result.Summary = summary
+    result.Usage = usage.report(c.pricingTable()).Total
+    return result, nil
+}
+
+// summarizeParts summarizes parts concurrently, keeping their order
+func summarizeParts(ctx context.Context, parts []string, concurrency int, fn func(context.Context, string) (string, error)) ([]string, error) {
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    summaries := make([]string, len(parts))
+    errs := make([]error, len(parts))
+    slots := make(chan struct{}, concurrency)
+    var wg sync.WaitGroup
+    for i, part := range parts {
+        wg.Add(1)
+        slots <- struct{}{}
+        go func(i int, part string) {
+            defer wg.Done()
+            defer func() { <-slots }()
+            summaries[i], errs[i] = fn(ctx, part)
+            if errs[i] != nil {
+                cancel()
+            }
+        }(i, part)
+    }
+    wg.Wait()
+
+    for i, err := range errs {
+        if err != nil {
+            return nil, fmt.Errorf("chunk %d of %d: %w", i+1, len(parts), err)
+        }
+    }
+    return summaries, nil
+}
+
+func partialSummaryPrompt(focus string) string {
+    prompt := "You are summarizing one part of a longer document. Summarize this part in detail, " +
+        "keeping the facts, names, figures and conclusions a summary of the whole document would need. " +
+        "Reply with the summary only."
+    if focus != "" {
+        prompt += " Concentrate on " + focus + "."
+    }
+    return prompt
+}
+
+func summaryPrompt(opts *SummarizeOptions, combined bool) string {
+    var b strings.Builder
+    if combined {
+        b.WriteString("The text below is a series of summaries of consecutive parts of one document. " +
+            "Combine them into a single summary of the whole document.")
+    } else {
+        b.WriteString("Summarize the text below.")
+    }
+    switch opts.Style {
+    case SummaryBullets:
+        b.WriteString(" Write the summary as a bulleted list of the key points.")
+    case SummaryOneLine:
+        b.WriteString(" Write the summary as a single sentence.")
+    default:
+        b.WriteString(" Write the summary as prose.")
+    }
+    if opts.Words > 0 {
+        fmt.Fprintf(&b, " Use at most %d words.", opts.Words)
+    }
+    if opts.Focus != "" {
+        b.WriteString(" Concentrate on " + opts.Focus + ".")
+    }
+    b.WriteString(" Reply with the summary only.")
+    return b.String()
+}
+
+// splitText splits text into chunks of at most size bytes, breaking at
+// paragraph breaks where possible, then at line breaks and spaces
+func splitText(text string, size int) []string {
+    if len(text) <= size {
+        return []string{text}
+    }
+    var chunks []string
+    for len(text) > size {
+        cut := -1
+        for _, sep := range []string{"\n\n", "\n", " "} {
+            if i := strings.LastIndex(text[:size], sep); i > 0 {
+                cut = i + len(sep)
+                break
+            }
+        }
+        if cut <= 0 {
+            cut = size
+            // Don't split a UTF-8 sequence
+            for cut > 0 && text[cut]&0xC0 == 0x80 {
+                cut--
+            }
+            if cut == 0 {
+                cut = size
+            }
+        }
+        if chunk := strings.TrimSpace(text[:cut]); chunk != "" {
+            chunks = append(chunks, chunk)
+        }
+        text = text[cut:]
+    }
+    if chunk := strings.TrimSpace(text); chunk != "" {
+        chunks = append(chunks, chunk)
+    }
+    return chunks
+}
@@ -0,0 +1,48 @@
+package anthropic
+
+import "encoding/json"
+
+// CacheControl marks a content block as the end of a cacheable prompt prefix.
+// Later requests that repeat the prefix read it from the cache at a fraction
+// of the input token cost.
+type CacheControl struct {
+    Type string `json:"type"`          // Always "ephemeral"
+    TTL  string `json:"ttl,omitempty"` // "5m" (default) or "1h"
+}
+
+// EphemeralCache returns the cache_control value for the default 5 minute cache
+func EphemeralCache() *CacheControl {
+    return &CacheControl{Type: "ephemeral"}
+}
+
+// NewCachedSystemPrompt splits a system prompt into blocks for
+// MessageParams.SystemBlocks, caching the stable prefix and sending the
+// dynamic suffix uncached. Either part may be empty.
+func NewCachedSystemPrompt(stable, dynamic string) []MessageContent {
+    var blocks []MessageContent
+    if stable != "" {
+        blocks = append(blocks, MessageContent{Type: ContentTypeText, Text: stable, CacheControl: EphemeralCache()})
+    }
+    if dynamic != "" {
+        blocks = append(blocks, MessageContent{Type: ContentTypeText, Text: dynamic})
+    }
+    return blocks
+}
+
+// requestAlias has Request's fields without its JSON methods
+type requestAlias Request
+
+// MarshalJSON sends SystemBlocks as the array form of "system" when set, and
+// the System string otherwise
+func (r Request) MarshalJSON() ([]byte, error) {
+    if len(r.SystemBlocks) == 0 {
+        return json.Marshal(requestAlias(r))
+    }
+    return json.Marshal(struct {
+        requestAlias
+        System []MessageContent `json:"system"`
+    }{
+        requestAlias: requestAlias(r),
+        System:       r.SystemBlocks,
+    })
+}
@@ -0,0 +1,152 @@
+package anthropic
+
+import (
+    "fmt"
+    "strings"
+    "text/template"
+    "time"
+)
+
+// defaultSystemTemplate renders defaultSystemPrompt, listing whichever tools
+// the request carries
+var defaultSystemTemplate = mustParseSystemPrompt(defaultSystemPrompt)
+
+// PromptData is the data a system prompt template is rendered with
+type PromptData struct {
+    Now      time.Time
+    Date     string            // Now as YYYY-MM-DD
+    UserName string            // From WithSessionUserName
+    Tools    []Tool            // Tools sent with the request
+    Vars     map[string]string // From WithSessionPromptVars; missing keys render empty
+}
+
+// SystemPromptTemplate is a text/template system prompt rendered at request
+// time. Besides the PromptData fields it can call toolList, which formats
+// tools as a numbered list with their descriptions, and toolNames:
+//
+//     Today is {{.Date}}. You are helping {{.UserName}}.
+//     {{if .Tools}}You have access to the following tools:
+//
+//     {{toolList .Tools}}{{end}}
+type SystemPromptTemplate struct {
+    source string
+    tmpl   *template.Template
+}
+
+var systemPromptFuncs = template.FuncMap{
+    "toolList":  formatToolList,
+    "toolNames": formatToolNames,
+}
+
+// ParseSystemPrompt parses a system prompt template
+func ParseSystemPrompt(text string) (*SystemPromptTemplate, error) {
+    tmpl, err := template.New("system").Funcs(systemPromptFuncs).Option("missingkey=zero").Parse(text)
+    if err != nil {
+        return nil, fmt.Errorf("invalid system prompt template: %w", err)
+    }
+    return &SystemPromptTemplate{source: text, tmpl: tmpl}, nil
+}
+
+func mustParseSystemPrompt(text string) *SystemPromptTemplate {
+    t, err := ParseSystemPrompt(text)
+    if err != nil {
+        panic(err)
+    }
+    return t
+}
+
+// Render executes the template with data
+func (t *SystemPromptTemplate) Render(data PromptData) (string, error) {
+    var sb strings.Builder
+    if err := t.tmpl.Execute(&sb, data); err != nil {
+        return "", fmt.Errorf("error rendering system prompt: %w", err)
+    }
+    return sb.String(), nil
+}
+
+// String returns the template source
+func (t *SystemPromptTemplate) String() string {
+    return t.source
+}
+
+// WithSystemPromptTemplate sets a client system prompt that is rendered for
+// every request, replacing any WithSystemPrompt value
+func WithSystemPromptTemplate(t *SystemPromptTemplate) ClientOption {
+    return func(c *AnthropicClient) {
+        if t != nil {
+            c.systemTemplate = t
+            c.systemPrompt = t.String()
+        }
+    }
+}
+
+// WithSessionUserName sets the UserName a session renders system prompt templates with
+func WithSessionUserName(name string) SessionOption {
+    return func(s *Session) {
+        s.userName = name
+    }
+}
+
+// WithSessionPromptVars sets the Vars a session renders system prompt templates with
+func WithSessionPromptVars(vars map[string]string) SessionOption {
+    return func(s *Session) {
+        s.promptVars = vars
+    }
+}
+
+// renderSystemPrompt produces the client-level system prompt for a request:
+// the default params' System if set, else the client prompt, rendering the
+// template when one is set. A template that fails to render is logged and
+// its source sent instead.
+func (c *AnthropicClient) renderSystemPrompt(session *Session, params *MessageParams) string {
+    if c.defaultParams.System != "" {
+        return c.defaultParams.System
+    }
+    if c.systemTemplate == nil {
+        return c.systemPrompt
+    }
+    if params == nil {
+        params = &c.defaultParams
+    }
+
+    now := time.Now()
+    data := PromptData{
+        Now:   now,
+        Date:  now.Format("2006-01-02"),
+        Tools: c.resolveTools(params),
+    }
+    if session != nil {
+        data.UserName = session.userName
+        data.Vars = session.promptVars
+    }
+    text, err := c.systemTemplate.Render(data)
+    if err != nil {
+        logMessage("%v", err)
+        return c.systemPrompt
+    }
+    return text
+}
+
+// formatToolList renders tools as a numbered list of names and descriptions
+func formatToolList(tools []Tool) string {
+    var sb strings.Builder
+    for i, tool := range tools {
+        if i > 0 {
+            sb.WriteString("\n\n")
+        }
+        fmt.Fprintf(&sb, "%d. '%s'", i+1, tool.Name)
+        if tool.Description != "" {
+            fmt.Fprintf(&sb, "\n   - %s", tool.Description)
+        }
+    }
+    return sb.String()
+}
+
+// formatToolNames renders tool names as a comma-separated list
+func formatToolNames(tools []Tool) string {
+    names := make([]string, len(tools))
+    for i, tool := range tools {
+        names[i] = tool.Name
+    }
+    return strings.Join(names, ", ")
+}
@@ -0,0 +1,127 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+    "strings"
+)
+
+const (
+    defaultTitleModel = "claude-3-5-haiku-20241022"
+    titleTurns        = 4    // Number of leading messages used to derive the title
+    titleMaxTokens    = 30
+    titlePrompt       = "Write a short title (at most six words) for the following conversation. " +
+        "Reply with the title only, without quotes or trailing punctuation.\n\n"
+)
+
+// WithTitleModel sets the model used by GenerateTitle. A small, inexpensive
+// model is recommended since titles only need a few tokens.
+func WithTitleModel(model string) ClientOption {
+    return func(c *AnthropicClient) {
+        if model != "" {
+            c.titleModel = model
+        }
+    }
+}
+
+// GenerateTitle produces a short title for the default session's conversation
+func (c *AnthropicClient) GenerateTitle(ctx context.Context) (string, error) {
+    return c.session.GenerateTitle(ctx)
+}
+
+// Title returns the cached title for the session, or an empty string if
+// GenerateTitle has not been called yet.
+func (s *Session) Title() string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    return s.title
+}
+
+// SetTitle overrides the cached title, e.g. after the user renames a conversation
+func (s *Session) SetTitle(title string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.title = title
+}
+
+// GenerateTitle asks a cheap model for a short title summarizing the first few
+// turns of the conversation, for chat history sidebars. The result is cached on
+// the session and returned without another request on subsequent calls.
+func (s *Session) GenerateTitle(ctx context.Context) (string, error) {
+    s.mu.Lock()
+    if s.title != "" {
+        title := s.title
+        s.mu.Unlock()
+        return title, nil
+    }
+    transcript := conversationTranscript(s.conversation, titleTurns)
+    s.mu.Unlock()
+
+    if transcript == "" {
+        return "", fmt.Errorf("cannot generate a title for an empty conversation")
+    }
+
+    logMessage("Generating title for session %s", s.id)
+    model := s.client.titleModel
+    if model == "" {
+        model = defaultTitleModel
+    }
+
+    resp, err := s.client.sendRequest(ctx, s, Request{
+        Model: model,
+        Messages: []Message{{
+            Role: RoleUser,
+            Content: []MessageContent{{
+                Type: ContentTypeText,
+                Text: titlePrompt + transcript,
+            }},
+        }},
+        MaxTokens: titleMaxTokens,
+    })
+    if err != nil {
+        logMessage("Title generation failed: %v", err)
+        return "", fmt.Errorf("title generation error: %w", err)
+    }
+
+    var title string
+    for _, content := range resp.Content {
+        if content.Type == ContentTypeText {
+            title += content.Text
+        }
+    }
+    title = strings.Trim(strings.TrimSpace(title), `"'.`)
+    if title == "" {
+        return "", fmt.Errorf("model returned an empty title")
+    }
+
+    s.mu.Lock()
+    s.title = title
+    s.mu.Unlock()
+
+    logMessage("Generated title for session %s: %s", s.id, title)
+    return title, nil
+}
+
+// conversationTranscript flattens the text of the first n messages into a
+// plain "Role: text" transcript, skipping tool traffic.
+func conversationTranscript(conversation []Message, n int) string {
+    var sb strings.Builder
+    for i, msg := range conversation {
+        if i >= n {
+            break
+        }
+        for _, content := range msg.Content {
+            if content.Type != ContentTypeText || content.Text == "" {
+                continue
+            }
+            role := "User"
+            if msg.Role == RoleAssistant {
+                role = "Assistant"
+            }
+            fmt.Fprintf(&sb, "%s: %s\n", role, content.Text)
+        }
+    }
+    return sb.String()
+}
@@ -0,0 +1,145 @@
+package anthropic
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "time"
+)
+
+// ErrNoToolHandler is returned when Claude calls a tool that has no handler
+var ErrNoToolHandler = errors.New("no handler for tool")
+
+// ToolResult is the outcome of a tool execution. Setting IsError reports a
+// tool-level failure back to Claude as an is_error tool_result so it can recover.
+// Blocks carries rich results (multiple text blocks, images); when both are set,
+// Content is sent as a leading text block.
+type ToolResult struct {
+    Content string
+    Blocks  []MessageContent
+    IsError bool
+}
+
+// ToolHandler is implemented by tools that carry their own definition, state, or
+// configuration. Handlers that also implement io.Closer are closed by
+// ToolRegistry.Close.
+type ToolHandler interface {
+    Definition() Tool
+    Execute(ctx context.Context, input json.RawMessage) (ToolResult, error)
+}
+
+// NewFuncTool adapts a plain handler function into a ToolHandler
+func NewFuncTool(def Tool, fn ToolHandlerFunc) ToolHandler {
+    return &funcTool{def: def, fn: fn}
+}
+
+type funcTool struct {
+    def Tool
+    fn  ToolHandlerFunc
+}
+
+func (t *funcTool) Definition() Tool {
+    return t.def
+}
+
+func (t *funcTool) Execute(ctx context.Context, input json.RawMessage) (ToolResult, error) {
+    content, err := t.fn(ctx, input)
+    if err != nil {
+        return ToolResult{}, err
+    }
+    return ToolResult{Content: content}, nil
+}
+
+// toolResultBlock converts a ToolResult into the tool_result content block sent to Claude
+func (r ToolResult) toolResultBlock(toolUseID string) MessageContent {
+    block := MessageContent{
+        Type:      ContentTypeToolResult,
+        ToolUseID: toolUseID,
+        IsError:   r.IsError,
+    }
+    if len(r.Blocks) == 0 {
+        block.Content = r.Content
+        return block
+    }
+    if r.Content != "" {
+        block.ContentBlocks = append(block.ContentBlocks, MessageContent{
+            Type: ContentTypeText,
+            Text: r.Content,
+        })
+    }
+    block.ContentBlocks = append(block.ContentBlocks, r.Blocks...)
+    return block
+}
+
+// invokeTool executes a tool call through the client's middleware chain. The
+// per-call handler map is used when it has an entry for the tool, falling back
+// to the client's registry. It returns an error wrapping ErrNoToolHandler when
+// neither knows the tool. Input that violates the tool's InputSchema is
+// rejected with an is_error result before the handler runs.
+func (c *AnthropicClient) invokeTool(
+    ctx context.Context,
+    call ToolUse,
+    tools []Tool,
+    handlers map[string]func(context.Context, json.RawMessage) (string, error),
+) (ToolResult, error) {
+    if err := c.inflight.begin(); err != nil {
+        logMessage("Refusing tool call '%s': %v", call.Name, err)
+        return ToolResult{}, err
+    }
+    defer c.inflight.end()
+
+    execute := func(ctx context.Context, call ToolUse) (ToolResult, error) {
+        handler, exists := handlers[call.Name]
+        var registered ToolHandler
+        if registry := c.registryFor(ctx); !exists && registry != nil {
+            registered, exists = registry.Handler(call.Name)
+        }
+        if !exists {
+            return ToolResult{}, fmt.Errorf("%w: %s", ErrNoToolHandler, call.Name)
+        }
+
+        if def, found := c.findToolDefinition(ctx, call.Name, tools); found && def.InputSchema.Type != "" {
+            if err := ValidateAgainstSchema(def.InputSchema, call.Input); err != nil {
+                logMessage("Rejecting input for tool '%s': %v", call.Name, err)
+                return toolErrorResult(fmt.Errorf("invalid input for tool %s: %w", call.Name, err)), nil
+            }
+        }
+
+        if registered != nil {
+            return registered.Execute(ctx, call.Input)
+        }
+        content, err := handler(ctx, call.Input)
+        if err != nil {
+            return ToolResult{}, err
+        }
+        return ToolResult{Content: content}, nil
+    }
+    start := time.Now()
+    result, err := chainToolMiddleware(execute, c.toolMiddleware)(ctx, call)
+    c.toolStats.record(call.Name, time.Since(start), result, err)
+    return result, err
+}
+
+// findToolDefinition locates a tool's definition in the request's tool list,
+// falling back to the client's registry.
+func (c *AnthropicClient) findToolDefinition(ctx context.Context, name string, tools []Tool) (Tool, bool) {
+    for _, tool := range tools {
+        if tool.Name == name {
+            return tool, true
+        }
+    }
+    if registry := c.registryFor(ctx); registry != nil {
+        return registry.Definition(name)
+    }
+    return Tool{}, false
+}
+
+// closeToolHandler closes a handler if it implements io.Closer
+func closeToolHandler(handler ToolHandler) error {
+    if closer, ok := handler.(io.Closer); ok {
+        return closer.Close()
+    }
+    return nil
+}
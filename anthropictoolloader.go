@@ -0,0 +1,111 @@
+package anthropic
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/fs"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+
+    "gopkg.in/yaml.v3"
+)
+
+// ParseToolDefinitions decodes tool definitions from JSON or YAML. The document
+// may be a list of tools or an object with a "tools" list; field names match
+// the API (name, description, input_schema). format is "json", "yaml" or "yml".
+func ParseToolDefinitions(data []byte, format string) ([]Tool, error) {
+    switch strings.ToLower(format) {
+    case "json":
+    case "yaml", "yml":
+        // Round-trip through JSON so the API field names in Tool's json tags apply
+        var doc interface{}
+        if err := yaml.Unmarshal(data, &doc); err != nil {
+            return nil, fmt.Errorf("error parsing YAML tool definitions: %w", err)
+        }
+        converted, err := json.Marshal(doc)
+        if err != nil {
+            return nil, fmt.Errorf("error converting YAML tool definitions: %w", err)
+        }
+        data = converted
+    default:
+        return nil, fmt.Errorf("unsupported tool definition format: %s", format)
+    }
+
+    var tools []Tool
+    if err := json.Unmarshal(data, &tools); err != nil {
+        var wrapped struct {
+            Tools []Tool `json:"tools"`
+        }
+        if wrappedErr := json.Unmarshal(data, &wrapped); wrappedErr != nil {
+            return nil, fmt.Errorf("error parsing tool definitions: %w", err)
+        }
+        tools = wrapped.Tools
+    }
+
+    for _, tool := range tools {
+        if err := validateToolDefinition(tool); err != nil {
+            return nil, err
+        }
+    }
+    return tools, nil
+}
+
+// LoadToolDefinitions reads tool definitions from a .json, .yaml or .yml file
+func LoadToolDefinitions(filename string) ([]Tool, error) {
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        return nil, fmt.Errorf("error reading tool definitions: %w", err)
+    }
+    tools, err := ParseToolDefinitions(data, strings.TrimPrefix(filepath.Ext(filename), "."))
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", filename, err)
+    }
+    return tools, nil
+}
+
+// LoadToolDefinitionsFS reads tool definitions from every file in fsys matching
+// the glob pattern (e.g. "tools/*.yaml"), which works with go:embed file systems.
+func LoadToolDefinitionsFS(fsys fs.FS, pattern string) ([]Tool, error) {
+    matches, err := fs.Glob(fsys, pattern)
+    if err != nil {
+        return nil, fmt.Errorf("invalid tool definition pattern: %w", err)
+    }
+
+    var tools []Tool
+    for _, name := range matches {
+        data, err := fs.ReadFile(fsys, name)
+        if err != nil {
+            return nil, fmt.Errorf("error reading tool definitions: %w", err)
+        }
+        defs, err := ParseToolDefinitions(data, strings.TrimPrefix(path.Ext(name), "."))
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", name, err)
+        }
+        tools = append(tools, defs...)
+    }
+    return tools, nil
+}
+
+// ApplyDefinitions binds externally maintained definitions to already
+// registered handlers by tool name, replacing the descriptions and schemas the
+// handlers were registered with. It fails without changing anything if a
+// definition names a tool that has no registered handler.
+func (r *ToolRegistry) ApplyDefinitions(defs []Tool) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    for _, def := range defs {
+        if _, exists := r.tools[def.Name]; !exists {
+            return fmt.Errorf("no registered handler for tool definition %s", def.Name)
+        }
+    }
+    for _, def := range defs {
+        t := r.tools[def.Name]
+        t.def = def
+        r.tools[def.Name] = t
+        logMessage("Applied external definition for tool '%s'", def.Name)
+    }
+    return nil
+}
@@ -0,0 +1,104 @@
+package anthropic
+
+import (
+    "errors"
+    "fmt"
+)
+
+// defaultMaxToolIterations bounds the model turns of a tool loop when neither
+// the call nor the client sets a limit
+const defaultMaxToolIterations = 10
+
+// ErrMaxIterations is returned (wrapped in a *MaxIterationsError) when a tool
+// loop reaches its iteration limit without a final answer. Test for it with errors.Is.
+var ErrMaxIterations = errors.New("exceeded maximum number of tool call iterations")
+
+// MaxIterationsError carries the partial conversation of a tool loop that hit
+// its iteration limit, so callers can inspect it, summarize it, or resume with
+// a higher limit
+type MaxIterationsError struct {
+    Iterations   int                // Model turns taken
+    Messages     []Message          // Conversation up to and including the last tool results
+    LastResponse *AnthropicResponse // Final assistant response received
+    Usage        Usage              // Tokens used across all iterations
+}
+
+func (e *MaxIterationsError) Error() string {
+    return fmt.Sprintf("exceeded maximum number of tool call iterations (%d)", e.Iterations)
+}
+
+// Is makes errors.Is(err, ErrMaxIterations) match
+func (e *MaxIterationsError) Is(target error) bool {
+    return target == ErrMaxIterations
+}
+
+// WithMaxToolIterations sets the default number of model turns a tool loop may
+// take before giving up with ErrMaxIterations. MessageParams.MaxToolIterations
+// overrides it per call.
+func WithMaxToolIterations(n int) ClientOption {
+    return func(c *AnthropicClient) {
+        if n > 0 {
+            c.maxToolIterations = n
+        }
+    }
+}
+
+// toolIterationLimit resolves the iteration limit for a tool loop call
+func (c *AnthropicClient) toolIterationLimit(params *MessageParams) int {
+    if params.MaxToolIterations > 0 {
+        return params.MaxToolIterations
+    }
+    if c.maxToolIterations > 0 {
+        return c.maxToolIterations
+    }
+    return defaultMaxToolIterations
+}
+
+// newToolLoopRequest builds the request template for a tool loop. It copies
+// what it needs out of the caller's params, including the tool choice, so that
+// per-iteration changes never reach caller-owned structs.
+func newToolLoopRequest(params *MessageParams, systemPrompt string, tools []Tool) Request {
+    var toolChoice *ToolChoice
+    if params.ToolChoice != nil {
+        choice := *params.ToolChoice
+        toolChoice = &choice
+    } else if len(tools) > 0 {
+        toolChoice = &ToolChoice{Type: ToolChoiceAuto}
+    }
+
+    return Request{
+        Model:         params.Model,
+        System:        systemPrompt,
+        SystemBlocks:  params.SystemBlocks,
+        MaxTokens:     params.MaxTokens,
+        Temperature:   params.Temperature,
+        TopP:          params.TopP,
+        TopK:          params.TopK,
+        StopSequences: params.StopSequences,
+        Tools:         tools,
+        ToolChoice:    toolChoice,
+        MCPServers:    normalizeMCPServers(params.MCPServers),
+    }
+}
+
+// followUpToolChoice is the tool choice for the turns after the first. A forced
+// tool is released so Claude can give its final answer, but the caller's
+// disable_parallel_tool_use setting is kept.
+func followUpToolChoice(original *ToolChoice) *ToolChoice {
+    if original == nil || !original.DisableParallel {
+        return nil
+    }
+    return &ToolChoice{Type: ToolChoiceAuto, DisableParallel: true}
+}
+
+// maxIterationsError snapshots the loop state for a MaxIterationsError
+func maxIterationsError(iterations int, messages []Message, last *AnthropicResponse, usage Usage) error {
+    snapshot := make([]Message, len(messages))
+    copy(snapshot, messages)
+    return &MaxIterationsError{
+        Iterations:   iterations,
+        Messages:     snapshot,
+        LastResponse: last,
+        Usage:        usage,
+    }
+}
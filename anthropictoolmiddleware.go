@@ -0,0 +1,102 @@
+package anthropic
+
+import (
+    "context"
+    "fmt"
+    "time"
+)
+
+// ToolExecutor runs a single tool call. It is the unit wrapped by ToolMiddleware.
+type ToolExecutor func(ctx context.Context, call ToolUse) (ToolResult, error)
+
+// ToolMiddleware wraps tool execution with cross-cutting behavior such as
+// logging, metrics, retries, input validation, or authorization.
+type ToolMiddleware func(next ToolExecutor) ToolExecutor
+
+// WithToolMiddleware registers middleware applied to every tool handler invoked
+// by ChatWithTools and AChatWithTools. The first middleware registered is the
+// outermost wrapper.
+func WithToolMiddleware(middleware ...ToolMiddleware) ClientOption {
+    return func(c *AnthropicClient) {
+        for _, mw := range middleware {
+            if mw != nil {
+                c.toolMiddleware = append(c.toolMiddleware, mw)
+            }
+        }
+    }
+}
+
+// chainToolMiddleware wraps the executor so that middleware[0] runs first
+func chainToolMiddleware(executor ToolExecutor, middleware []ToolMiddleware) ToolExecutor {
+    for i := len(middleware) - 1; i >= 0; i-- {
+        executor = middleware[i](executor)
+    }
+    return executor
+}
+
+// ToolLoggingMiddleware logs every tool call, its duration, and its outcome
+// through the package logger.
+func ToolLoggingMiddleware() ToolMiddleware {
+    return func(next ToolExecutor) ToolExecutor {
+        return func(ctx context.Context, call ToolUse) (ToolResult, error) {
+            start := time.Now()
+            logMessage("Tool '%s' (ID: %s) started", call.Name, call.ID)
+            result, err := next(ctx, call)
+            switch {
+            case err != nil:
+                logMessage("Tool '%s' failed after %s: %v", call.Name, time.Since(start), err)
+            case result.IsError:
+                logMessage("Tool '%s' returned an error result after %s", call.Name, time.Since(start))
+            default:
+                logMessage("Tool '%s' completed in %s", call.Name, time.Since(start))
+            }
+            return result, err
+        }
+    }
+}
+
+// ToolTimeoutMiddleware bounds each tool call by the given duration. Handlers
+// must honor context cancellation for the timeout to take effect.
+func ToolTimeoutMiddleware(timeout time.Duration) ToolMiddleware {
+    return func(next ToolExecutor) ToolExecutor {
+        return func(ctx context.Context, call ToolUse) (ToolResult, error) {
+            ctx, cancel := context.WithTimeout(ctx, timeout)
+            defer cancel()
+            return next(ctx, call)
+        }
+    }
+}
+
+// ToolRecoveryMiddleware converts a panicking handler into an error so a faulty
+// tool cannot crash the whole chat loop.
+func ToolRecoveryMiddleware() ToolMiddleware {
+    return func(next ToolExecutor) ToolExecutor {
+        return func(ctx context.Context, call ToolUse) (result ToolResult, err error) {
+            defer func() {
+                if r := recover(); r != nil {
+                    logMessage("Tool '%s' panicked: %v", call.Name, r)
+                    result = ToolResult{}
+                    err = fmt.Errorf("tool %s panicked: %v", call.Name, r)
+                }
+            }()
+            return next(ctx, call)
+        }
+    }
+}
+
+// ToolAuthorizationMiddleware rejects tool calls for which authorize returns an
+// error. The rejection is reported to Claude as an is_error tool result.
+func ToolAuthorizationMiddleware(authorize func(ctx context.Context, call ToolUse) error) ToolMiddleware {
+    return func(next ToolExecutor) ToolExecutor {
+        return func(ctx context.Context, call ToolUse) (ToolResult, error) {
+            if err := authorize(ctx, call); err != nil {
+                logMessage("Tool '%s' call not authorized: %v", call.Name, err)
+                return ToolResult{
+                    Content: fmt.Sprintf("Tool call not authorized: %v", err),
+                    IsError: true,
+                }, nil
+            }
+            return next(ctx, call)
+        }
+    }
+}
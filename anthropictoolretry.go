@@ -0,0 +1,160 @@
+package anthropic
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net"
+    "time"
+)
+
+const defaultToolRetryBackoff = 200 * time.Millisecond
+
+// ToolInputError is returned by handlers when Claude's input is unusable. Its
+// hints are passed back in the is_error tool result so Claude can correct the
+// next call instead of repeating the mistake.
+type ToolInputError struct {
+    Field       string   // Offending input field, if any
+    Problem     string   // What was wrong, e.g. "date is in the future"
+    ValidValues []string // Accepted values for enumerations
+    ValidRange  string   // Accepted range, e.g. "1-100" or "after 2020-01-01"
+    Hint        string   // Free-form guidance for the next attempt
+}
+
+func (e *ToolInputError) Error() string {
+    if e.Field != "" {
+        return fmt.Sprintf("invalid %s: %s", e.Field, e.Problem)
+    }
+    return "invalid input: " + e.Problem
+}
+
+// transientToolError marks an error as safe to retry
+type transientToolError struct {
+    err error
+}
+
+func (e *transientToolError) Error() string { return e.err.Error() }
+func (e *transientToolError) Unwrap() error { return e.err }
+
+// TransientToolError marks err as transient (a timeout, rate limit, or
+// temporarily unavailable backend) so ToolRetryMiddleware retries the call
+func TransientToolError(err error) error {
+    if err == nil {
+        return nil
+    }
+    return &transientToolError{err: err}
+}
+
+// IsTransientToolError reports whether err was marked with TransientToolError
+// or is a network timeout
+func IsTransientToolError(err error) bool {
+    var transient *transientToolError
+    if errors.As(err, &transient) {
+        return true
+    }
+    var netErr net.Error
+    return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// ToolRetryPolicy configures ToolRetryMiddleware
+type ToolRetryPolicy struct {
+    MaxRetries int                  // Retries after the first attempt
+    Backoff    time.Duration        // Delay before the first retry, doubled each time; defaults to 200ms
+    MaxBackoff time.Duration        // Upper bound on the delay; 0 means no bound
+    Retryable  func(err error) bool // Defaults to IsTransientToolError
+}
+
+// ToolRetryMiddleware retries tool calls that fail with a retryable error.
+// When the retries are exhausted the last error is returned and reported to
+// Claude as an is_error result. is_error results are never retried, since
+// they describe a problem Claude is expected to fix.
+func ToolRetryMiddleware(policy ToolRetryPolicy) ToolMiddleware {
+    if policy.Backoff <= 0 {
+        policy.Backoff = defaultToolRetryBackoff
+    }
+    if policy.Retryable == nil {
+        policy.Retryable = IsTransientToolError
+    }
+    return func(next ToolExecutor) ToolExecutor {
+        return func(ctx context.Context, call ToolUse) (ToolResult, error) {
+            delay := policy.Backoff
+            for attempt := 0; ; attempt++ {
+                result, err := next(ctx, call)
+                if err == nil || !policy.Retryable(err) {
+                    return result, err
+                }
+                if attempt >= policy.MaxRetries {
+                    if policy.MaxRetries > 0 {
+                        err = fmt.Errorf("failed after %d attempts: %w", attempt+1, err)
+                    }
+                    return result, err
+                }
+
+                logMessage("Tool '%s' failed transiently (attempt %d/%d), retrying in %s: %v",
+                    call.Name, attempt+1, policy.MaxRetries+1, delay, err)
+                timer := time.NewTimer(delay)
+                select {
+                case <-timer.C:
+                case <-ctx.Done():
+                    timer.Stop()
+                    return ToolResult{}, ctx.Err()
+                }
+                delay *= 2
+                if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+                    delay = policy.MaxBackoff
+                }
+            }
+        }
+    }
+}
+
+// toolErrorDetails is the structured part of an is_error tool result
+type toolErrorDetails struct {
+    Field       string   `json:"field,omitempty"`
+    ValidValues []string `json:"valid_values,omitempty"`
+    ValidRange  string   `json:"valid_range,omitempty"`
+    Violations  []string `json:"violations,omitempty"`
+    Retryable   bool     `json:"retryable"`
+    Hint        string   `json:"hint,omitempty"`
+}
+
+// toolErrorResult converts a failed tool execution into an is_error result.
+// Errors carrying input hints or schema violations get a JSON details line
+// after the message.
+func toolErrorResult(err error) ToolResult {
+    message := fmt.Sprintf("Error executing tool: %v", err)
+
+    var details *toolErrorDetails
+    var inputErr *ToolInputError
+    var schemaErr *SchemaValidationError
+    switch {
+    case errors.As(err, &inputErr):
+        details = &toolErrorDetails{
+            Field:       inputErr.Field,
+            ValidValues: inputErr.ValidValues,
+            ValidRange:  inputErr.ValidRange,
+            Hint:        inputErr.Hint,
+        }
+        if details.Hint == "" {
+            details.Hint = "Correct the input and call the tool again"
+        }
+    case errors.As(err, &schemaErr):
+        details = &toolErrorDetails{
+            Violations: schemaErr.Violations,
+            Hint:       "Call the tool again with input that matches its input_schema",
+        }
+    case IsTransientToolError(err):
+        details = &toolErrorDetails{
+            Retryable: true,
+            Hint:      "The failure is temporary; the same call may succeed later",
+        }
+    }
+
+    if details != nil {
+        if encoded, marshalErr := json.Marshal(details); marshalErr == nil {
+            message += "\n" + string(encoded)
+        }
+    }
+    return ToolResult{Content: message, IsError: true}
+}
@@ -3,6 +3,7 @@ package anthropic
 import (
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "regexp"
 )
@@ -11,14 +12,56 @@ var (
     // Tool name must match regex ^[a-zA-Z0-9_-]{1,64}$
     toolNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
 )
+// ChatWithTools runs a self-contained tool loop on the client's default session.
+// Unlike AChatWithTools it does not read or update the conversation history.
+// The response's Content is the whole exchange in order (each assistant turn
+// followed by its tool results) and Usage is summed over every iteration.
 func (c *AnthropicClient) ChatWithTools(
     ctx context.Context, 
     message string,
     params *MessageParams,
     handlers map[string]func(context.Context, json.RawMessage) (string, error),
+    opts ...RequestOption,
 ) (*AnthropicResponse, error) {
-    var finalAnswer string
-    var toolResults []MessageContent
+    return c.session.ChatWithTools(ctx, message, params, handlers, opts...)
+}
+
+// ChatWithTools runs a self-contained tool loop using the session's system prompt
+func (s *Session) ChatWithTools(
+    ctx context.Context, 
+    message string,
+    params *MessageParams,
+    handlers map[string]func(context.Context, json.RawMessage) (string, error),
+    opts ...RequestOption,
+) (*AnthropicResponse, error) {
+    params = s.client.resolveParams(params, opts)
+    message, err := s.client.checkInput(ctx, message)
+    if err != nil {
+        return nil, err
+    }
+    s.mu.Lock()
+    systemPrompt := s.resolveSystemPrompt(params)
+    s.mu.Unlock()
+
+    return s.client.idempotent(ctx, params.IdempotencyKey, func() (*AnthropicResponse, error) {
+        return s.client.chatWithTools(ctx, s, message, systemPrompt, params, handlers)
+    })
+}
+
+func (c *AnthropicClient) chatWithTools(
+    ctx context.Context, 
+    session *Session,
+    message string,
+    systemPrompt string,
+    params *MessageParams,
+    handlers map[string]func(context.Context, json.RawMessage) (string, error),
+) (*AnthropicResponse, error) {
+    params = c.resolveParams(params, nil)
+    ctx, cancel := callContext(ctx, params)
+    defer cancel()
+    tools := c.resolveTools(params)
+    maxIterations := c.toolIterationLimit(params)
+    base := newToolLoopRequest(params, systemPrompt, tools)
     
     // Initialize conversation with user message
     messages := []Message{{
@@ -29,120 +72,211 @@ func (c *AnthropicClient) ChatWithTools(
         }},
     }}
 
-    // Track all responses for final result
-    var allResponses []MessageContent
+    // The returned transcript holds every assistant block followed by the
+    // tool results it produced, in the order they occurred
+    var transcript []MessageContent
+    var totalUsage Usage
+    var lastResp *AnthropicResponse
+
+    // finish assembles the loop's result from the last response
+    finish := func(resp *AnthropicResponse, terminal *TerminalCall) *AnthropicResponse {
+        return &AnthropicResponse{
+            ID:           resp.ID,
+            Type:         resp.Type,
+            Role:         resp.Role,
+            Content:      transcript,
+            Model:        resp.Model,
+            StopReason:   resp.StopReason,
+            StopSequence: resp.StopSequence,
+            Usage:        totalUsage,
+            TerminalCall: terminal,
+        }
+    }
 
     // Main conversation loop
-    for {
+    for iterations := 0; ; iterations++ {
+        if iterations >= maxIterations {
+            logMessage("Tool interaction loop exceeded maximum iterations")
+            return nil, maxIterationsError(iterations, messages, lastResp, totalUsage)
+        }
+
         // Send request with current messages
-        resp, err := c.sendRequest(ctx, Request{
-            Model:       params.Model,
-            Messages:    messages,
-            MaxTokens:   params.MaxTokens,
-            Tools:       params.Tools,
-            ToolChoice:  &ToolChoice{
-                Type: ToolChoiceAuto, 
-                DisableParallel: true,
-            },
-        })
+        reqBody := base
+        reqBody.Messages = messages
+        if iterations > 0 {
+            reqBody.ToolChoice = followUpToolChoice(base.ToolChoice)
+        }
+        resp, err := c.sendRequest(ctx, session, reqBody)
         if err != nil {
             return nil, fmt.Errorf("request error: %w", err)
         }
+        lastResp = resp
+        totalUsage.Add(resp.Usage)
 
-        // Process response blocks
-        for _, block := range resp.Content {
-            switch block.Type {
-            case ContentTypeText:
-                finalAnswer += block.Text
-                allResponses = append(allResponses, block)
-                
-            case ContentTypeToolUse:
-                // Execute tool
-                handler, exists := handlers[block.Name]
-                if !exists {
-                    return nil, fmt.Errorf("no handler for tool: %s", block.Name)
-                }
-                
-                result, err := handler(ctx, block.Input)
-                if err != nil {
-                    return nil, fmt.Errorf("tool execution error: %w", err)
-                }
-                
-                // Store tool result
-                toolResults = append(toolResults, MessageContent{
-                    Type:      ContentTypeToolResult,
-                    ToolUseID: block.ID,
-                    Content:   result,
-                })
-                allResponses = append(allResponses, toolResults...)
-            }
+        // The assistant turn must precede its tool results in the conversation
+        if len(resp.Content) > 0 {
+            messages = append(messages, Message{Role: RoleAssistant, Content: resp.Content})
+            transcript = append(transcript, resp.Content...)
         }
 
-        // Add tool results to conversation if any
-        if len(toolResults) > 0 {
-            messages = append(messages, Message{
-                Role:    RoleUser,
-                Content: toolResults,
-            })
-            toolResults = nil // Reset for next iteration
+        // A paused server tool turn is resumed by resending the conversation
+        if resp.StopReason == StopReasonPauseTurn {
+            logMessage("Turn paused by server tool; continuing")
+            continue
         }
 
         // Return final answer with complete response history
         if resp.StopReason != StopReasonToolUse {
-            return &AnthropicResponse{
-                Content:    allResponses,
-                StopReason: resp.StopReason,
-                Usage:     resp.Usage,
-            }, nil
+            return finish(resp, nil), nil
+        }
+
+        // Execute each tool call in order
+        var toolResults []MessageContent
+        var terminal *TerminalCall
+        for _, call := range extractToolCalls(resp) {
+            c.fireToolCall(call)
+            result, err := c.invokeTool(ctx, call, tools, handlers)
+            if err != nil {
+                if errors.Is(err, ErrNoToolHandler) {
+                    return nil, err
+                }
+                logMessage("Tool execution failed: %v", err)
+                result = toolErrorResult(err)
+            }
+
+            resultBlock := result.toolResultBlock(call.ID)
+            c.fireToolResult(call, resultBlock)
+            toolResults = append(toolResults, resultBlock)
+
+            if terminal == nil && c.isTerminalTool(ctx, call.Name) {
+                terminal = &TerminalCall{ID: call.ID, Name: call.Name, Input: call.Input, Result: result}
+            }
+        }
+        if len(toolResults) == 0 {
+            return nil, fmt.Errorf("received tool_use stop reason but no valid tool calls found")
+        }
+
+        messages = append(messages, Message{Role: RoleUser, Content: toolResults})
+        transcript = append(transcript, toolResults...)
+
+        // A terminal tool ends the loop without another model turn
+        if terminal != nil {
+            logMessage("Terminal tool '%s' called - ending tool interaction", terminal.Name)
+            return finish(resp, terminal), nil
+        }
+        if params.StopWhen != nil && params.StopWhen(resp) {
+            logMessage("Stop condition met - ending tool interaction")
+            return finish(resp, nil), nil
         }
     }
 }
-// ChatWithTools implements the core tool interaction loop according to Anthropic's
+
+// AChatWithTools runs the tool interaction loop on the client's default session
+func (c *AnthropicClient) AChatWithTools(
+    ctx context.Context,
+    message string,
+    params *MessageParams,
+    handlers map[string]func(context.Context, json.RawMessage) (string, error),
+    opts ...RequestOption,
+) (*AnthropicResponse, error) {
+    return c.session.AChatWithTools(ctx, message, params, handlers, opts...)
+}
+
+// AChatWithTools implements the core tool interaction loop according to Anthropic's
 // documented flow:
 // 1. Provide Claude with tools and user prompt
 // 2. Claude decides to use tool (returns stop_reason: tool_use)
 // 3. Extract tool input, run code, return results
 // 4. Claude uses tool result to formulate final response
-func (c *AnthropicClient) AChatWithTools(
+// The exchange is recorded in the session's conversation history.
+func (s *Session) AChatWithTools(
+    ctx context.Context,
+    message string,
+    params *MessageParams,
+    handlers map[string]func(context.Context, json.RawMessage) (string, error),
+    opts ...RequestOption,
+) (*AnthropicResponse, error) {
+    return s.toolChat(ctx, message, params, handlers, nil, opts)
+}
+
+// toolChat runs AChatWithTools, streaming each model turn to stream when it
+// is set
+func (s *Session) toolChat(
     ctx context.Context,
     message string,
     params *MessageParams,
     handlers map[string]func(context.Context, json.RawMessage) (string, error),
+    stream StreamHandler,
+    opts []RequestOption,
 ) (*AnthropicResponse, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    params = s.client.resolveParams(params, opts)
+    ctx, cancel := callContext(ctx, params)
+    defer cancel()
+    s.stream = stream
+    defer func() { s.stream = nil }()
+
     logMessage("Starting tool-enabled chat interaction")
     logJSON("Initial message", message)
     logJSON("Tool parameters", params)
-    
-       // ADD THIS SECTION
-    // Set default tool_choice if not provided
-    if params.Tools != nil && len(params.Tools) > 0 && params.ToolChoice == nil {
-        params.ToolChoice = &ToolChoice{Type: ToolChoiceAuto}
-    }
+    tools := s.client.resolveTools(params)
+
+    // The loop works on its own request state; the caller's params may be
+    // shared and are never modified
+    base := newToolLoopRequest(params, s.resolveSystemPrompt(params), tools)
 
-    // Validate tool configuration before proceeding
-    if err := validateToolParams(params); err != nil {
+    // Validate tool configuration before proceeding, with the defaulted tool choice
+    effective := *params
+    effective.ToolChoice = base.ToolChoice
+    if err := validateToolParams(&effective); err != nil {
         logMessage("Tool parameter validation failed: %v", err)
         return nil, fmt.Errorf("invalid tool parameters: %w", err)
     }
 
+    message, err := s.client.checkInput(ctx, message)
+    if err != nil {
+        return nil, err
+    }
+    history := s.guardHistory()
+    var snapshot []Message
+    if stream != nil {
+        snapshot = append([]Message{}, s.conversation...)
+    }
+    resp, err := s.runToolLoop(ctx, message, params, handlers, base, tools)
+    s.restoreOnBlock(history, err)
+    if stream != nil {
+        s.restoreOnCancel(ctx, snapshot, err)
+    }
+    return resp, err
+}
+
+// runToolLoop is the body of AChatWithTools. Callers must hold s.mu.
+func (s *Session) runToolLoop(
+    ctx context.Context,
+    message string,
+    params *MessageParams,
+    handlers map[string]func(context.Context, json.RawMessage) (string, error),
+    base Request,
+    tools []Tool,
+) (*AnthropicResponse, error) {
     // Initialize conversation with user's message
     initialContent := []MessageContent{{
         Type: ContentTypeText,
         Text: message,
     }}
-    c.addMessageToConversation(RoleUser, initialContent)
-    logJSON("Initial conversation state", c.conversation)
+    s.addMessageToConversation(RoleUser, initialContent)
+    logJSON("Initial conversation state", s.conversation)
 
     // Configure iteration limits to prevent infinite loops
-    const maxIterations = 10
+    maxIterations := s.client.toolIterationLimit(params)
     iterations := 0
+    var totalUsage Usage
+    var lastResp *AnthropicResponse
 
     // Store original tool choice for later reset if needed
-    originalToolChoice := params.ToolChoice
-    disableParallel := false
-    if params.ToolChoice != nil && params.ToolChoice.DisableParallel {
-        disableParallel = true
-    }
+    originalToolChoice := base.ToolChoice
+    toolChoice := originalToolChoice
 
     // Main tool interaction loop
     for {
@@ -150,35 +284,36 @@ func (c *AnthropicClient) AChatWithTools(
         
         if iterations >= maxIterations {
             logMessage("Tool interaction loop exceeded maximum iterations")
-            return nil, fmt.Errorf("exceeded maximum number of tool call iterations (%d)", maxIterations)
+            return nil, maxIterationsError(iterations, s.conversation, lastResp, totalUsage)
         }
 
         // Prepare request with current conversation state
-        reqBody := Request{
-            Model:       params.Model,
-            System:      params.System,
-            Messages:    c.conversation,
-            MaxTokens:   params.MaxTokens,
-            Temperature: params.Temperature,
-            TopP:        params.TopP,
-            TopK:        params.TopK,
-            Tools:       params.Tools,
-            ToolChoice:  params.ToolChoice,
-        }
+        reqBody := base
+        reqBody.Messages = s.conversation
+        reqBody.ToolChoice = toolChoice
         logJSON("Outgoing request for tool interaction", reqBody)
 
         // Get assistant's response
-        resp, err := c.sendRequest(ctx, reqBody)
+        resp, err := s.sendHistory(ctx, reqBody)
         if err != nil {
             logMessage("Failed to get assistant response: %v", err)
             return nil, fmt.Errorf("chat request error (iteration %d): %w", iterations, err)
         }
         logJSON("Received assistant response", resp)
+        lastResp = resp
+        totalUsage.Add(resp.Usage)
 
         // Process any initial text or chain-of-thought from Claude
         if len(resp.Content) > 0 {
-            c.addMessageToConversation(RoleAssistant, resp.Content)
-            logJSON("Updated conversation with assistant response", c.conversation)
+            s.addMessageToConversation(RoleAssistant, resp.Content)
+            logJSON("Updated conversation with assistant response", s.conversation)
+        }
+
+        // A paused server tool turn is resumed by resending the conversation
+        if resp.StopReason == StopReasonPauseTurn {
+            logMessage("Turn paused by server tool; continuing")
+            iterations++
+            continue
         }
 
         // If not a tool use response, this is the final response
@@ -197,63 +332,66 @@ func (c *AnthropicClient) AChatWithTools(
             return nil, fmt.Errorf("received tool_use stop reason but no valid tool calls found")
         }
 
-        // Enforce single tool use if parallel is disabled
-        if disableParallel && len(toolCalls) > 1 {
-            logMessage("Warning: Multiple tool calls received with parallel disabled, using only first call")
-            toolCalls = toolCalls[:1]
-        }
-
         // Process each tool call and collect results
         var resultContents []MessageContent
+        var terminal *TerminalCall
         for _, call := range toolCalls {
             logMessage("Processing tool call - Tool: %s, ID: %s", call.Name, call.ID)
             logJSON("Tool call input parameters", string(call.Input))
 
-            // Find the appropriate handler for this tool
-            handler, exists := handlers[call.Name]
-            if !exists {
-                logMessage("Error: No handler found for tool '%s'", call.Name)
-                return nil, fmt.Errorf("no handler for tool: %s", call.Name)
-            }
-
             // Execute the tool and handle any errors
             logMessage("Executing tool '%s'", call.Name)
-            result, err := handler(ctx, call.Input)
+            s.client.fireToolCall(call)
+            result, err := s.client.invokeTool(ctx, call, tools, handlers)
             if err != nil {
+                if errors.Is(err, ErrNoToolHandler) {
+                    logMessage("Error: No handler found for tool '%s'", call.Name)
+                    return nil, err
+                }
                 logMessage("Tool execution failed: %v", err)
                 // Return error result according to Anthropic's format
-                resultContents = append(resultContents, MessageContent{
-                    Type:      ContentTypeToolResult,
-                    ToolUseID: call.ID,
-                    Content:   fmt.Sprintf("Error executing tool: %v", err),
-                    IsError:   true,
-                })
-                continue
+                result = toolErrorResult(err)
+            } else {
+                logMessage("Tool execution successful")
+                logJSON("Tool execution result", result)
             }
             
-            logMessage("Tool execution successful")
-            logJSON("Tool execution result", result)
-            
-            // Record successful tool execution result
-            resultContents = append(resultContents, MessageContent{
-                Type:      ContentTypeToolResult,
-                ToolUseID: call.ID,
-                Content:   result,
-            })
+            // Record tool execution result
+            resultBlock := result.toolResultBlock(call.ID)
+            s.client.fireToolResult(call, resultBlock)
+            if s.stream != nil {
+                s.stream(StreamEvent{Type: StreamToolResult, Index: len(resultContents), ContentBlock: &resultBlock})
+            }
+            resultContents = append(resultContents, resultBlock)
+
+            if terminal == nil && s.client.isTerminalTool(ctx, call.Name) {
+                terminal = &TerminalCall{ID: call.ID, Name: call.Name, Input: call.Input, Result: result}
+            }
         }
 
         // Add tool results to conversation history as user message
-        c.addMessageToConversation(RoleUser, resultContents)
-        logJSON("Updated conversation with tool results", c.conversation)
+        s.addMessageToConversation(RoleUser, resultContents)
+        logJSON("Updated conversation with tool results", s.conversation)
+
+        // A terminal tool ends the loop without another model turn
+        if terminal != nil {
+            logMessage("Terminal tool '%s' called - ending tool interaction", terminal.Name)
+            resp.TerminalCall = terminal
+            return resp, nil
+        }
+        if params.StopWhen != nil && params.StopWhen(resp) {
+            logMessage("Stop condition met - ending tool interaction")
+            return resp, nil
+        }
 
         // After first iteration:
         // 1. Clear tool choice to allow Claude to formulate final response
         // 2. Reset to original tool choice for subsequent iterations if needed
         if iterations == 0 {
             logMessage("Clearing tool choice after first iteration")
-            params.ToolChoice = nil
+            toolChoice = followUpToolChoice(originalToolChoice)
         } else {
-            params.ToolChoice = originalToolChoice
+            toolChoice = originalToolChoice
         }
         
         iterations++
@@ -308,20 +446,8 @@ func validateToolParams(params *MessageParams) error {
         
         // Validate each tool definition
         for _, tool := range params.Tools {
-            // Validate tool name format
-            if !toolNameRegex.MatchString(tool.Name) {
-                return fmt.Errorf("invalid tool name format: %s - must match %s", 
-                    tool.Name, toolNameRegex.String())
-            }
-            
-            // Validate tool has description
-            if tool.Description == "" {
-                return fmt.Errorf("tool %s missing required description", tool.Name)
-            }
-            
-            // Validate input schema
-            if err := validateInputSchema(tool.InputSchema); err != nil {
-                return fmt.Errorf("invalid input schema for tool %s: %w", tool.Name, err)
+            if err := validateToolDefinition(tool); err != nil {
+                return err
             }
         }
 
@@ -335,6 +461,34 @@ func validateToolParams(params *MessageParams) error {
     return nil
 }
 
+// validateToolDefinition checks a single tool's name, description, and input schema
+func validateToolDefinition(tool Tool) error {
+    // Anthropic-defined tools only carry a type and a name
+    if tool.hasBuiltinType() {
+        if tool.Name == "" {
+            return fmt.Errorf("tool of type %s missing required name", tool.Type)
+        }
+        return nil
+    }
+
+    // Validate tool name format
+    if !toolNameRegex.MatchString(tool.Name) {
+        return fmt.Errorf("invalid tool name format: %s - must match %s", 
+            tool.Name, toolNameRegex.String())
+    }
+    
+    // Validate tool has description
+    if tool.Description == "" {
+        return fmt.Errorf("tool %s missing required description", tool.Name)
+    }
+    
+    // Validate input schema
+    if err := validateInputSchema(tool.InputSchema); err != nil {
+        return fmt.Errorf("invalid input schema for tool %s: %w", tool.Name, err)
+    }
+    return nil
+}
+
 // validateToolChoice ensures the tool choice configuration is valid
 func validateToolChoice(choice *ToolChoice) error {
     if choice == nil {
@@ -343,7 +497,12 @@ func validateToolChoice(choice *ToolChoice) error {
 
     // Validate choice type
     switch choice.Type {
-    case ToolChoiceAuto, ToolChoiceNone:
+    case ToolChoiceAuto:
+        return nil
+    case ToolChoiceNone:
+        if choice.DisableParallel {
+            return fmt.Errorf("disable_parallel_tool_use cannot be set when type is 'none'")
+        }
         return nil
     case ToolChoiceTool:
         if choice.Name == "" {
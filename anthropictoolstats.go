@@ -0,0 +1,140 @@
+package anthropic
+
+import (
+    "sort"
+    "sync"
+    "time"
+)
+
+// toolLatencySamples bounds the latency samples kept per tool for percentiles
+const toolLatencySamples = 1024
+
+// ToolStats summarizes the executions of one tool
+type ToolStats struct {
+    Name           string        `json:"name"`
+    Calls          int           `json:"calls"`
+    Errors         int           `json:"errors"` // Handler errors and is_error results
+    ErrorRate      float64       `json:"error_rate"`
+    P50Latency     time.Duration `json:"p50_latency"`
+    P95Latency     time.Duration `json:"p95_latency"`
+    TotalLatency   time.Duration `json:"total_latency"`
+    AvgResultBytes float64       `json:"avg_result_bytes"`
+}
+
+// ToolStats returns per-tool execution statistics gathered since the client
+// was created or last reset, sorted by total latency so the tools that
+// dominate a run come first. Percentiles cover the most recent executions.
+func (c *AnthropicClient) ToolStats() []ToolStats {
+    return c.toolStats.report()
+}
+
+// ResetToolStats discards the collected tool statistics
+func (c *AnthropicClient) ResetToolStats() {
+    c.toolStats.reset()
+}
+
+// toolStatsTracker accumulates execution metrics per tool
+type toolStatsTracker struct {
+    mu     sync.Mutex
+    byTool map[string]*toolStatsEntry
+}
+
+type toolStatsEntry struct {
+    calls        int
+    errors       int
+    totalLatency time.Duration
+    resultBytes  int64
+    samples      []time.Duration // Ring buffer of recent latencies
+    next         int
+}
+
+func (t *toolStatsTracker) record(name string, latency time.Duration, result ToolResult, err error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.byTool == nil {
+        t.byTool = make(map[string]*toolStatsEntry)
+    }
+    entry, exists := t.byTool[name]
+    if !exists {
+        entry = &toolStatsEntry{}
+        t.byTool[name] = entry
+    }
+
+    entry.calls++
+    if err != nil || result.IsError {
+        entry.errors++
+    }
+    entry.totalLatency += latency
+    entry.resultBytes += int64(resultSize(result))
+    if len(entry.samples) < toolLatencySamples {
+        entry.samples = append(entry.samples, latency)
+    } else {
+        entry.samples[entry.next] = latency
+        entry.next = (entry.next + 1) % toolLatencySamples
+    }
+}
+
+func (t *toolStatsTracker) report() []ToolStats {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    stats := make([]ToolStats, 0, len(t.byTool))
+    for name, entry := range t.byTool {
+        sorted := make([]time.Duration, len(entry.samples))
+        copy(sorted, entry.samples)
+        sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+        stats = append(stats, ToolStats{
+            Name:           name,
+            Calls:          entry.calls,
+            Errors:         entry.errors,
+            ErrorRate:      float64(entry.errors) / float64(entry.calls),
+            P50Latency:     percentile(sorted, 0.50),
+            P95Latency:     percentile(sorted, 0.95),
+            TotalLatency:   entry.totalLatency,
+            AvgResultBytes: float64(entry.resultBytes) / float64(entry.calls),
+        })
+    }
+    sort.Slice(stats, func(i, j int) bool {
+        if stats[i].TotalLatency != stats[j].TotalLatency {
+            return stats[i].TotalLatency > stats[j].TotalLatency
+        }
+        return stats[i].Name < stats[j].Name
+    })
+    return stats
+}
+
+func (t *toolStatsTracker) reset() {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    t.byTool = nil
+}
+
+// percentile returns the nearest-rank percentile of sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+    if len(sorted) == 0 {
+        return 0
+    }
+    rank := int(p*float64(len(sorted))+0.5) - 1
+    if rank < 0 {
+        rank = 0
+    }
+    if rank >= len(sorted) {
+        rank = len(sorted) - 1
+    }
+    return sorted[rank]
+}
+
+// resultSize approximates the size of a tool result sent back to the model
+func resultSize(result ToolResult) int {
+    size := len(result.Content)
+    for _, block := range result.Blocks {
+        size += len(block.Text)
+        if block.Source != nil {
+            size += len(block.Source.Data)
+        }
+    }
+    return size
+}
@@ -0,0 +1,68 @@
+package anthropic
+
+import (
+    "net/http"
+    "time"
+)
+
+// WithMaxIdleConnsPerHost sets how many idle connections to the API are kept
+// for reuse. Go's default of 2 makes services with many concurrent requests
+// open and close a connection for most calls.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+    return func(c *AnthropicClient) {
+        if n <= 0 {
+            return
+        }
+        c.tuneTransport(func(t *http.Transport) {
+            t.MaxIdleConnsPerHost = n
+            if t.MaxIdleConns > 0 && t.MaxIdleConns < n {
+                t.MaxIdleConns = n
+            }
+        })
+    }
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept before it is
+// closed
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+    return func(c *AnthropicClient) {
+        if d <= 0 {
+            return
+        }
+        c.tuneTransport(func(t *http.Transport) {
+            t.IdleConnTimeout = d
+        })
+    }
+}
+
+// WithForceHTTP2 makes the transport attempt HTTP/2 even when it has a custom
+// TLS config or dialer, which otherwise disables HTTP/2 in Go's transport
+func WithForceHTTP2() ClientOption {
+    return func(c *AnthropicClient) {
+        c.tuneTransport(func(t *http.Transport) {
+            t.ForceAttemptHTTP2 = true
+        })
+    }
+}
+
+// tuneTransport applies fn to a copy of the client's transport, so clients
+// sharing the HTTP client (see With) are not affected. Clients whose
+// transport is not an *http.Transport are left unchanged.
+func (c *AnthropicClient) tuneTransport(fn func(*http.Transport)) {
+    var base *http.Transport
+    switch t := c.httpClient.Transport.(type) {
+    case nil:
+        base = http.DefaultTransport.(*http.Transport)
+    case *http.Transport:
+        base = t
+    default:
+        logMessage("Ignoring connection option: custom HTTP transport %T", t)
+        return
+    }
+
+    transport := base.Clone()
+    fn(transport)
+    client := *c.httpClient
+    client.Transport = transport
+    c.httpClient = &client
+}
@@ -3,51 +3,40 @@ package anthropic
 import (
     "encoding/json"
     "net/http"
+    "time"
 )
 
 // Core API configuration constants
 const (
     defaultAPIEndpoint = "https://api.anthropic.com/v1/messages"
+    defaultRequestTimeout = 10 * time.Minute
     defaultModel      = "claude-3-5-sonnet-20241022"
-    defaultSystemPrompt = `You are Mr. PeeBody. You are an expert search agent. If the user requests research, you are to search the internet if you do not have the information available. You have access to the following tools:
+    defaultSystemPrompt = `You are a helpful assistant.{{if .Tools}} You have access to the following tools:
 
-1. 'get_weather'
-   - Gets current weather for a specified location
-   - Requires location parameter (e.g., city, country, region)
-   - Optional unit parameter (celsius or fahrenheit)
-   - Returns temperature, conditions, and humidity in both Celsius and Fahrenheit
+{{toolList .Tools}}
 
-2. 'get_stock_price'
-   - Gets current stock price for a given stock symbol
-   - Requires stock symbol parameter (e.g., AAPL)
-
-3. 'SearchInternet'
-   - Performs a general internet search for information
-   - Requires a search query parameter
-
-4. 'DeepSearch'
-   - Performs a more comprehensive, detailed search analysis
-   - Requires a search query parameter
-   - Best used when detailed analysis is needed
-
-Any request that you get from the user, you are to develop a step by step plan to execute. Once Developed, you are to review and execute.`
+Use a tool when it would help answer the request.{{end}}`
 )
 
 // Role and content type constants
 const (
-    RoleSystem    = "system"
-    RoleUser      = "user"
-    RoleAssistant = "assistant"
+    RoleSystem    Role = "system"
+    RoleUser      Role = "user"
+    RoleAssistant Role = "assistant"
     
-    ContentTypeText       = "text"
-    ContentTypeToolUse    = "tool_use"
-    ContentTypeToolResult = "tool_result"
-    ContentTypeThinking   = "thinking"  
+    ContentTypeText       ContentType = "text"
+    ContentTypeToolUse    ContentType = "tool_use"
+    ContentTypeToolResult ContentType = "tool_result"
+    ContentTypeThinking   ContentType = "thinking"  
+    ContentTypeImage      ContentType = "image"
+    ContentTypeDocument   ContentType = "document"
+    ContentTypeMCPToolUse    ContentType = "mcp_tool_use"     // Remote MCP tool call made server-side
+    ContentTypeMCPToolResult ContentType = "mcp_tool_result"  // Result of a remote MCP tool call
     
-    StopReasonToolUse      = "tool_use"
-    StopReasonEndTurn      = "end_turn"
-    StopReasonMaxTokens    = "max_tokens"
-    StopReasonStopSequence = "stop_sequence"  
+    StopReasonToolUse      StopReason = "tool_use"
+    StopReasonEndTurn      StopReason = "end_turn"
+    StopReasonMaxTokens    StopReason = "max_tokens"
+    StopReasonStopSequence StopReason = "stop_sequence"  
     
     ToolChoiceAuto = "auto"
     ToolChoiceNone = "none"
@@ -65,22 +54,56 @@ type ClientOption func(*AnthropicClient)
 // AnthropicClient handles communication with the Anthropic API
 type AnthropicClient struct {
     apiKey          string
+    apiKeyProvider  APIKeyProvider          // Fetches apiKey per request when set
+    tokenSource     TokenSource             // Bearer token auth, replacing x-api-key when set
     defaultParams   MessageParams
     httpClient      *http.Client
-    conversation    []Message
+    session         *Session  // Default session backing the client-level conversation methods
     maxConvLength   int
     systemPrompt    string    // System prompt that defines assistant behavior
+    systemTemplate  *SystemPromptTemplate   // Renders systemPrompt per request when set
+    hooks           []Hooks   // Conversation event observers
+    titleModel      string    // Model used for conversation titling
+    pricing         map[string]ModelPricing // Per-model pricing overrides
+    models          map[string]ModelCapabilities // Per-model capability overrides
+    strictValidation bool                   // Reject rather than clamp an oversized MaxTokens
+    repairConversation bool                 // Merge same-role messages before each request
+    streamIdleTimeout time.Duration         // Aborts streams that go quiet
+    requestTimeout    time.Duration         // Bounds each HTTP request
+    compressAbove     int                   // Gzip request bodies of at least this size; 0 disables
+    inflight          inflightTracker       // Work Close waits for
+    idempotencyWindow time.Duration         // How long keyed responses are remembered
+    idempotency       idempotencyTracker    // Calls made with idempotency keys
+    usage           usageTracker            // Cumulative usage across all sessions
+    budget          Budget                  // Spending limit across all sessions
+    usageCallbacks  []func(UsageEvent)      // Per-request metering callbacks
+    registry        *ToolRegistry           // Default tools for the tool loops
+    toolMiddleware  []ToolMiddleware        // Wrappers applied to every tool execution
+    toolStats       toolStatsTracker        // Per-tool execution metrics
+    maxToolIterations int                   // Default tool loop iteration limit
+    maxContinuations  int                   // Default max_tokens continuation limit
+    overflowStrategy  OverflowStrategy      // Recovery from prompt-too-long errors
+    contextWindow     int                   // Overrides the model's context window
+    contextWarnAt     float64               // Percent of the window that triggers contextWarn
+    contextWarn       func(*Session, ContextUsage)
+    rateLimiter     *rateLimiter            // Request rate limit, shared with derived clients
+    cache           *responseCache          // Responses to identical requests, shared with derived clients
+    ownCredentials  bool                    // Credentials were set by an option passed to With
+    audit           *AuditConfig            // Request/response transcript, when enabled
+    piiRedactor     *PIIRedactor            // Masks personal data in outgoing messages
+    inputGuardrails  []Guardrail            // Checks prompts before they are sent
+    outputGuardrails []Guardrail            // Checks response text before it is returned
 }
 
 // Message represents a single message in the conversation
 type Message struct {
-    Role    string           `json:"role"`    
+    Role    Role             `json:"role"`    
     Content []MessageContent `json:"content"` 
 }
 
 // MessageContent represents different types of content within a message
 type MessageContent struct {
-    Type       string          `json:"type"`               
+    Type       ContentType     `json:"type"`               
     Text       string          `json:"text,omitempty"`     
     ID         string          `json:"id,omitempty"`       
     Name       string          `json:"name,omitempty"`     
@@ -88,6 +111,41 @@ type MessageContent struct {
     ToolUseID  string          `json:"tool_use_id,omitempty"`  
     Content    string          `json:"content,omitempty"`      
     IsError    bool            `json:"is_error,omitempty"`     
+    Source     *ImageSource    `json:"source,omitempty"`       // Data for image and document blocks
+    ServerName string          `json:"server_name,omitempty"`  // MCP server for mcp_tool_use blocks
+    Citations  []Citation      `json:"citations,omitempty"`    // Sources backing a text block
+    Thinking   string          `json:"thinking,omitempty"`     // Extended thinking text
+    Signature  string          `json:"signature,omitempty"`    // Verifies thinking blocks sent back to the API
+    CacheControl *CacheControl `json:"cache_control,omitempty"`  // Marks the end of a cacheable prompt prefix
+
+    // Web search result fields; WebSearchToolResultBlock is the typed form
+    URL              string `json:"url,omitempty"`
+    Title            string `json:"title,omitempty"`
+    EncryptedContent string `json:"encrypted_content,omitempty"`
+    PageAge          string `json:"page_age,omitempty"`
+    ErrorCode        string `json:"error_code,omitempty"`
+
+    // Code execution result fields; CodeExecutionToolResultBlock is the typed form
+    Stdout     string `json:"stdout,omitempty"`
+    Stderr     string `json:"stderr,omitempty"`
+    ReturnCode *int   `json:"return_code,omitempty"`
+    FileID     string `json:"file_id,omitempty"`
+
+    // ContentBlocks holds the array form of tool_result content (text and image
+    // blocks). When set it is sent as "content" in place of the Content string.
+    ContentBlocks []MessageContent `json:"-"`
+
+    // contentObject records that "content" arrived as a single object (e.g. a
+    // web search error) so it is sent back in the same shape
+    contentObject bool
+}
+
+// ImageSource describes the data behind an image or document content block
+type ImageSource struct {
+    Type      string `json:"type"`                  // "base64", "url", or "text" for plain-text documents
+    MediaType string `json:"media_type,omitempty"`  // e.g. "image/png"; required for base64
+    Data      string `json:"data,omitempty"`        // Base64-encoded image bytes
+    URL       string `json:"url,omitempty"`
 }
 
 // ToolUse represents a tool call from the assistant
@@ -101,13 +159,44 @@ type ToolUse struct {
 type MessageParams struct {
     Model       string                 `json:"model"`
     MaxTokens   int                    `json:"max_tokens"`
-    Temperature float64                `json:"temperature,omitempty"`
-    TopP        float64                `json:"top_p,omitempty"`
-    TopK        int                    `json:"top_k,omitempty"`
+    Temperature *float64               `json:"temperature,omitempty"` // nil uses the API default; see Float
+    TopP        *float64               `json:"top_p,omitempty"`
+    TopK        *int                   `json:"top_k,omitempty"`
+    StopSequences []string             `json:"stop_sequences,omitempty"` // Custom strings that end generation
     Metadata    map[string]interface{} `json:"metadata,omitempty"`
     System      string                 `json:"system,omitempty"`
+    SystemBlocks []MessageContent      `json:"-"` // Array form of system; replaces System when set
     Tools       []Tool                 `json:"tools,omitempty"`
     ToolChoice  *ToolChoice            `json:"tool_choice,omitempty"`
+    MCPServers  []MCPServer            `json:"mcp_servers,omitempty"` // Remote MCP servers (beta)
+
+    // MaxToolIterations limits the model turns of a tool loop; 0 uses the
+    // client default (see WithMaxToolIterations)
+    MaxToolIterations int `json:"-"`
+
+    // StopWhen ends a tool loop early: it is called after each model turn's
+    // tools have run, and returning true returns that turn's response
+    StopWhen func(resp *AnthropicResponse) bool `json:"-"`
+
+    // MaxOutputRepairs limits the correction turns ChatJSON and ExtractInto
+    // send when structured output fails validation; 0 means 2, negative disables
+    MaxOutputRepairs int `json:"-"`
+
+    // MaxContinuations limits how often an answer cut off at max_tokens is
+    // continued; 0 uses the client default (see WithAutoContinue), negative disables
+    MaxContinuations int `json:"-"`
+
+    // Timeout bounds the whole call, including tool executions and retries;
+    // 0 leaves only the context's deadline (see WithTimeout)
+    Timeout time.Duration `json:"-"`
+
+    // Priority decides which waiting requests get rate-limit capacity first
+    // (see WithPriority)
+    Priority Priority `json:"-"`
+
+    // IdempotencyKey identifies the logical request so duplicates return the
+    // first call's response (see WithIdempotencyKey)
+    IdempotencyKey string `json:"-"`
 }
 
 // Request represents the complete structure sent to the Anthropic API
@@ -115,19 +204,30 @@ type Request struct {
     Model       string      `json:"model"`
     Messages    []Message   `json:"messages"`
     MaxTokens   int         `json:"max_tokens"`
-    Temperature float64     `json:"temperature,omitempty"`
-    TopP        float64     `json:"top_p,omitempty"`
-    TopK        int         `json:"top_k,omitempty"`
+    Temperature *float64    `json:"temperature,omitempty"`
+    TopP        *float64    `json:"top_p,omitempty"`
+    TopK        *int        `json:"top_k,omitempty"`
+    StopSequences []string  `json:"stop_sequences,omitempty"`
     System      string      `json:"system,omitempty"`
+    SystemBlocks []MessageContent `json:"-"` // Sent as "system" in place of System when set
     Tools       []Tool      `json:"tools,omitempty"`
     ToolChoice  *ToolChoice `json:"tool_choice,omitempty"`
+    MCPServers  []MCPServer `json:"mcp_servers,omitempty"`
+    Stream      bool        `json:"stream,omitempty"`
 }
 
 // Tool-related types
 type Tool struct {
+    Type         string      `json:"type,omitempty"`  // Empty for custom tools; set for Anthropic-defined tools
     Name         string      `json:"name"`
     Description  string      `json:"description"`
     InputSchema  InputSchema `json:"input_schema"`
+
+    // Web search settings, used when Type is ToolTypeWebSearch
+    MaxUses        int           `json:"max_uses,omitempty"`
+    AllowedDomains []string      `json:"allowed_domains,omitempty"`
+    BlockedDomains []string      `json:"blocked_domains,omitempty"`
+    UserLocation   *UserLocation `json:"user_location,omitempty"`
 }
 
 type InputSchema struct {
@@ -137,9 +237,12 @@ type InputSchema struct {
 }
 
 type Property struct {
-    Type        string   `json:"type"`
-    Description string   `json:"description"`
-    Enum        []string `json:"enum,omitempty"`
+    Type        string              `json:"type,omitempty"`
+    Description string              `json:"description,omitempty"`
+    Enum        []string            `json:"enum,omitempty"`
+    Items       *Property           `json:"items,omitempty"`      // Element schema when Type is "array"
+    Properties  map[string]Property `json:"properties,omitempty"` // Nested fields when Type is "object"
+    Required    []string            `json:"required,omitempty"`   // Required nested fields when Type is "object"
 }
 
 /*
@@ -155,78 +258,48 @@ type AnthropicResponse struct {
     Role        string           `json:"role"`
     Content     []MessageContent `json:"content"`
     Model       string           `json:"model"`
-    StopReason  string           `json:"stop_reason"`
+    StopReason  StopReason       `json:"stop_reason"`
+    StopSequence string          `json:"stop_sequence,omitempty"` // Which stop sequence fired, for StopReasonStopSequence
     Usage       Usage            `json:"usage"`
+
+    // TerminalCall is set when a tool loop ended because Claude called a
+    // terminal tool (see ToolRegistry.MarkTerminal)
+    TerminalCall *TerminalCall `json:"-"`
+}
+
+// TerminalCall is the terminal tool call that ended a tool loop
+type TerminalCall struct {
+    ID     string
+    Name   string
+    Input  json.RawMessage // The structured output Claude submitted
+    Result ToolResult      // What the tool's handler returned
+}
+
+// Decode unmarshals the terminal call's input into v
+func (t *TerminalCall) Decode(v interface{}) error {
+    return json.Unmarshal(t.Input, v)
 }
 
 type Usage struct {
-    InputTokens  int `json:"input_tokens"`
-    OutputTokens int `json:"output_tokens"`
-}
-
-// GetDefaultTools returns the default set of tools available to Mr. PeeBody
-func GetDefaultTools() []Tool {
-    return []Tool{
-        {
-            Name:        "get_weather",
-            Description: "Gets current weather for a specified location",
-            InputSchema: InputSchema{
-                Type: "object",
-                Properties: map[string]Property{
-                    "location": {
-                        Type:        "string",
-                        Description: "Location (city, country, or region)",
-                    },
-                    "unit": {
-                        Type:        "string",
-                        Description: "Temperature unit (celsius or fahrenheit)",
-                        Enum:        []string{"celsius", "fahrenheit"},
-                    },
-                },
-                Required: []string{"location"},
-            },
-        },
-        {
-            Name:        "get_stock_price",
-            Description: "Gets current stock price for a given stock symbol",
-            InputSchema: InputSchema{
-                Type: "object",
-                Properties: map[string]Property{
-                    "symbol": {
-                        Type:        "string",
-                        Description: "Stock symbol (e.g., AAPL)",
-                    },
-                },
-                Required: []string{"symbol"},
-            },
-        },
-        {
-            Name:        "SearchInternet",
-            Description: "Performs a general internet search for information",
-            InputSchema: InputSchema{
-                Type: "object",
-                Properties: map[string]Property{
-                    "query": {
-                        Type:        "string",
-                        Description: "Search query",
-                    },
-                },
-                Required: []string{"query"},
-            },
-        },
-        {
-            Name:        "DeepSearch",
-            Description: "Performs a more comprehensive, detailed search analysis",
-            InputSchema: InputSchema{
-                Type: "object",
-                Properties: map[string]Property{
-                    "query": {
-                        Type:        "string",
-                        Description: "Search query for detailed analysis",
-                    },
-                },
-                Required: []string{"query"},
-            },
-        },
+    InputTokens              int `json:"input_tokens"`
+    OutputTokens             int `json:"output_tokens"`
+    CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+    CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+    ServerToolUse            *ServerToolUsage `json:"server_tool_use,omitempty"`
+}
+
+// Add accumulates another Usage into u
+func (u *Usage) Add(other Usage) {
+    u.InputTokens += other.InputTokens
+    u.OutputTokens += other.OutputTokens
+    u.CacheCreationInputTokens += other.CacheCreationInputTokens
+    u.CacheReadInputTokens += other.CacheReadInputTokens
+    if other.ServerToolUse != nil {
+        total := ServerToolUsage{}
+        if u.ServerToolUse != nil {
+            total = *u.ServerToolUse
+        }
+        total.WebSearchRequests += other.ServerToolUse.WebSearchRequests
+        u.ServerToolUse = &total
     }
 }
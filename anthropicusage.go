@@ -0,0 +1,220 @@
+package anthropic
+
+import (
+    "strings"
+    "sync"
+    "time"
+)
+
+// ModelPricing holds per-model prices in US dollars per million tokens
+type ModelPricing struct {
+    InputPerMTok      float64 `json:"input_per_mtok"`
+    OutputPerMTok     float64 `json:"output_per_mtok"`
+    CacheWritePerMTok float64 `json:"cache_write_per_mtok"`
+    CacheReadPerMTok  float64 `json:"cache_read_per_mtok"`
+}
+
+// Cost returns the estimated dollar cost of the given token usage
+func (p ModelPricing) Cost(u Usage) float64 {
+    return (float64(u.InputTokens)*p.InputPerMTok +
+        float64(u.OutputTokens)*p.OutputPerMTok +
+        float64(u.CacheCreationInputTokens)*p.CacheWritePerMTok +
+        float64(u.CacheReadInputTokens)*p.CacheReadPerMTok) / 1e6
+}
+
+// defaultPricing is the built-in pricing table, current as of the models listed.
+// Override or extend it with WithPricing.
+var defaultPricing = map[string]ModelPricing{
+    "claude-opus-4":     {InputPerMTok: 15, OutputPerMTok: 75, CacheWritePerMTok: 18.75, CacheReadPerMTok: 1.50},
+    "claude-sonnet-4":   {InputPerMTok: 3, OutputPerMTok: 15, CacheWritePerMTok: 3.75, CacheReadPerMTok: 0.30},
+    "claude-3-7-sonnet": {InputPerMTok: 3, OutputPerMTok: 15, CacheWritePerMTok: 3.75, CacheReadPerMTok: 0.30},
+    "claude-3-5-sonnet": {InputPerMTok: 3, OutputPerMTok: 15, CacheWritePerMTok: 3.75, CacheReadPerMTok: 0.30},
+    "claude-3-5-haiku":  {InputPerMTok: 0.80, OutputPerMTok: 4, CacheWritePerMTok: 1, CacheReadPerMTok: 0.08},
+    "claude-3-opus":     {InputPerMTok: 15, OutputPerMTok: 75, CacheWritePerMTok: 18.75, CacheReadPerMTok: 1.50},
+    "claude-3-haiku":    {InputPerMTok: 0.25, OutputPerMTok: 1.25, CacheWritePerMTok: 0.30, CacheReadPerMTok: 0.03},
+}
+
+// DefaultPricing returns a copy of the built-in pricing table. Keys are model
+// names or model family prefixes (e.g. "claude-3-5-sonnet").
+func DefaultPricing() map[string]ModelPricing {
+    pricing := make(map[string]ModelPricing, len(defaultPricing))
+    for model, p := range defaultPricing {
+        pricing[model] = p
+    }
+    return pricing
+}
+
+// WithPricing adds or replaces entries in the client's pricing table
+func WithPricing(pricing map[string]ModelPricing) ClientOption {
+    return func(c *AnthropicClient) {
+        if c.pricing == nil {
+            c.pricing = DefaultPricing()
+        }
+        for model, p := range pricing {
+            c.pricing[model] = p
+        }
+    }
+}
+
+// lookupPricing finds pricing for a model by exact name, falling back to the
+// longest matching family prefix so dated and "-latest" names resolve.
+func lookupPricing(pricing map[string]ModelPricing, model string) (ModelPricing, bool) {
+    if p, ok := pricing[model]; ok {
+        return p, true
+    }
+    var best string
+    for prefix := range pricing {
+        if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+            best = prefix
+        }
+    }
+    if best == "" {
+        return ModelPricing{}, false
+    }
+    return pricing[best], true
+}
+
+// UsageEvent describes a single completed API call, for per-tenant metering
+type UsageEvent struct {
+    Time       time.Time     `json:"time"`
+    SessionID  string        `json:"session_id,omitempty"`
+    RequestID  string        `json:"request_id,omitempty"`
+    Model      string        `json:"model"`
+    Usage      Usage         `json:"usage"`
+    Latency    time.Duration `json:"latency"`
+    StopReason StopReason    `json:"stop_reason"`
+}
+
+// WithUsageCallback registers a function invoked after every successful API call.
+// It can be supplied multiple times; callbacks run synchronously in registration order.
+func WithUsageCallback(callback func(UsageEvent)) ClientOption {
+    return func(c *AnthropicClient) {
+        if callback != nil {
+            c.usageCallbacks = append(c.usageCallbacks, callback)
+        }
+    }
+}
+
+// ModelUsage aggregates token counts and estimated cost
+type ModelUsage struct {
+    Requests                 int     `json:"requests"`
+    InputTokens              int     `json:"input_tokens"`
+    OutputTokens             int     `json:"output_tokens"`
+    CacheCreationInputTokens int     `json:"cache_creation_input_tokens"`
+    CacheReadInputTokens     int     `json:"cache_read_input_tokens"`
+    CostUSD                  float64 `json:"cost_usd"`
+    Priced                   bool    `json:"priced"`    // False when no pricing entry matched the model
+}
+
+// TotalTokens returns the sum of all input, output, and cache tokens
+func (m ModelUsage) TotalTokens() int {
+    return m.InputTokens + m.OutputTokens + m.CacheCreationInputTokens + m.CacheReadInputTokens
+}
+
+// UsageReport summarizes cumulative usage for a client or session
+type UsageReport struct {
+    Models map[string]ModelUsage `json:"models"`
+    Total  ModelUsage            `json:"total"`
+}
+
+// UsageReport returns cumulative token usage and estimated cost across every
+// request made by the client, including all of its sessions.
+func (c *AnthropicClient) UsageReport() UsageReport {
+    return c.usage.report(c.pricingTable())
+}
+
+// UsageReport returns cumulative token usage and estimated cost for this session
+func (s *Session) UsageReport() UsageReport {
+    return s.usage.report(s.client.pricingTable())
+}
+
+// recordUsage attributes a response's token usage to the client and session
+// and notifies any registered usage callbacks.
+func (c *AnthropicClient) recordUsage(session *Session, reqBody Request, resp *AnthropicResponse, requestID string, latency time.Duration) {
+    model := resp.Model
+    if model == "" {
+        model = reqBody.Model
+    }
+    c.usage.record(model, resp.Usage)
+    if session != nil {
+        session.usage.record(model, resp.Usage)
+    }
+
+    if len(c.usageCallbacks) == 0 {
+        return
+    }
+    event := UsageEvent{
+        Time:       time.Now(),
+        RequestID:  requestID,
+        Model:      model,
+        Usage:      resp.Usage,
+        Latency:    latency,
+        StopReason: resp.StopReason,
+    }
+    if session != nil {
+        event.SessionID = session.id
+    }
+    for _, callback := range c.usageCallbacks {
+        callback(event)
+    }
+}
+
+func (c *AnthropicClient) pricingTable() map[string]ModelPricing {
+    if c.pricing != nil {
+        return c.pricing
+    }
+    return defaultPricing
+}
+
+// usageTracker accumulates token usage per model
+type usageTracker struct {
+    mu       sync.Mutex
+    byModel  map[string]Usage
+    requests map[string]int
+}
+
+func (t *usageTracker) record(model string, u Usage) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.byModel == nil {
+        t.byModel = make(map[string]Usage)
+        t.requests = make(map[string]int)
+    }
+    total := t.byModel[model]
+    total.Add(u)
+    t.byModel[model] = total
+    t.requests[model]++
+}
+
+func (t *usageTracker) report(pricing map[string]ModelPricing) UsageReport {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    report := UsageReport{
+        Models: make(map[string]ModelUsage, len(t.byModel)),
+        Total:  ModelUsage{Priced: true},
+    }
+    for model, u := range t.byModel {
+        p, priced := lookupPricing(pricing, model)
+        mu := ModelUsage{
+            Requests:                 t.requests[model],
+            InputTokens:              u.InputTokens,
+            OutputTokens:             u.OutputTokens,
+            CacheCreationInputTokens: u.CacheCreationInputTokens,
+            CacheReadInputTokens:     u.CacheReadInputTokens,
+            CostUSD:                  p.Cost(u),
+            Priced:                   priced,
+        }
+        report.Models[model] = mu
+
+        report.Total.Requests += mu.Requests
+        report.Total.InputTokens += mu.InputTokens
+        report.Total.OutputTokens += mu.OutputTokens
+        report.Total.CacheCreationInputTokens += mu.CacheCreationInputTokens
+        report.Total.CacheReadInputTokens += mu.CacheReadInputTokens
+        report.Total.CostUSD += mu.CostUSD
+        report.Total.Priced = report.Total.Priced && priced
+    }
+    return report
+}
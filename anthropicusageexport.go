@@ -0,0 +1,174 @@
+package anthropic
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "sort"
+    "strconv"
+    "sync"
+)
+
+// UsageGrouping selects how exported usage events are aggregated. Flags can be
+// combined (e.g. UsageBySession|UsageByDay); zero exports one row per event.
+type UsageGrouping int
+
+const (
+    UsageBySession UsageGrouping = 1 << iota
+    UsageByModel
+    UsageByDay
+)
+
+// UsageRow is one line of exported usage. Fields not part of the grouping are empty.
+type UsageRow struct {
+    Day                      string  `json:"day,omitempty"`
+    SessionID                string  `json:"session_id,omitempty"`
+    Model                    string  `json:"model,omitempty"`
+    Requests                 int     `json:"requests"`
+    InputTokens              int     `json:"input_tokens"`
+    OutputTokens             int     `json:"output_tokens"`
+    CacheCreationInputTokens int     `json:"cache_creation_input_tokens"`
+    CacheReadInputTokens     int     `json:"cache_read_input_tokens"`
+    CostUSD                  float64 `json:"cost_usd"`
+}
+
+// UsageRecorder accumulates UsageEvents for export to billing and reporting
+// pipelines. Attach it to a client with WithUsageCallback(recorder.Record).
+type UsageRecorder struct {
+    mu      sync.Mutex
+    events  []UsageEvent
+    pricing map[string]ModelPricing
+}
+
+// NewUsageRecorder creates a recorder that prices events with the given table,
+// or the built-in pricing table when pricing is nil.
+func NewUsageRecorder(pricing map[string]ModelPricing) *UsageRecorder {
+    if pricing == nil {
+        pricing = DefaultPricing()
+    }
+    return &UsageRecorder{pricing: pricing}
+}
+
+// Record stores a usage event; its signature matches WithUsageCallback
+func (r *UsageRecorder) Record(event UsageEvent) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.events = append(r.events, event)
+}
+
+// Events returns a copy of the recorded events
+func (r *UsageRecorder) Events() []UsageEvent {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    events := make([]UsageEvent, len(r.events))
+    copy(events, r.events)
+    return events
+}
+
+// Reset discards all recorded events, typically after a successful export
+func (r *UsageRecorder) Reset() {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.events = nil
+}
+
+// Rows aggregates the recorded events according to grouping. Rows are sorted by
+// day, session, and model.
+func (r *UsageRecorder) Rows(grouping UsageGrouping) []UsageRow {
+    events := r.Events()
+
+    var rows []UsageRow
+    index := make(map[UsageRow]int)
+    for _, event := range events {
+        key := UsageRow{}
+        day := event.Time.UTC().Format("2006-01-02")
+        if grouping == 0 {
+            key = UsageRow{Day: day, SessionID: event.SessionID, Model: event.Model}
+        }
+        if grouping&UsageByDay != 0 {
+            key.Day = day
+        }
+        if grouping&UsageBySession != 0 {
+            key.SessionID = event.SessionID
+        }
+        if grouping&UsageByModel != 0 {
+            key.Model = event.Model
+        }
+
+        pos, exists := index[key]
+        if !exists || grouping == 0 {
+            pos = len(rows)
+            rows = append(rows, key)
+            if grouping != 0 {
+                index[key] = pos
+            }
+        }
+
+        row := &rows[pos]
+        p, _ := lookupPricing(r.pricing, event.Model)
+        row.Requests++
+        row.InputTokens += event.Usage.InputTokens
+        row.OutputTokens += event.Usage.OutputTokens
+        row.CacheCreationInputTokens += event.Usage.CacheCreationInputTokens
+        row.CacheReadInputTokens += event.Usage.CacheReadInputTokens
+        row.CostUSD += p.Cost(event.Usage)
+    }
+
+    if grouping != 0 {
+        sort.SliceStable(rows, func(i, j int) bool {
+            if rows[i].Day != rows[j].Day {
+                return rows[i].Day < rows[j].Day
+            }
+            if rows[i].SessionID != rows[j].SessionID {
+                return rows[i].SessionID < rows[j].SessionID
+            }
+            return rows[i].Model < rows[j].Model
+        })
+    }
+    return rows
+}
+
+// ExportCSV writes the aggregated usage as CSV with a header row
+func (r *UsageRecorder) ExportCSV(w io.Writer, grouping UsageGrouping) error {
+    cw := csv.NewWriter(w)
+    header := []string{"day", "session_id", "model", "requests", "input_tokens", "output_tokens",
+        "cache_creation_input_tokens", "cache_read_input_tokens", "cost_usd"}
+    if err := cw.Write(header); err != nil {
+        return fmt.Errorf("error writing CSV header: %w", err)
+    }
+
+    for _, row := range r.Rows(grouping) {
+        record := []string{
+            row.Day,
+            row.SessionID,
+            row.Model,
+            strconv.Itoa(row.Requests),
+            strconv.Itoa(row.InputTokens),
+            strconv.Itoa(row.OutputTokens),
+            strconv.Itoa(row.CacheCreationInputTokens),
+            strconv.Itoa(row.CacheReadInputTokens),
+            strconv.FormatFloat(row.CostUSD, 'f', 6, 64),
+        }
+        if err := cw.Write(record); err != nil {
+            return fmt.Errorf("error writing CSV row: %w", err)
+        }
+    }
+
+    cw.Flush()
+    return cw.Error()
+}
+
+// ExportJSONL writes the aggregated usage as one JSON object per line
+func (r *UsageRecorder) ExportJSONL(w io.Writer, grouping UsageGrouping) error {
+    enc := json.NewEncoder(w)
+    for _, row := range r.Rows(grouping) {
+        if err := enc.Encode(row); err != nil {
+            return fmt.Errorf("error writing JSONL row: %w", err)
+        }
+    }
+    return nil
+}
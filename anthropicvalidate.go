@@ -0,0 +1,149 @@
+package anthropic
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "math"
+    "sort"
+    "strings"
+)
+
+// SchemaValidationError lists every way a JSON value violates a schema
+type SchemaValidationError struct {
+    Violations []string
+}
+
+func (e *SchemaValidationError) Error() string {
+    return "input does not match schema: " + strings.Join(e.Violations, "; ")
+}
+
+// ValidateAgainstSchema checks a JSON document against an InputSchema: the
+// top-level value must be an object, required properties must be present, and
+// every known property must match its type, enum, and nested schema. Unknown
+// properties are allowed, as in JSON Schema. Returns a *SchemaValidationError
+// on mismatch.
+func ValidateAgainstSchema(schema InputSchema, input json.RawMessage) error {
+    dec := json.NewDecoder(bytes.NewReader(input))
+    dec.UseNumber()
+    var value interface{}
+    if len(bytes.TrimSpace(input)) == 0 {
+        value = map[string]interface{}{}
+    } else if err := dec.Decode(&value); err != nil {
+        return &SchemaValidationError{Violations: []string{fmt.Sprintf("input is not valid JSON: %v", err)}}
+    }
+
+    root := Property{
+        Type:       schema.Type,
+        Properties: schema.Properties,
+        Required:   schema.Required,
+    }
+    var violations []string
+    validateValue("input", root, value, &violations)
+    if len(violations) > 0 {
+        return &SchemaValidationError{Violations: violations}
+    }
+    return nil
+}
+
+// validateValue appends a violation for each mismatch between value and prop
+func validateValue(path string, prop Property, value interface{}, violations *[]string) {
+    if prop.Type != "" && !matchesType(prop.Type, value) {
+        *violations = append(*violations, fmt.Sprintf("%s: expected %s, got %s", path, prop.Type, jsonTypeName(value)))
+        return
+    }
+
+    if len(prop.Enum) > 0 {
+        str, ok := value.(string)
+        if !ok || !containsString(prop.Enum, str) {
+            *violations = append(*violations, fmt.Sprintf("%s: must be one of [%s], got %v",
+                path, strings.Join(prop.Enum, ", "), value))
+        }
+    }
+
+    switch v := value.(type) {
+    case map[string]interface{}:
+        for _, name := range prop.Required {
+            if _, exists := v[name]; !exists {
+                *violations = append(*violations, fmt.Sprintf("%s.%s: required property missing", path, name))
+            }
+        }
+        names := make([]string, 0, len(v))
+        for name := range v {
+            names = append(names, name)
+        }
+        sort.Strings(names)
+        for _, name := range names {
+            if child, known := prop.Properties[name]; known {
+                validateValue(path+"."+name, child, v[name], violations)
+            }
+        }
+    case []interface{}:
+        if prop.Items != nil {
+            for i, item := range v {
+                validateValue(fmt.Sprintf("%s[%d]", path, i), *prop.Items, item, violations)
+            }
+        }
+    }
+}
+
+// matchesType reports whether a decoded JSON value satisfies a JSON Schema type
+func matchesType(schemaType string, value interface{}) bool {
+    switch schemaType {
+    case "object":
+        _, ok := value.(map[string]interface{})
+        return ok
+    case "array":
+        _, ok := value.([]interface{})
+        return ok
+    case "string":
+        _, ok := value.(string)
+        return ok
+    case "boolean":
+        _, ok := value.(bool)
+        return ok
+    case "number":
+        _, ok := value.(json.Number)
+        return ok
+    case "integer":
+        n, ok := value.(json.Number)
+        if !ok {
+            return false
+        }
+        // JSON has no separate integer type, so 1.0 and 1e3 count as integers
+        f, err := n.Float64()
+        return err == nil && math.Trunc(f) == f
+    case "null":
+        return value == nil
+    default:
+        return true
+    }
+}
+
+func jsonTypeName(value interface{}) string {
+    switch value.(type) {
+    case nil:
+        return "null"
+    case map[string]interface{}:
+        return "object"
+    case []interface{}:
+        return "array"
+    case string:
+        return "string"
+    case bool:
+        return "boolean"
+    case json.Number:
+        return "number"
+    default:
+        return fmt.Sprintf("%T", value)
+    }
+}
+
+func containsString(values []string, s string) bool {
+    for _, v := range values {
+        if v == s {
+            return true
+        }
+    }
+    return false
+}
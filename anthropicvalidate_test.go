@@ -0,0 +1,102 @@
+package anthropic
+
+import (
+    "encoding/json"
+    "errors"
+    "strings"
+    "testing"
+)
+
+func TestValidateAgainstSchema(t *testing.T) {
+    schema := InputSchema{
+        Type: "object",
+        Properties: map[string]Property{
+            "count": {Type: "integer"},
+            "ratio": {Type: "number"},
+            "unit":  {Type: "string", Enum: []string{"celsius", "fahrenheit"}},
+            "tags":  {Type: "array", Items: &Property{Type: "string"}},
+            "location": {
+                Type: "object",
+                Properties: map[string]Property{
+                    "city":    {Type: "string"},
+                    "country": {Type: "string"},
+                },
+                Required: []string{"city"},
+            },
+        },
+        Required: []string{"count"},
+    }
+
+    tests := []struct {
+        name  string
+        input string
+        want  []string // Substrings of the expected violations; nil means valid
+    }{
+        {name: "integer", input: `{"count": 3}`},
+        {name: "integral float", input: `{"count": 1.0}`},
+        {name: "exponent", input: `{"count": 1e3}`},
+        {name: "negative integer", input: `{"count": -7}`},
+        {name: "fractional float", input: `{"count": 1.5}`, want: []string{"input.count: expected integer, got number"}},
+        {name: "integer as string", input: `{"count": "3"}`, want: []string{"input.count: expected integer, got string"}},
+        {name: "number accepts fractions", input: `{"count": 1, "ratio": 0.25}`},
+        {name: "enum value", input: `{"count": 1, "unit": "celsius"}`},
+        {name: "enum mismatch", input: `{"count": 1, "unit": "kelvin"}`, want: []string{"input.unit: must be one of [celsius, fahrenheit], got kelvin"}},
+        {name: "required missing", input: `{"unit": "celsius"}`, want: []string{"input.count: required property missing"}},
+        {name: "empty input", input: ``, want: []string{"input.count: required property missing"}},
+        {name: "array items", input: `{"count": 1, "tags": ["a", 2]}`, want: []string{"input.tags[1]: expected string, got number"}},
+        {name: "nested valid", input: `{"count": 1, "location": {"city": "Paris"}}`},
+        {name: "nested required", input: `{"count": 1, "location": {"country": "FR"}}`, want: []string{"input.location.city: required property missing"}},
+        {name: "nested type", input: `{"count": 1, "location": {"city": 75}}`, want: []string{"input.location.city: expected string, got number"}},
+        {name: "unknown properties allowed", input: `{"count": 1, "extra": true}`},
+        {name: "several violations", input: `{"count": 0.5, "unit": "kelvin"}`, want: []string{"input.count", "input.unit"}},
+        {name: "not an object", input: `[1, 2]`, want: []string{"input: expected object, got array"}},
+        {name: "invalid JSON", input: `{"count":`, want: []string{"input is not valid JSON"}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := ValidateAgainstSchema(schema, json.RawMessage(tt.input))
+            if tt.want == nil {
+                if err != nil {
+                    t.Fatalf("ValidateAgainstSchema(%s) = %v, want nil", tt.input, err)
+                }
+                return
+            }
+            var verr *SchemaValidationError
+            if !errors.As(err, &verr) {
+                t.Fatalf("ValidateAgainstSchema(%s) = %v, want *SchemaValidationError", tt.input, err)
+            }
+            if len(verr.Violations) != len(tt.want) {
+                t.Fatalf("violations = %q, want %d", verr.Violations, len(tt.want))
+            }
+            for i, want := range tt.want {
+                if !strings.Contains(verr.Violations[i], want) {
+                    t.Errorf("violation %d = %q, want it to contain %q", i, verr.Violations[i], want)
+                }
+            }
+        })
+    }
+}
+
+func TestMatchesTypeInteger(t *testing.T) {
+    tests := []struct {
+        value string
+        want  bool
+    }{
+        {"0", true},
+        {"42", true},
+        {"-0", true},
+        {"1.0", true},
+        {"1e3", true},
+        {"2.5e1", true},
+        {"9007199254740993", true},
+        {"1.5", false},
+        {"1e-3", false},
+        {"1e400", false},
+    }
+    for _, tt := range tests {
+        if got := matchesType("integer", json.Number(tt.value)); got != tt.want {
+            t.Errorf("matchesType(integer, %s) = %v, want %v", tt.value, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,109 @@
+package anthropic
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "time"
+)
+
+const (
+    defaultWebhookTimeout          = 30 * time.Second
+    defaultWebhookMaxResponseBytes = 1 << 20
+)
+
+// WebhookConfig configures an HTTP-backed tool
+type WebhookConfig struct {
+    URL              string            // Endpoint receiving the tool input as a JSON POST body
+    AuthHeader       string            // Header carrying AuthValue; defaults to "Authorization"
+    AuthValue        string            // e.g. "Bearer <token>"
+    Headers          map[string]string // Additional static headers
+    Timeout          time.Duration     // Per-call timeout; defaults to 30s
+    MaxResponseBytes int64             // Larger responses are truncated; defaults to 1 MiB
+    HTTPClient       *http.Client      // Optional custom client
+}
+
+// NewWebhookTool returns a ToolHandler that forwards each tool call's input as a
+// POST to cfg.URL and returns the response body as the tool result, so tools can
+// run out of process in any language. Non-2xx responses become is_error results.
+// The tool name is sent in the X-Tool-Name header and the tool_use ID is not
+// exposed to the endpoint.
+func NewWebhookTool(def Tool, cfg WebhookConfig) (ToolHandler, error) {
+    if err := validateToolDefinition(def); err != nil {
+        return nil, err
+    }
+    if cfg.URL == "" {
+        return nil, fmt.Errorf("webhook tool %s: URL is required", def.Name)
+    }
+    if cfg.AuthHeader == "" {
+        cfg.AuthHeader = "Authorization"
+    }
+    if cfg.Timeout <= 0 {
+        cfg.Timeout = defaultWebhookTimeout
+    }
+    if cfg.MaxResponseBytes <= 0 {
+        cfg.MaxResponseBytes = defaultWebhookMaxResponseBytes
+    }
+    if cfg.HTTPClient == nil {
+        cfg.HTTPClient = &http.Client{}
+    }
+    return &webhookTool{def: def, cfg: cfg}, nil
+}
+
+type webhookTool struct {
+    def Tool
+    cfg WebhookConfig
+}
+
+func (t *webhookTool) Definition() Tool {
+    return t.def
+}
+
+func (t *webhookTool) Execute(ctx context.Context, input json.RawMessage) (ToolResult, error) {
+    ctx, cancel := context.WithTimeout(ctx, t.cfg.Timeout)
+    defer cancel()
+
+    if len(input) == 0 {
+        input = json.RawMessage("{}")
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.cfg.URL, bytes.NewReader(input))
+    if err != nil {
+        return ToolResult{}, fmt.Errorf("error creating webhook request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("X-Tool-Name", t.def.Name)
+    for k, v := range t.cfg.Headers {
+        req.Header.Set(k, v)
+    }
+    if t.cfg.AuthValue != "" {
+        req.Header.Set(t.cfg.AuthHeader, t.cfg.AuthValue)
+    }
+
+    logMessage("Forwarding tool '%s' to webhook", t.def.Name)
+    resp, err := t.cfg.HTTPClient.Do(req)
+    if err != nil {
+        return ToolResult{}, fmt.Errorf("webhook request failed: %w", err)
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(io.LimitReader(resp.Body, t.cfg.MaxResponseBytes+1))
+    if err != nil {
+        return ToolResult{}, fmt.Errorf("error reading webhook response: %w", err)
+    }
+    content := string(body)
+    if int64(len(body)) > t.cfg.MaxResponseBytes {
+        content = string(body[:t.cfg.MaxResponseBytes]) + "\n[response truncated]"
+    }
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        logMessage("Webhook for tool '%s' returned status %d", t.def.Name, resp.StatusCode)
+        return ToolResult{
+            Content: fmt.Sprintf("webhook returned status %d: %s", resp.StatusCode, content),
+            IsError: true,
+        }, nil
+    }
+    return ToolResult{Content: content}, nil
+}
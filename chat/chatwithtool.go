@@ -1,14 +1,13 @@
 package main
 
 import (
-    "bufio"
     "context"
     "flag"
     "fmt"
     "os"
-    "strings"
     "github.com/rdhillbb/messagefile"    
-    "anthropic"
+    "github.com/rdhillbb/anthropic"
+    "github.com/rdhillbb/anthropic/cli"
 )
 
 const defaultModel = "claude-3-5-sonnet-20241022"
@@ -33,7 +32,13 @@ func main() {
     systemPrompt, err := messagefile.GetMSG("systemprompt:systempromptmain")
     if err != nil {
         fmt.Printf("Warning: Failed to load system prompt: %v\nUsing default prompt\n", err)
-        systemPrompt = "You are Mr. PeeBody, an expert search agent." // Fallback prompt
+        systemPrompt = "You are an expert search agent." // Fallback prompt
+    }
+
+    registry, err := GetToolRegistry()
+    if err != nil {
+        fmt.Printf("Error: failed to register tools: %v\n", err)
+        os.Exit(1)
     }
 
     client := anthropic.NewClient(*apiKey, 
@@ -41,66 +46,23 @@ func main() {
         anthropic.WithDefaultParams(anthropic.MessageParams{
             Model:      defaultModel,
             MaxTokens:  8000,
-            Tools:      GetDefaultTools(),
+            Tools:      registry.Tools(),
             ToolChoice: &anthropic.ToolChoice{Type: anthropic.ToolChoiceAuto},
         }),
         anthropic.WithMaxConversationLength(10),
     )
 
-    handlers := GetDefaultHandlers()
-    scanner := bufio.NewScanner(os.Stdin)
-    ctx := context.Background()
-
-    fmt.Println("Chat initialized with tools. Type 'exit' to quit.")
+    fmt.Println("Chat initialized with tools. Type /help for commands, 'exit' to quit, Ctrl-C to stop a reply.")
     fmt.Println("Available tools:")
-    for _, tool := range GetDefaultTools() {
+    for _, tool := range registry.Tools() {
         fmt.Printf("- %s: %s\n", tool.Name, tool.Description)
     }
     fmt.Println("\nEnter your message:")
 
-    for {
-        fmt.Print("> ")
-        if !scanner.Scan() {
-            break
-        }
-
-        input := strings.TrimSpace(scanner.Text())
-        if input == "exit" {
-            break
-        }
-
-        if input == "" {
-            continue
-        }
-
-        response, err := client.ChatWithTools(
-            ctx,
-            input,
-            &anthropic.MessageParams{
-                Model:      defaultModel,
-                MaxTokens:  8000,
-                Tools:      GetDefaultTools(),
-                ToolChoice: &anthropic.ToolChoice{Type: anthropic.ToolChoiceAuto},
-            },
-            handlers,
-        )
-
-        if err != nil {
-            fmt.Printf("Error: %v\n", err)
-            continue
-        }
-
-        fmt.Println("\nAssistant:")
-        for _, content := range response.Content {
-            if content.Type == anthropic.ContentTypeText {
-                fmt.Println(content.Text)
-            }
-        }
-        fmt.Println()
-    }
-
-    if err := scanner.Err(); err != nil {
-        fmt.Printf("Error reading input: %v\n", err)
+    // Replies are streamed to the terminal as they are written
+    repl := cli.New(client, cli.Config{Tools: registry})
+    if err := repl.Run(context.Background()); err != nil {
+        fmt.Printf("Error: %v\n", err)
         os.Exit(1)
     }
 }
@@ -1,12 +1,13 @@
 package main
 
 import (
-	"anthropic" // Import the local package
 	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/rdhillbb/anthropic"
 )
 
 func main() {
@@ -37,24 +38,16 @@ func main() {
 		params := &anthropic.MessageParams{
 			Model:       "claude-3-5-sonnet-20241022", // Latest Claude model
 			MaxTokens:   1000,                         // Adjust as needed
-			Temperature: 0.7,                          // Adjust for creativity vs determinism
+			Temperature: anthropic.Float(0.7),         // Adjust for creativity vs determinism
 		}
 
-		// Send message to Claude
-		response, err := client.ChatMe(context.Background(), input, params)
+		// Send message to Claude, printing the answer as it is written
+		fmt.Print("Assistant: ")
+		_, err := client.ChatStream(context.Background(), input, params,
+			anthropic.TextHandler(func(text string) { fmt.Print(text) }))
+		fmt.Println()
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
-			continue
-		}
-
-		// Print assistant's response
-		if len(response.Content) > 0 {
-			fmt.Print("Assistant: ")
-			for _, content := range response.Content {
-				if content.Type == anthropic.ContentTypeText {
-					fmt.Println(content.Text)
-				}
-			}
 		}
 	}
 
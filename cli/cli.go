@@ -0,0 +1,209 @@
+// Package cli runs an interactive chat with Claude in the terminal, with
+// each reply printed as it is written:
+//
+//     repl := cli.New(client, cli.Config{Tools: registry})
+//     if err := repl.Run(context.Background()); err != nil {
+//         log.Fatal(err)
+//     }
+//
+// Typing "exit", or the end of input, ends the chat. Ctrl-C stops the reply
+// in progress and leaves the conversation as it was before the message.
+//
+// Lines starting with a slash are commands: /reset, /save <file>,
+// /load <file>, /model <id>, /system <prompt>, /tools, /usage, /help and
+// /exit. Config.Commands adds more.
+package cli
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "os/signal"
+    "strings"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    defaultPrompt = "> "
+    maxInputBytes = 1 << 20 // Longest line read, for pasted documents
+)
+
+// Config configures a REPL
+type Config struct {
+    Params  *anthropic.MessageParams // Parameters for every message; nil uses the client's defaults
+    Tools   *anthropic.ToolRegistry  // Tools Claude may call; nil disables tool use
+    Session *anthropic.Session       // Conversation to continue; nil starts a new one
+    Prompt  string                   // Shown before each input line; defaults to "> "
+    In      io.Reader                // Defaults to os.Stdin
+    Out     io.Writer                // Defaults to os.Stdout
+
+    // Commands adds slash commands; one with the name of a built-in
+    // command replaces it
+    Commands []Command
+}
+
+// REPL reads messages from the terminal and streams Claude's replies back
+type REPL struct {
+    client  *anthropic.AnthropicClient
+    cfg     Config
+    session *anthropic.Session
+
+    model     string // Set by /model; empty uses Config.Params or the client default
+    lastModel string // Model of the last reply
+
+    commands     map[string]Command
+    commandOrder []string // For /help
+}
+
+// New creates a REPL for client
+func New(client *anthropic.AnthropicClient, cfg Config) *REPL {
+    if cfg.Prompt == "" {
+        cfg.Prompt = defaultPrompt
+    }
+    if cfg.In == nil {
+        cfg.In = os.Stdin
+    }
+    if cfg.Out == nil {
+        cfg.Out = os.Stdout
+    }
+    session := cfg.Session
+    if session == nil {
+        session = client.NewSession()
+    }
+    r := &REPL{client: client, cfg: cfg, session: session, commands: make(map[string]Command)}
+    for _, cmd := range append(builtinCommands(), cfg.Commands...) {
+        if _, exists := r.commands[cmd.Name]; !exists {
+            r.commandOrder = append(r.commandOrder, cmd.Name)
+        }
+        r.commands[cmd.Name] = cmd
+    }
+    return r
+}
+
+// Session returns the conversation the REPL is adding to
+func (r *REPL) Session() *anthropic.Session {
+    return r.session
+}
+
+// Run reads messages and commands until "exit" or the end of input,
+// printing errors and carrying on
+func (r *REPL) Run(ctx context.Context) error {
+    scanner := bufio.NewScanner(r.cfg.In)
+    scanner.Buffer(make([]byte, 0, 64*1024), maxInputBytes)
+    for {
+        fmt.Fprint(r.cfg.Out, r.cfg.Prompt)
+        if !scanner.Scan() {
+            break
+        }
+
+        input := strings.TrimSpace(scanner.Text())
+        if input == "exit" {
+            break
+        }
+        if input == "" {
+            continue
+        }
+
+        var err error
+        if strings.HasPrefix(input, "/") {
+            err = r.dispatch(ctx, input)
+        } else {
+            err = r.Send(ctx, input)
+        }
+        if errors.Is(err, errExit) {
+            break
+        }
+        if err != nil {
+            fmt.Fprintf(r.cfg.Out, "Error: %v\n", err)
+        }
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+    }
+    fmt.Fprintln(r.cfg.Out)
+    if err := scanner.Err(); err != nil {
+        return fmt.Errorf("error reading input: %w", err)
+    }
+    return nil
+}
+
+// Send sends one message and streams the reply to Out, running the tools
+// Claude calls. Ctrl-C cancels the reply without ending the program.
+func (r *REPL) Send(ctx context.Context, message string) error {
+    replyCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+    defer stop()
+
+    var opts []anthropic.RequestOption
+    if r.model != "" {
+        opts = append(opts, anthropic.WithModel(r.model))
+    }
+    p := &printer{out: r.cfg.Out}
+    var resp *anthropic.AnthropicResponse
+    var err error
+    if r.cfg.Tools != nil {
+        opts = append(opts, anthropic.WithTools(r.cfg.Tools.Tools()...))
+        resp, err = r.session.ChatStreamWithTools(replyCtx, message, r.cfg.Params, r.cfg.Tools.Handlers(), p.handle, opts...)
+    } else {
+        resp, err = r.session.ChatStream(replyCtx, message, r.cfg.Params, p.handle, opts...)
+    }
+    p.finish()
+    if resp != nil {
+        r.lastModel = resp.Model
+    }
+
+    if err != nil && ctx.Err() == nil && errors.Is(replyCtx.Err(), context.Canceled) {
+        fmt.Fprintln(r.cfg.Out, "[reply cancelled]")
+        return nil
+    }
+    return err
+}
+
+// printer writes stream events to the terminal: text as it arrives, and a
+// line for each tool call
+type printer struct {
+    out     io.Writer
+    midLine bool // The last output did not end with a newline
+}
+
+func (p *printer) handle(event anthropic.StreamEvent) {
+    switch event.Type {
+    case anthropic.StreamContentBlockStart:
+        if block := event.ContentBlock; block != nil && block.Type == anthropic.ContentTypeToolUse {
+            p.line(fmt.Sprintf("[calling %s]", block.Name))
+        }
+    case anthropic.StreamContentBlockDelta:
+        if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+            fmt.Fprint(p.out, event.Delta.Text)
+            p.midLine = !strings.HasSuffix(event.Delta.Text, "\n")
+        }
+    case anthropic.StreamToolResult:
+        if block := event.ContentBlock; block != nil && block.IsError {
+            p.line(fmt.Sprintf("[tool failed: %s]", firstLine(block.Content)))
+        }
+    }
+}
+
+// line prints text on a line of its own
+func (p *printer) line(text string) {
+    p.finish()
+    fmt.Fprintln(p.out, text)
+}
+
+// finish ends a reply that stopped mid-line
+func (p *printer) finish() {
+    if p.midLine {
+        fmt.Fprintln(p.out)
+        p.midLine = false
+    }
+}
+
+func firstLine(text string) string {
+    if i := strings.IndexByte(text, '\n'); i >= 0 {
+        return text[:i]
+    }
+    return text
+}
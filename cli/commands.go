@@ -0,0 +1,177 @@
+package cli
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "strings"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+// errExit is returned by /exit to end Run
+var errExit = errors.New("exit")
+
+// Command is a slash command typed at the REPL prompt
+type Command struct {
+    Name string // Typed after the slash, e.g. "reset"
+    Args string // Argument synopsis shown by /help, e.g. "<file>"
+    Help string // One-line description shown by /help
+    Run  func(ctx context.Context, r *REPL, args string) error
+}
+
+// transcript is the file format of /save and /load
+type transcript struct {
+    System   string              `json:"system,omitempty"`
+    Model    string              `json:"model,omitempty"`
+    Messages []anthropic.Message `json:"messages"`
+}
+
+func builtinCommands() []Command {
+    return []Command{
+        {Name: "help", Help: "list the commands", Run: cmdHelp},
+        {Name: "reset", Help: "clear the conversation", Run: cmdReset},
+        {Name: "save", Args: "<file>", Help: "save the conversation to a JSON file", Run: cmdSave},
+        {Name: "load", Args: "<file>", Help: "replace the conversation with one saved by /save", Run: cmdLoad},
+        {Name: "model", Args: "[id]", Help: "show or change the model", Run: cmdModel},
+        {Name: "system", Args: "[prompt]", Help: "show or change the system prompt", Run: cmdSystem},
+        {Name: "tools", Help: "list the tools Claude can call", Run: cmdTools},
+        {Name: "usage", Help: "show the tokens used and estimated cost so far", Run: cmdUsage},
+        {Name: "exit", Help: "end the chat", Run: func(context.Context, *REPL, string) error { return errExit }},
+    }
+}
+
+// Printf writes to the REPL's output, for use by commands
+func (r *REPL) Printf(format string, args ...interface{}) {
+    fmt.Fprintf(r.cfg.Out, format, args...)
+}
+
+// dispatch runs the slash command in input
+func (r *REPL) dispatch(ctx context.Context, input string) error {
+    name, args, _ := strings.Cut(strings.TrimPrefix(input, "/"), " ")
+    cmd, ok := r.commands[name]
+    if !ok {
+        return fmt.Errorf("unknown command /%s; type /help for the list", name)
+    }
+    return cmd.Run(ctx, r, strings.TrimSpace(args))
+}
+
+func cmdHelp(ctx context.Context, r *REPL, args string) error {
+    for _, name := range r.commandOrder {
+        cmd := r.commands[name]
+        usage := "/" + cmd.Name
+        if cmd.Args != "" {
+            usage += " " + cmd.Args
+        }
+        r.Printf("  %-18s %s\n", usage, cmd.Help)
+    }
+    return nil
+}
+
+func cmdReset(ctx context.Context, r *REPL, args string) error {
+    r.session.Reset()
+    r.Printf("Conversation cleared.\n")
+    return nil
+}
+
+func cmdSave(ctx context.Context, r *REPL, args string) error {
+    if args == "" {
+        return errors.New("usage: /save <file>")
+    }
+    saved := transcript{
+        System:   r.session.GetSystemPrompt(),
+        Model:    r.model,
+        Messages: r.session.GetConversation(),
+    }
+    data, err := json.MarshalIndent(saved, "", "  ")
+    if err != nil {
+        return fmt.Errorf("error encoding conversation: %w", err)
+    }
+    if err := ioutil.WriteFile(args, data, 0600); err != nil {
+        return fmt.Errorf("error saving conversation: %w", err)
+    }
+    r.Printf("Saved %d messages to %s.\n", len(saved.Messages), args)
+    return nil
+}
+
+func cmdLoad(ctx context.Context, r *REPL, args string) error {
+    if args == "" {
+        return errors.New("usage: /load <file>")
+    }
+    data, err := ioutil.ReadFile(args)
+    if err != nil {
+        return fmt.Errorf("error loading conversation: %w", err)
+    }
+    var saved transcript
+    if err := json.Unmarshal(data, &saved); err != nil {
+        return fmt.Errorf("error parsing %s: %w", args, err)
+    }
+    if err := r.session.SetConversation(saved.Messages); err != nil {
+        return fmt.Errorf("error loading %s: %w", args, err)
+    }
+    if saved.System != "" {
+        r.session.UpdateSystemPrompt(saved.System)
+    }
+    if saved.Model != "" {
+        r.model = saved.Model
+    }
+    r.Printf("Loaded %d messages from %s.\n", len(saved.Messages), args)
+    return nil
+}
+
+func cmdModel(ctx context.Context, r *REPL, args string) error {
+    if args == "" {
+        switch {
+        case r.model != "":
+            r.Printf("Model: %s\n", r.model)
+        case r.lastModel != "":
+            r.Printf("Model: %s (default)\n", r.lastModel)
+        default:
+            r.Printf("Model: default\n")
+        }
+        return nil
+    }
+    if _, known := r.client.ModelCapabilities(args); !known {
+        r.Printf("Note: %s is not in the model table; sending it as given.\n", args)
+    }
+    r.model = args
+    r.Printf("Model set to %s.\n", args)
+    return nil
+}
+
+func cmdSystem(ctx context.Context, r *REPL, args string) error {
+    if args == "" {
+        r.Printf("%s\n", r.session.GetSystemPrompt())
+        return nil
+    }
+    r.session.UpdateSystemPrompt(args)
+    r.Printf("System prompt updated.\n")
+    return nil
+}
+
+func cmdTools(ctx context.Context, r *REPL, args string) error {
+    if r.cfg.Tools == nil || len(r.cfg.Tools.Tools()) == 0 {
+        r.Printf("No tools are available.\n")
+        return nil
+    }
+    for _, tool := range r.cfg.Tools.Tools() {
+        r.Printf("- %s: %s\n", tool.Name, firstLine(tool.Description))
+    }
+    return nil
+}
+
+func cmdUsage(ctx context.Context, r *REPL, args string) error {
+    total := r.session.UsageReport().Total
+    r.Printf("Requests:      %d\n", total.Requests)
+    r.Printf("Input tokens:  %d (%d cache writes, %d cache reads)\n",
+        total.InputTokens, total.CacheCreationInputTokens, total.CacheReadInputTokens)
+    r.Printf("Output tokens: %d\n", total.OutputTokens)
+    if total.Priced {
+        r.Printf("Cost:          $%.4f (estimated)\n", total.CostUSD)
+    }
+    window := r.session.ContextUsage()
+    r.Printf("Context:       %d of %d tokens (%.0f%%)\n", window.Used, window.Limit, window.Percent)
+    return nil
+}
@@ -0,0 +1,124 @@
+// Package grpctool dispatches Claude tool calls to gRPC services implementing
+// the ExecuteTool RPC described in tool.proto, so existing gRPC microservices
+// can be wired in as tools without bespoke glue:
+//
+//     conn, err := grpc.NewClient("tools.internal:443", grpc.WithTransportCredentials(creds))
+//     handler := grpctool.NewTool(conn, weatherTool, grpctool.Options{Timeout: 5 * time.Second})
+//     registry.Register(handler)
+//
+// The RPC exchanges google.protobuf.Struct messages, so services in any
+// language can implement it without generated code from this package.
+package grpctool
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "github.com/rdhillbb/anthropic"
+    "google.golang.org/grpc"
+    "google.golang.org/protobuf/types/known/structpb"
+)
+
+// ExecuteToolMethod is the full gRPC method name invoked for every tool call
+const ExecuteToolMethod = "/anthropic.tools.v1.ToolService/ExecuteTool"
+
+// Options configures a gRPC-backed tool
+type Options struct {
+    Timeout     time.Duration     // Per-call deadline; zero means the caller's context applies
+    CallOptions []grpc.CallOption // Extra options such as per-RPC credentials
+}
+
+// NewTool returns a ToolHandler that forwards calls to the ExecuteTool RPC on
+// conn. The connection is owned by the caller and is not closed by the handler.
+func NewTool(conn grpc.ClientConnInterface, def anthropic.Tool, opts Options) anthropic.ToolHandler {
+    return &grpcTool{conn: conn, def: def, opts: opts}
+}
+
+type grpcTool struct {
+    conn grpc.ClientConnInterface
+    def  anthropic.Tool
+    opts Options
+}
+
+func (t *grpcTool) Definition() anthropic.Tool {
+    return t.def
+}
+
+func (t *grpcTool) Execute(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+    if t.opts.Timeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, t.opts.Timeout)
+        defer cancel()
+    }
+
+    var args map[string]interface{}
+    if len(input) > 0 {
+        if err := json.Unmarshal(input, &args); err != nil {
+            return anthropic.ToolResult{}, fmt.Errorf("invalid tool input: %w", err)
+        }
+    }
+    req, err := structpb.NewStruct(map[string]interface{}{
+        "name":  t.def.Name,
+        "input": args,
+    })
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error encoding gRPC request: %w", err)
+    }
+
+    resp := &structpb.Struct{}
+    if err := t.conn.Invoke(ctx, ExecuteToolMethod, req, resp, t.opts.CallOptions...); err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("ExecuteTool RPC failed: %w", err)
+    }
+
+    fields := resp.GetFields()
+    result := anthropic.ToolResult{
+        Content: fields["content"].GetStringValue(),
+        IsError: fields["is_error"].GetBoolValue(),
+    }
+    return result, nil
+}
+
+// ExecuteFunc implements a tool service in Go: it receives the tool name and raw
+// JSON input and returns the result content and error flag.
+type ExecuteFunc func(ctx context.Context, name string, input json.RawMessage) (anthropic.ToolResult, error)
+
+// RegisterToolServer exposes fn as the ToolService on a gRPC server, for Go
+// services that want to serve tools to this client.
+func RegisterToolServer(s grpc.ServiceRegistrar, fn ExecuteFunc) {
+    s.RegisterService(&grpc.ServiceDesc{
+        ServiceName: "anthropic.tools.v1.ToolService",
+        HandlerType: (*interface{})(nil),
+        Methods: []grpc.MethodDesc{{
+            MethodName: "ExecuteTool",
+            Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+                req := &structpb.Struct{}
+                if err := dec(req); err != nil {
+                    return nil, err
+                }
+                handler := func(ctx context.Context, r interface{}) (interface{}, error) {
+                    fields := r.(*structpb.Struct).GetFields()
+                    input, err := json.Marshal(fields["input"].AsInterface())
+                    if err != nil {
+                        return nil, fmt.Errorf("error decoding tool input: %w", err)
+                    }
+
+                    result, err := fn(ctx, fields["name"].GetStringValue(), input)
+                    if err != nil {
+                        return nil, err
+                    }
+                    return structpb.NewStruct(map[string]interface{}{
+                        "content":  result.Content,
+                        "is_error": result.IsError,
+                    })
+                }
+                if interceptor == nil {
+                    return handler(ctx, req)
+                }
+                return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: ExecuteToolMethod}, handler)
+            },
+        }},
+        Metadata: "tool.proto",
+    }, struct{}{})
+}
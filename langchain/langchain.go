@@ -0,0 +1,295 @@
+// Package langchain adapts AnthropicClient to langchaingo's llms.Model
+// interface, so the client can be dropped into existing langchaingo chains
+// and agents:
+//
+//     llm := langchain.New(client, "claude-3-5-sonnet-20241022")
+//     chain := chains.NewLLMChain(llm, prompt)
+//
+// Requests go through the client, so its credentials, retries, rate limit,
+// guardrails, budget and usage tracking apply. Tool calls map onto
+// llms.ToolCall parts and streaming onto llms.WithStreamingFunc.
+package langchain
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+
+    "github.com/rdhillbb/anthropic"
+    "github.com/rdhillbb/anthropic/openaicompat"
+    "github.com/tmc/langchaingo/llms"
+)
+
+// LLM implements llms.Model on top of an AnthropicClient
+type LLM struct {
+    client *anthropic.AnthropicClient
+    model  string
+}
+
+var _ llms.Model = (*LLM)(nil)
+
+// New creates an adapter; an empty model uses the client's default
+func New(client *anthropic.AnthropicClient, model string) *LLM {
+    return &LLM{client: client, model: model}
+}
+
+// Call generates a completion for a single prompt
+func (l *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+    return llms.GenerateFromSinglePrompt(ctx, l, prompt, options...)
+}
+
+// GenerateContent sends messages to Claude and returns its reply as a
+// single choice. langchaingo's zero values mean "unset", so a temperature
+// of 0 cannot be requested through the options; set it in the client
+// defaults instead.
+func (l *LLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+    opts := llms.CallOptions{Model: l.model}
+    for _, option := range options {
+        option(&opts)
+    }
+    if opts.N > 1 || opts.CandidateCount > 1 {
+        return nil, fmt.Errorf("langchain: only one candidate is supported")
+    }
+
+    req, err := chatRequest(messages, opts)
+    if err != nil {
+        return nil, err
+    }
+    converted, err := openaicompat.Convert(req)
+    if err != nil {
+        return nil, err
+    }
+    if opts.TopK > 0 {
+        converted.Params.TopK = &opts.TopK
+    }
+    for k, v := range opts.Metadata {
+        if converted.Params.Metadata == nil {
+            converted.Params.Metadata = make(map[string]interface{})
+        }
+        converted.Params.Metadata[k] = v
+    }
+
+    var resp *anthropic.AnthropicResponse
+    if opts.StreamingFunc != nil || opts.StreamingReasoningFunc != nil {
+        resp, err = l.stream(ctx, converted, opts)
+    } else {
+        resp, err = l.client.Messages(ctx, converted.Messages, &converted.Params)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return contentResponse(converted, resp), nil
+}
+
+// stream sends the request with text and thinking deltas passed to the
+// streaming callbacks. A callback error cancels the request.
+func (l *LLM) stream(ctx context.Context, converted *openaicompat.Converted, opts llms.CallOptions) (*anthropic.AnthropicResponse, error) {
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    var callbackErr error
+    deliver := func(reasoning, text string) {
+        if callbackErr != nil {
+            return
+        }
+        switch {
+        case opts.StreamingReasoningFunc != nil:
+            callbackErr = opts.StreamingReasoningFunc(ctx, []byte(reasoning), []byte(text))
+        case text != "":
+            callbackErr = opts.StreamingFunc(ctx, []byte(text))
+        }
+        if callbackErr != nil {
+            cancel()
+        }
+    }
+    resp, err := l.client.MessagesStream(ctx, converted.Messages, &converted.Params, func(event anthropic.StreamEvent) {
+        if event.Type != anthropic.StreamContentBlockDelta {
+            return
+        }
+        switch event.Delta.Type {
+        case "text_delta":
+            deliver("", event.Delta.Text)
+        case "thinking_delta":
+            deliver(event.Delta.Thinking, "")
+        }
+    })
+    if callbackErr != nil {
+        return nil, fmt.Errorf("langchain: streaming callback: %w", callbackErr)
+    }
+    return resp, err
+}
+
+// chatRequest expresses the langchaingo call as an OpenAI-style request,
+// whose translation handles tools, tool results and JSON mode
+func chatRequest(messages []llms.MessageContent, opts llms.CallOptions) (openaicompat.ChatCompletionRequest, error) {
+    req := openaicompat.ChatCompletionRequest{
+        Model:     opts.Model,
+        MaxTokens: opts.MaxTokens,
+        Stop:      opts.StopWords,
+    }
+    if opts.Temperature > 0 {
+        req.Temperature = &opts.Temperature
+    }
+    if opts.TopP > 0 {
+        req.TopP = &opts.TopP
+    }
+    if opts.JSONMode {
+        req.ResponseFormat = &openaicompat.ResponseFormat{Type: "json_object"}
+    }
+
+    for i, msg := range messages {
+        converted, err := chatMessages(msg)
+        if err != nil {
+            return req, fmt.Errorf("langchain: message %d: %w", i, err)
+        }
+        req.Messages = append(req.Messages, converted...)
+    }
+
+    for _, tool := range opts.Tools {
+        if tool.Function == nil {
+            continue
+        }
+        fn, err := functionDefinition(*tool.Function)
+        if err != nil {
+            return req, err
+        }
+        req.Tools = append(req.Tools, openaicompat.ToolDefinition{Type: "function", Function: fn})
+    }
+    for _, def := range opts.Functions {
+        fn, err := functionDefinition(def)
+        if err != nil {
+            return req, err
+        }
+        req.Tools = append(req.Tools, openaicompat.ToolDefinition{Type: "function", Function: fn})
+    }
+
+    switch choice := opts.ToolChoice.(type) {
+    case nil:
+        if opts.FunctionCallBehavior == llms.FunctionCallBehaviorNone {
+            req.ToolChoice = &openaicompat.ToolChoice{Mode: "none"}
+        }
+    case string:
+        if choice == "any" {
+            choice = "required"
+        }
+        req.ToolChoice = &openaicompat.ToolChoice{Mode: choice}
+    case llms.ToolChoice:
+        req.ToolChoice = toolChoice(choice)
+    case *llms.ToolChoice:
+        req.ToolChoice = toolChoice(*choice)
+    default:
+        return req, fmt.Errorf("langchain: unsupported tool choice %T", opts.ToolChoice)
+    }
+    return req, nil
+}
+
+func toolChoice(choice llms.ToolChoice) *openaicompat.ToolChoice {
+    if choice.Function != nil {
+        return &openaicompat.ToolChoice{Function: choice.Function.Name}
+    }
+    return &openaicompat.ToolChoice{Mode: choice.Type}
+}
+
+func functionDefinition(def llms.FunctionDefinition) (openaicompat.FunctionDefinition, error) {
+    fn := openaicompat.FunctionDefinition{Name: def.Name, Description: def.Description, Strict: def.Strict}
+    if def.Parameters != nil {
+        params, err := json.Marshal(def.Parameters)
+        if err != nil {
+            return fn, fmt.Errorf("langchain: function %s parameters: %w", def.Name, err)
+        }
+        fn.Parameters = params
+    }
+    return fn, nil
+}
+
+// chatMessages converts one langchaingo message. Tool messages carrying
+// several results become one OpenAI tool message each.
+func chatMessages(msg llms.MessageContent) ([]openaicompat.ChatMessage, error) {
+    var role string
+    switch msg.Role {
+    case llms.ChatMessageTypeSystem:
+        role = "system"
+    case llms.ChatMessageTypeHuman, llms.ChatMessageTypeGeneric:
+        role = "user"
+    case llms.ChatMessageTypeAI:
+        role = "assistant"
+    case llms.ChatMessageTypeTool, llms.ChatMessageTypeFunction:
+        var results []openaicompat.ChatMessage
+        for _, part := range msg.Parts {
+            response, ok := part.(llms.ToolCallResponse)
+            if !ok {
+                return nil, fmt.Errorf("tool messages may only hold tool call responses, got %T", part)
+            }
+            results = append(results, openaicompat.ChatMessage{
+                Role:       "tool",
+                ToolCallID: response.ToolCallID,
+                Name:       response.Name,
+                Content:    openaicompat.Text(response.Content),
+            })
+        }
+        return results, nil
+    default:
+        return nil, fmt.Errorf("unsupported role %q", msg.Role)
+    }
+
+    out := openaicompat.ChatMessage{Role: role}
+    for _, part := range msg.Parts {
+        switch p := part.(type) {
+        case llms.TextContent:
+            out.Content.Parts = append(out.Content.Parts, openaicompat.ContentPart{Type: "text", Text: p.Text})
+        case llms.ImageURLContent:
+            out.Content.Parts = append(out.Content.Parts, openaicompat.ContentPart{
+                Type:     "image_url",
+                ImageURL: &openaicompat.ImageURL{URL: p.URL, Detail: p.Detail},
+            })
+        case llms.BinaryContent:
+            out.Content.Parts = append(out.Content.Parts, openaicompat.ContentPart{
+                Type:     "image_url",
+                ImageURL: &openaicompat.ImageURL{URL: p.String()},
+            })
+        case llms.ToolCall:
+            if p.FunctionCall == nil {
+                continue
+            }
+            out.ToolCalls = append(out.ToolCalls, openaicompat.ToolCall{
+                ID:       p.ID,
+                Type:     "function",
+                Function: openaicompat.FunctionCall{Name: p.FunctionCall.Name, Arguments: p.FunctionCall.Arguments},
+            })
+        default:
+            return nil, fmt.Errorf("unsupported content part %T", part)
+        }
+    }
+    return []openaicompat.ChatMessage{out}, nil
+}
+
+// contentResponse converts Claude's reply into a single langchaingo choice
+func contentResponse(converted *openaicompat.Converted, resp *anthropic.AnthropicResponse) *llms.ContentResponse {
+    message := converted.Response(resp).Choices[0].Message
+    choice := &llms.ContentChoice{
+        Content:    message.Content.String(),
+        StopReason: string(resp.StopReason),
+        GenerationInfo: map[string]any{
+            "InputTokens":              resp.Usage.InputTokens,
+            "OutputTokens":             resp.Usage.OutputTokens,
+            "CacheCreationInputTokens": resp.Usage.CacheCreationInputTokens,
+            "CacheReadInputTokens":     resp.Usage.CacheReadInputTokens,
+        },
+    }
+    for _, call := range message.ToolCalls {
+        choice.ToolCalls = append(choice.ToolCalls, llms.ToolCall{
+            ID:           call.ID,
+            Type:         call.Type,
+            FunctionCall: &llms.FunctionCall{Name: call.Function.Name, Arguments: call.Function.Arguments},
+        })
+    }
+    if len(choice.ToolCalls) > 0 {
+        choice.FuncCall = choice.ToolCalls[0].FunctionCall
+    }
+    for _, block := range resp.Content {
+        if block.Type == anthropic.ContentTypeThinking {
+            choice.ReasoningContent += block.Thinking
+        }
+    }
+    return &llms.ContentResponse{Choices: []*llms.ContentChoice{choice}}
+}
@@ -0,0 +1,502 @@
+// Package mcp connects to local Model Context Protocol servers over stdio and
+// exposes their tools as Anthropic tool handlers:
+//
+//     client, err := mcp.Start(ctx, mcp.ServerConfig{
+//         Command: "npx",
+//         Args:    []string{"-y", "@modelcontextprotocol/server-filesystem", "/srv/docs"},
+//     })
+//     defer client.Close()
+//     if err := client.Register(ctx, registry); err != nil { ... }
+//
+// Tool calls Claude makes are dispatched back to the MCP server with tools/call.
+package mcp
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os/exec"
+    "regexp"
+    "sync"
+    "sync/atomic"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    protocolVersion = "2025-03-26"
+    clientName      = "rdhillbb-anthropic"
+    clientVersion   = "0.1.0"
+    maxMessageBytes = 16 << 20
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// ServerConfig describes how to launch an MCP server
+type ServerConfig struct {
+    Command string
+    Args    []string
+    Env     []string // Full environment; nil inherits the parent's
+    Dir     string
+
+    // Prefix is prepended to tool names (e.g. "fs_") to avoid collisions when
+    // several servers are connected to one registry.
+    Prefix string
+}
+
+// Tool is a tool advertised by an MCP server
+type Tool struct {
+    Name        string                 `json:"name"`
+    Description string                 `json:"description"`
+    InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// Client is a connection to one MCP server process
+type Client struct {
+    cfg    ServerConfig
+    cmd    *exec.Cmd
+    stdin  io.WriteCloser
+    nextID int64
+
+    writeMu sync.Mutex
+    mu      sync.Mutex
+    pending map[int64]chan rpcResponse
+    closed  bool // No more calls are accepted; set once the reader stops
+    closing bool // Close was called
+    done    chan struct{}
+
+    serverName string
+}
+
+type rpcRequest struct {
+    JSONRPC string      `json:"jsonrpc"`
+    ID      *int64      `json:"id,omitempty"`
+    Method  string      `json:"method"`
+    Params  interface{} `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+    ID     *int64          `json:"id"`
+    Method string          `json:"method"`
+    Result json.RawMessage `json:"result"`
+    Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+    Code    int    `json:"code"`
+    Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+    return fmt.Sprintf("MCP error %d: %s", e.Code, e.Message)
+}
+
+// Start launches the server process and performs the MCP initialize handshake
+func Start(ctx context.Context, cfg ServerConfig) (*Client, error) {
+    if cfg.Command == "" {
+        return nil, fmt.Errorf("MCP server command is required")
+    }
+
+    cmd := exec.Command(cfg.Command, cfg.Args...)
+    cmd.Env = cfg.Env
+    cmd.Dir = cfg.Dir
+    stdin, err := cmd.StdinPipe()
+    if err != nil {
+        return nil, fmt.Errorf("error creating MCP stdin: %w", err)
+    }
+    stdout, err := cmd.StdoutPipe()
+    if err != nil {
+        return nil, fmt.Errorf("error creating MCP stdout: %w", err)
+    }
+    if err := cmd.Start(); err != nil {
+        return nil, fmt.Errorf("error starting MCP server: %w", err)
+    }
+
+    c := &Client{
+        cfg:     cfg,
+        cmd:     cmd,
+        stdin:   stdin,
+        pending: make(map[int64]chan rpcResponse),
+        done:    make(chan struct{}),
+    }
+    go c.readLoop(stdout)
+
+    if err := c.initialize(ctx); err != nil {
+        c.Close()
+        return nil, err
+    }
+    return c, nil
+}
+
+func (c *Client) initialize(ctx context.Context) error {
+    var result struct {
+        ProtocolVersion string `json:"protocolVersion"`
+        ServerInfo      struct {
+            Name    string `json:"name"`
+            Version string `json:"version"`
+        } `json:"serverInfo"`
+    }
+    err := c.call(ctx, "initialize", map[string]interface{}{
+        "protocolVersion": protocolVersion,
+        "capabilities":    map[string]interface{}{},
+        "clientInfo": map[string]string{
+            "name":    clientName,
+            "version": clientVersion,
+        },
+    }, &result)
+    if err != nil {
+        return fmt.Errorf("MCP initialize failed: %w", err)
+    }
+    c.serverName = result.ServerInfo.Name
+    return c.notify("notifications/initialized", nil)
+}
+
+// ServerName returns the name the server reported during initialization
+func (c *Client) ServerName() string {
+    return c.serverName
+}
+
+// ListTools returns every tool the server advertises, following pagination
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+    var tools []Tool
+    cursor := ""
+    for {
+        params := map[string]interface{}{}
+        if cursor != "" {
+            params["cursor"] = cursor
+        }
+        var page struct {
+            Tools      []Tool `json:"tools"`
+            NextCursor string `json:"nextCursor"`
+        }
+        if err := c.call(ctx, "tools/list", params, &page); err != nil {
+            return nil, fmt.Errorf("MCP tools/list failed: %w", err)
+        }
+        tools = append(tools, page.Tools...)
+        if page.NextCursor == "" {
+            return tools, nil
+        }
+        cursor = page.NextCursor
+    }
+}
+
+// CallTool invokes a tool on the server and converts its content into a ToolResult
+func (c *Client) CallTool(ctx context.Context, name string, arguments json.RawMessage) (anthropic.ToolResult, error) {
+    if len(arguments) == 0 {
+        arguments = json.RawMessage("{}")
+    }
+    var result struct {
+        Content []struct {
+            Type     string `json:"type"`
+            Text     string `json:"text"`
+            Data     string `json:"data"`
+            MimeType string `json:"mimeType"`
+            Resource *struct {
+                URI  string `json:"uri"`
+                Text string `json:"text"`
+            } `json:"resource"`
+        } `json:"content"`
+        IsError bool `json:"isError"`
+    }
+    err := c.call(ctx, "tools/call", map[string]interface{}{
+        "name":      name,
+        "arguments": arguments,
+    }, &result)
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("MCP tools/call %s failed: %w", name, err)
+    }
+
+    var blocks []anthropic.MessageContent
+    for _, item := range result.Content {
+        switch item.Type {
+        case "text":
+            blocks = append(blocks, anthropic.MessageContent{Type: anthropic.ContentTypeText, Text: item.Text})
+        case "image":
+            blocks = append(blocks, anthropic.MessageContent{
+                Type: anthropic.ContentTypeImage,
+                Source: &anthropic.ImageSource{
+                    Type:      "base64",
+                    MediaType: item.MimeType,
+                    Data:      item.Data,
+                },
+            })
+        case "resource":
+            if item.Resource != nil && item.Resource.Text != "" {
+                blocks = append(blocks, anthropic.MessageContent{Type: anthropic.ContentTypeText, Text: item.Resource.Text})
+            }
+        }
+    }
+
+    toolResult := anthropic.ToolResult{IsError: result.IsError}
+    if len(blocks) == 1 && blocks[0].Type == anthropic.ContentTypeText {
+        toolResult.Content = blocks[0].Text
+    } else {
+        toolResult.Blocks = blocks
+    }
+    return toolResult, nil
+}
+
+// Tools lists the server's tools and wraps each as an anthropic.ToolHandler
+func (c *Client) Tools(ctx context.Context) ([]anthropic.ToolHandler, error) {
+    tools, err := c.ListTools(ctx)
+    if err != nil {
+        return nil, err
+    }
+    handlers := make([]anthropic.ToolHandler, 0, len(tools))
+    for _, tool := range tools {
+        handlers = append(handlers, &mcpTool{
+            client:     c,
+            remoteName: tool.Name,
+            def:        c.convertTool(tool),
+        })
+    }
+    return handlers, nil
+}
+
+// Register adds every tool from the server to the registry
+func (c *Client) Register(ctx context.Context, registry *anthropic.ToolRegistry) error {
+    handlers, err := c.Tools(ctx)
+    if err != nil {
+        return err
+    }
+    for _, h := range handlers {
+        if err := registry.Register(h); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Close terminates the server process and fails any in-flight calls
+func (c *Client) Close() error {
+    c.mu.Lock()
+    if c.closing {
+        c.mu.Unlock()
+        return nil
+    }
+    c.closing = true
+    c.closed = true
+    c.mu.Unlock()
+
+    c.stdin.Close()
+    if c.cmd.Process != nil {
+        // Fails harmlessly if the server already exited on its own
+        c.cmd.Process.Kill()
+    }
+    <-c.done
+    // Wait reaps the process and releases its pipes even when it exited by
+    // itself; the exit status carries no information here
+    c.cmd.Wait()
+    return nil
+}
+
+// convertTool maps an MCP tool onto an Anthropic tool definition
+func (c *Client) convertTool(tool Tool) anthropic.Tool {
+    name := c.cfg.Prefix + invalidNameChars.ReplaceAllString(tool.Name, "_")
+    if len(name) > 64 {
+        name = name[:64]
+    }
+    description := tool.Description
+    if description == "" {
+        description = fmt.Sprintf("MCP tool %s", tool.Name)
+    }
+
+    root := convertSchema(tool.InputSchema)
+    schema := anthropic.InputSchema{
+        Type:       "object",
+        Properties: root.Properties,
+        Required:   root.Required,
+    }
+    // The client requires at least one property; allow calls without arguments
+    if len(schema.Properties) == 0 {
+        schema.Properties = map[string]anthropic.Property{
+            "_": {Type: "string", Description: "Unused; this tool takes no parameters"},
+        }
+    }
+    return anthropic.Tool{Name: name, Description: description, InputSchema: schema}
+}
+
+// convertSchema tolerantly maps a JSON Schema object onto Property
+func convertSchema(raw map[string]interface{}) anthropic.Property {
+    var prop anthropic.Property
+    switch t := raw["type"].(type) {
+    case string:
+        prop.Type = t
+    case []interface{}:
+        for _, v := range t {
+            if s, ok := v.(string); ok && s != "null" {
+                prop.Type = s
+                break
+            }
+        }
+    }
+    if desc, ok := raw["description"].(string); ok {
+        prop.Description = desc
+    }
+    if enum, ok := raw["enum"].([]interface{}); ok {
+        for _, v := range enum {
+            prop.Enum = append(prop.Enum, fmt.Sprint(v))
+        }
+    }
+    if items, ok := raw["items"].(map[string]interface{}); ok {
+        itemProp := convertSchema(items)
+        prop.Items = &itemProp
+    }
+    if props, ok := raw["properties"].(map[string]interface{}); ok {
+        prop.Properties = make(map[string]anthropic.Property, len(props))
+        for name, child := range props {
+            if childMap, ok := child.(map[string]interface{}); ok {
+                prop.Properties[name] = convertSchema(childMap)
+            }
+        }
+    }
+    if required, ok := raw["required"].([]interface{}); ok {
+        for _, v := range required {
+            if s, ok := v.(string); ok {
+                prop.Required = append(prop.Required, s)
+            }
+        }
+    }
+    return prop
+}
+
+// JSON-RPC plumbing
+
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+    id := atomic.AddInt64(&c.nextID, 1)
+    ch := make(chan rpcResponse, 1)
+
+    c.mu.Lock()
+    if c.closed {
+        c.mu.Unlock()
+        return fmt.Errorf("MCP client is closed")
+    }
+    c.pending[id] = ch
+    c.mu.Unlock()
+
+    defer func() {
+        c.mu.Lock()
+        delete(c.pending, id)
+        c.mu.Unlock()
+    }()
+
+    if err := c.write(rpcRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+        return err
+    }
+
+    select {
+    case resp, ok := <-ch:
+        if !ok {
+            return fmt.Errorf("MCP server exited")
+        }
+        if resp.Error != nil {
+            return resp.Error
+        }
+        if result != nil && len(resp.Result) > 0 {
+            if err := json.Unmarshal(resp.Result, result); err != nil {
+                return fmt.Errorf("error decoding MCP %s result: %w", method, err)
+            }
+        }
+        return nil
+    case <-ctx.Done():
+        c.notify("notifications/cancelled", map[string]interface{}{"requestId": id})
+        return ctx.Err()
+    }
+}
+
+func (c *Client) notify(method string, params interface{}) error {
+    return c.write(rpcRequest{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (c *Client) write(msg interface{}) error {
+    data, err := json.Marshal(msg)
+    if err != nil {
+        return fmt.Errorf("error encoding MCP message: %w", err)
+    }
+    c.writeMu.Lock()
+    defer c.writeMu.Unlock()
+    if _, err := c.stdin.Write(append(data, '\n')); err != nil {
+        return fmt.Errorf("error writing to MCP server: %w", err)
+    }
+    return nil
+}
+
+// readLoop dispatches responses to waiting calls and answers server requests
+func (c *Client) readLoop(stdout io.Reader) {
+    defer func() {
+        c.mu.Lock()
+        for id, ch := range c.pending {
+            close(ch)
+            delete(c.pending, id)
+        }
+        c.closed = true
+        c.mu.Unlock()
+        close(c.done)
+    }()
+
+    scanner := bufio.NewScanner(stdout)
+    scanner.Buffer(make([]byte, 64*1024), maxMessageBytes)
+    for scanner.Scan() {
+        var msg rpcResponse
+        if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+            continue
+        }
+
+        switch {
+        case msg.Method != "" && msg.ID != nil:
+            // Server-initiated request: answer pings, reject anything else
+            if msg.Method == "ping" {
+                c.write(map[string]interface{}{"jsonrpc": "2.0", "id": *msg.ID, "result": map[string]interface{}{}})
+            } else {
+                c.write(map[string]interface{}{
+                    "jsonrpc": "2.0",
+                    "id":      *msg.ID,
+                    "error":   rpcError{Code: -32601, Message: "method not supported by client"},
+                })
+            }
+        case msg.Method != "":
+            // Notification; nothing to do
+        case msg.ID != nil:
+            c.mu.Lock()
+            ch, exists := c.pending[*msg.ID]
+            c.mu.Unlock()
+            if exists {
+                ch <- msg
+            }
+        }
+    }
+}
+
+// mcpTool adapts one MCP server tool to anthropic.ToolHandler
+type mcpTool struct {
+    client     *Client
+    remoteName string
+    def        anthropic.Tool
+}
+
+func (t *mcpTool) Definition() anthropic.Tool {
+    return t.def
+}
+
+func (t *mcpTool) Execute(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+    return t.client.CallTool(ctx, t.remoteName, stripPlaceholder(input))
+}
+
+// stripPlaceholder removes the "_" argument added for parameterless tools
+func stripPlaceholder(input json.RawMessage) json.RawMessage {
+    var args map[string]json.RawMessage
+    if err := json.Unmarshal(input, &args); err != nil {
+        return input
+    }
+    if _, exists := args["_"]; !exists {
+        return input
+    }
+    delete(args, "_")
+    cleaned, err := json.Marshal(args)
+    if err != nil {
+        return input
+    }
+    return cleaned
+}
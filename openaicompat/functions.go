@@ -0,0 +1,327 @@
+package openaicompat
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "strings"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const maxSchemaDepth = 32
+
+// placeholderArg is the property given to functions without parameters
+const placeholderArg = "_"
+
+// ImportTools reads an OpenAI function-calling catalog and returns the
+// equivalent tool definitions for MessageParams.Tools, so existing catalogs
+// can be used with ChatWithTools unchanged. data may hold a function
+// definition, a tools entry ({"type": "function", "function": {...}}), an
+// array of either, or an object with a "functions" or "tools" array.
+// Functions without parameters get a placeholder "_" property, which the
+// client requires; wrap their handlers with Handlers to drop it again.
+func ImportTools(data []byte) ([]anthropic.Tool, error) {
+    functions, err := parseCatalog(data)
+    if err != nil {
+        return nil, fmt.Errorf("openaicompat: %w", err)
+    }
+    return ToolsFromFunctions(functions)
+}
+
+// ImportToolsFile is ImportTools for a JSON file
+func ImportToolsFile(filename string) ([]anthropic.Tool, error) {
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        return nil, fmt.Errorf("openaicompat: error reading %s: %w", filename, err)
+    }
+    return ImportTools(data)
+}
+
+// ToolsFromFunctions converts OpenAI function definitions into tools. The
+// parameters schema is translated as far as Property can express it:
+// local $ref and $defs references are inlined, nullable types such as
+// ["string", "null"] and anyOf/oneOf with null become the non-null type,
+// and enum values become strings. Keywords without an equivalent, such as
+// format or minimum, are dropped.
+func ToolsFromFunctions(functions []FunctionDefinition) ([]anthropic.Tool, error) {
+    tools := make([]anthropic.Tool, 0, len(functions))
+    seen := make(map[string]bool, len(functions))
+    for _, fn := range functions {
+        if fn.Name == "" {
+            return nil, fmt.Errorf("openaicompat: function definition has no name")
+        }
+        if seen[fn.Name] {
+            return nil, fmt.Errorf("openaicompat: function %s is defined twice", fn.Name)
+        }
+        seen[fn.Name] = true
+        tool, err := functionTool(fn)
+        if err != nil {
+            return nil, fmt.Errorf("openaicompat: %w", err)
+        }
+        tools = append(tools, tool)
+    }
+    return tools, nil
+}
+
+// Handlers wraps tool handlers so they receive the arguments without the
+// placeholder property added to functions that take no parameters
+func Handlers(handlers map[string]func(context.Context, json.RawMessage) (string, error)) map[string]func(context.Context, json.RawMessage) (string, error) {
+    wrapped := make(map[string]func(context.Context, json.RawMessage) (string, error), len(handlers))
+    for name, handler := range handlers {
+        handler := handler
+        wrapped[name] = func(ctx context.Context, input json.RawMessage) (string, error) {
+            return handler(ctx, StripPlaceholder(input))
+        }
+    }
+    return wrapped
+}
+
+// StripPlaceholder removes the "_" argument added for functions without
+// parameters
+func StripPlaceholder(input json.RawMessage) json.RawMessage {
+    var args map[string]json.RawMessage
+    if err := json.Unmarshal(input, &args); err != nil {
+        return input
+    }
+    if _, exists := args[placeholderArg]; !exists {
+        return input
+    }
+    delete(args, placeholderArg)
+    cleaned, err := json.Marshal(args)
+    if err != nil {
+        return input
+    }
+    return cleaned
+}
+
+// parseCatalog accepts the catalog layouts ImportTools documents
+func parseCatalog(data []byte) ([]FunctionDefinition, error) {
+    var entries []json.RawMessage
+    var wrapper struct {
+        Functions []json.RawMessage `json:"functions"`
+        Tools     []json.RawMessage `json:"tools"`
+    }
+    trimmed := strings.TrimSpace(string(data))
+    switch {
+    case strings.HasPrefix(trimmed, "["):
+        if err := json.Unmarshal(data, &entries); err != nil {
+            return nil, fmt.Errorf("invalid function catalog: %w", err)
+        }
+    case strings.HasPrefix(trimmed, "{"):
+        if err := json.Unmarshal(data, &wrapper); err != nil {
+            return nil, fmt.Errorf("invalid function catalog: %w", err)
+        }
+        entries = append(wrapper.Functions, wrapper.Tools...)
+        if len(entries) == 0 {
+            entries = []json.RawMessage{json.RawMessage(trimmed)}
+        }
+    default:
+        return nil, fmt.Errorf("invalid function catalog: expected a JSON object or array")
+    }
+
+    functions := make([]FunctionDefinition, 0, len(entries))
+    for i, entry := range entries {
+        var item struct {
+            Type     string              `json:"type"`
+            Function *FunctionDefinition `json:"function"`
+            FunctionDefinition
+        }
+        if err := json.Unmarshal(entry, &item); err != nil {
+            return nil, fmt.Errorf("invalid function catalog entry %d: %w", i, err)
+        }
+        switch {
+        case item.Function != nil:
+            if item.Type != "" && item.Type != "function" {
+                return nil, fmt.Errorf("catalog entry %d has unsupported tool type %q", i, item.Type)
+            }
+            functions = append(functions, *item.Function)
+        default:
+            functions = append(functions, item.FunctionDefinition)
+        }
+    }
+    return functions, nil
+}
+
+// jsonSchema is the subset of JSON Schema that maps onto Property
+type jsonSchema struct {
+    Ref         string                 `json:"$ref"`
+    Type        json.RawMessage        `json:"type"` // A name or an array of names
+    Description string                 `json:"description"`
+    Enum        []interface{}          `json:"enum"`
+    Const       interface{}            `json:"const"`
+    Items       *jsonSchema            `json:"items"`
+    Properties  map[string]*jsonSchema `json:"properties"`
+    Required    []string               `json:"required"`
+    AnyOf       []*jsonSchema          `json:"anyOf"`
+    OneOf       []*jsonSchema          `json:"oneOf"`
+    AllOf       []*jsonSchema          `json:"allOf"`
+    Defs        map[string]*jsonSchema `json:"$defs"`
+    Definitions map[string]*jsonSchema `json:"definitions"`
+}
+
+// schemaConverter resolves references against the root schema
+type schemaConverter struct {
+    root *jsonSchema
+}
+
+// parseSchema converts a JSON Schema object into an InputSchema
+func parseSchema(data json.RawMessage) (anthropic.InputSchema, error) {
+    var root jsonSchema
+    if err := json.Unmarshal(data, &root); err != nil {
+        return anthropic.InputSchema{}, fmt.Errorf("invalid parameters schema: %w", err)
+    }
+    conv := schemaConverter{root: &root}
+    prop, err := conv.property(&root, 0)
+    if err != nil {
+        return anthropic.InputSchema{}, err
+    }
+    if prop.Type == "" {
+        prop.Type = "object"
+    }
+    if prop.Type != "object" {
+        return anthropic.InputSchema{}, fmt.Errorf("parameters schema must be an object, got %q", prop.Type)
+    }
+    schema := anthropic.InputSchema{Type: "object", Properties: prop.Properties, Required: prop.Required}
+    if schema.Properties == nil {
+        schema.Properties = map[string]anthropic.Property{}
+    }
+    if schema.Required == nil {
+        schema.Required = []string{}
+    }
+    return schema, nil
+}
+
+func (c schemaConverter) property(s *jsonSchema, depth int) (anthropic.Property, error) {
+    if depth > maxSchemaDepth {
+        return anthropic.Property{}, fmt.Errorf("schema nests too deeply; recursive schemas are not supported")
+    }
+    s, err := c.resolve(s, depth)
+    if err != nil {
+        return anthropic.Property{}, err
+    }
+    if s == nil {
+        return anthropic.Property{}, nil
+    }
+
+    // A nullable union is treated as its non-null member, keeping the
+    // outer description
+    if variant := nonNull(s.AnyOf); variant != nil {
+        return c.withDescription(variant, s.Description, depth)
+    }
+    if variant := nonNull(s.OneOf); variant != nil {
+        return c.withDescription(variant, s.Description, depth)
+    }
+    if len(s.AllOf) == 1 {
+        return c.withDescription(s.AllOf[0], s.Description, depth)
+    }
+
+    prop := anthropic.Property{
+        Type:        schemaType(s.Type),
+        Description: s.Description,
+        Required:    s.Required,
+    }
+    for _, v := range s.Enum {
+        if v != nil {
+            prop.Enum = append(prop.Enum, fmt.Sprint(v))
+        }
+    }
+    if s.Const != nil {
+        prop.Enum = []string{fmt.Sprint(s.Const)}
+    }
+    if s.Items != nil {
+        items, err := c.property(s.Items, depth+1)
+        if err != nil {
+            return anthropic.Property{}, err
+        }
+        prop.Items = &items
+    }
+    if len(s.Properties) > 0 {
+        if prop.Type == "" {
+            prop.Type = "object"
+        }
+        prop.Properties = make(map[string]anthropic.Property, len(s.Properties))
+        for name, child := range s.Properties {
+            childProp, err := c.property(child, depth+1)
+            if err != nil && depth == 0 {
+                return anthropic.Property{}, fmt.Errorf("property %s: %w", name, err)
+            }
+            if err != nil {
+                return anthropic.Property{}, err
+            }
+            prop.Properties[name] = childProp
+        }
+    }
+    return prop, nil
+}
+
+func (c schemaConverter) withDescription(s *jsonSchema, description string, depth int) (anthropic.Property, error) {
+    prop, err := c.property(s, depth+1)
+    if err == nil && description != "" {
+        prop.Description = description
+    }
+    return prop, err
+}
+
+// resolve follows local references into $defs or definitions
+func (c schemaConverter) resolve(s *jsonSchema, depth int) (*jsonSchema, error) {
+    for s != nil && s.Ref != "" {
+        if depth > maxSchemaDepth {
+            return nil, fmt.Errorf("schema reference %s nests too deeply", s.Ref)
+        }
+        var target *jsonSchema
+        switch {
+        case s.Ref == "#":
+            target = c.root
+        case strings.HasPrefix(s.Ref, "#/$defs/"):
+            target = c.root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+        case strings.HasPrefix(s.Ref, "#/definitions/"):
+            target = c.root.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+        }
+        if target == nil {
+            return nil, fmt.Errorf("unresolvable schema reference %s", s.Ref)
+        }
+        if s.Description != "" {
+            described := *target
+            described.Description = s.Description
+            target = &described
+        }
+        s = target
+        depth++
+    }
+    return s, nil
+}
+
+// schemaType returns the type name, taking the first non-null entry of an
+// array of types
+func schemaType(raw json.RawMessage) string {
+    var name string
+    if json.Unmarshal(raw, &name) == nil {
+        return name
+    }
+    var names []string
+    json.Unmarshal(raw, &names)
+    for _, n := range names {
+        if n != "null" {
+            return n
+        }
+    }
+    return ""
+}
+
+// nonNull returns the single non-null member of a union, or nil when the
+// union is absent or has several non-null members
+func nonNull(variants []*jsonSchema) *jsonSchema {
+    var found *jsonSchema
+    for _, v := range variants {
+        if v != nil && schemaType(v.Type) == "null" {
+            continue
+        }
+        if found != nil {
+            return nil
+        }
+        found = v
+    }
+    return found
+}
@@ -0,0 +1,579 @@
+// Package openaicompat translates OpenAI chat-completions requests and
+// responses to and from this package's types, so Go code written against
+// an OpenAI SDK can move to Claude without rewriting its call sites:
+//
+//     adapter := openaicompat.New(client)
+//     resp, err := adapter.CreateChatCompletion(ctx, openaicompat.ChatCompletionRequest{
+//         Model:    "claude-3-5-sonnet-20241022",
+//         Messages: []openaicompat.ChatMessage{{Role: "user", Content: openaicompat.Text("Hello")}},
+//     })
+//     fmt.Println(resp.Choices[0].Message.Content.String())
+//
+// System messages become the system prompt, tools and legacy functions
+// become tool definitions, and response_format is honored by forcing
+// Claude to answer through a tool with the requested schema.
+//
+// ImportTools converts an existing function-calling catalog on its own, for
+// use with ChatWithTools:
+//
+//     tools, err := openaicompat.ImportToolsFile("functions.json")
+//     resp, err := client.ChatWithTools(ctx, prompt, &anthropic.MessageParams{Tools: tools}, handlers)
+package openaicompat
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+// respondTool is the synthetic tool Claude answers through when the request
+// asks for JSON output
+const respondTool = "respond"
+
+// ChatCompletionRequest is an OpenAI chat-completions request
+type ChatCompletionRequest struct {
+    Model               string               `json:"model"`
+    Messages            []ChatMessage        `json:"messages"`
+    MaxTokens           int                  `json:"max_tokens,omitempty"`
+    MaxCompletionTokens int                  `json:"max_completion_tokens,omitempty"` // Preferred over MaxTokens
+    Temperature         *float64             `json:"temperature,omitempty"`           // Clamped to Claude's range of 0 to 1
+    TopP                *float64             `json:"top_p,omitempty"`
+    Stop                StopSequences        `json:"stop,omitempty"`
+    N                   int                  `json:"n,omitempty"` // Only 1 is supported
+    Stream              bool                 `json:"stream,omitempty"`
+    Tools               []ToolDefinition     `json:"tools,omitempty"`
+    ToolChoice          *ToolChoice          `json:"tool_choice,omitempty"`
+    ParallelToolCalls   *bool                `json:"parallel_tool_calls,omitempty"`
+    Functions           []FunctionDefinition `json:"functions,omitempty"`     // Deprecated OpenAI field, still translated
+    FunctionCall        *ToolChoice          `json:"function_call,omitempty"` // Deprecated OpenAI field, still translated
+    ResponseFormat      *ResponseFormat      `json:"response_format,omitempty"`
+    User                string               `json:"user,omitempty"` // Sent as metadata.user_id
+}
+
+// ChatMessage is one message of an OpenAI conversation
+type ChatMessage struct {
+    Role         string        `json:"role"` // system, developer, user, assistant, tool or function
+    Content      Content       `json:"content"`
+    Name         string        `json:"name,omitempty"`
+    ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
+    ToolCallID   string        `json:"tool_call_id,omitempty"`
+    FunctionCall *FunctionCall `json:"function_call,omitempty"`
+}
+
+// Content is message content, which OpenAI sends either as a string or as
+// an array of parts
+type Content struct {
+    Parts []ContentPart
+}
+
+// ContentPart is one element of array-form content
+type ContentPart struct {
+    Type     string    `json:"type"` // "text" or "image_url"
+    Text     string    `json:"text,omitempty"`
+    ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL points at an image by URL or data: URL
+type ImageURL struct {
+    URL    string `json:"url"`
+    Detail string `json:"detail,omitempty"`
+}
+
+// Text returns content holding a single string
+func Text(text string) Content {
+    return Content{Parts: []ContentPart{{Type: "text", Text: text}}}
+}
+
+// String joins the text parts of the content
+func (c Content) String() string {
+    var b strings.Builder
+    for _, part := range c.Parts {
+        if part.Type == "text" {
+            b.WriteString(part.Text)
+        }
+    }
+    return b.String()
+}
+
+func (c Content) MarshalJSON() ([]byte, error) {
+    switch {
+    case len(c.Parts) == 0:
+        return []byte("null"), nil
+    case len(c.Parts) == 1 && c.Parts[0].Type == "text":
+        return json.Marshal(c.Parts[0].Text)
+    }
+    return json.Marshal(c.Parts)
+}
+
+func (c *Content) UnmarshalJSON(data []byte) error {
+    var text *string
+    if err := json.Unmarshal(data, &text); err == nil {
+        c.Parts = nil
+        if text != nil {
+            c.Parts = []ContentPart{{Type: "text", Text: *text}}
+        }
+        return nil
+    }
+    return json.Unmarshal(data, &c.Parts)
+}
+
+// StopSequences is the stop field, a string or an array of strings
+type StopSequences []string
+
+func (s *StopSequences) UnmarshalJSON(data []byte) error {
+    var one string
+    if err := json.Unmarshal(data, &one); err == nil {
+        *s = StopSequences{one}
+        return nil
+    }
+    return json.Unmarshal(data, (*[]string)(s))
+}
+
+// ToolDefinition is an entry of the tools field
+type ToolDefinition struct {
+    Type     string             `json:"type"` // Only "function" is supported
+    Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition declares a callable function with a JSON Schema for
+// its parameters
+type FunctionDefinition struct {
+    Name        string          `json:"name"`
+    Description string          `json:"description,omitempty"`
+    Parameters  json.RawMessage `json:"parameters,omitempty"`
+    Strict      bool            `json:"strict,omitempty"`
+}
+
+// ToolCall is a function call requested by the assistant
+type ToolCall struct {
+    ID       string       `json:"id"`
+    Type     string       `json:"type"`
+    Function FunctionCall `json:"function"`
+}
+
+// FunctionCall names a function and carries its arguments as a JSON string
+type FunctionCall struct {
+    Name      string `json:"name"`
+    Arguments string `json:"arguments"`
+}
+
+// ToolChoice is tool_choice or function_call: a mode ("none", "auto" or
+// "required") or a named function
+type ToolChoice struct {
+    Mode     string
+    Function string
+}
+
+func (t ToolChoice) MarshalJSON() ([]byte, error) {
+    if t.Function != "" {
+        return json.Marshal(map[string]interface{}{"type": "function", "function": map[string]string{"name": t.Function}})
+    }
+    return json.Marshal(t.Mode)
+}
+
+func (t *ToolChoice) UnmarshalJSON(data []byte) error {
+    if err := json.Unmarshal(data, &t.Mode); err == nil {
+        return nil
+    }
+    var named struct {
+        Name     string `json:"name"` // function_call form
+        Function struct {
+            Name string `json:"name"`
+        } `json:"function"`
+    }
+    if err := json.Unmarshal(data, &named); err != nil {
+        return err
+    }
+    t.Function = named.Function.Name
+    if t.Function == "" {
+        t.Function = named.Name
+    }
+    return nil
+}
+
+// ResponseFormat requests text, any JSON object, or JSON matching a schema
+type ResponseFormat struct {
+    Type       string            `json:"type"` // "text", "json_object" or "json_schema"
+    JSONSchema *JSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+// JSONSchemaFormat is the schema of a json_schema response format
+type JSONSchemaFormat struct {
+    Name        string          `json:"name"`
+    Description string          `json:"description,omitempty"`
+    Schema      json.RawMessage `json:"schema"`
+    Strict      bool            `json:"strict,omitempty"`
+}
+
+// ChatCompletionResponse is an OpenAI chat-completions response
+type ChatCompletionResponse struct {
+    ID      string   `json:"id"`
+    Object  string   `json:"object"`
+    Created int64    `json:"created"`
+    Model   string   `json:"model"`
+    Choices []Choice `json:"choices"`
+    Usage   Usage    `json:"usage"`
+}
+
+// Choice is one generated answer; Claude always produces exactly one
+type Choice struct {
+    Index        int         `json:"index"`
+    Message      ChatMessage `json:"message"`
+    FinishReason string      `json:"finish_reason"` // stop, length, tool_calls or function_call
+}
+
+// Usage reports the tokens of a completion
+type Usage struct {
+    PromptTokens     int `json:"prompt_tokens"`
+    CompletionTokens int `json:"completion_tokens"`
+    TotalTokens      int `json:"total_tokens"`
+}
+
+// Adapter serves OpenAI-style requests with an AnthropicClient. Requests
+// are stateless, as in the OpenAI API, and count toward the client's usage
+// and budget.
+type Adapter struct {
+    client *anthropic.AnthropicClient
+}
+
+// New creates an adapter for client
+func New(client *anthropic.AnthropicClient) *Adapter {
+    return &Adapter{client: client}
+}
+
+// CreateChatCompletion translates req, sends it to Claude, and translates
+// the reply back. Streaming and n > 1 are not supported.
+func (a *Adapter) CreateChatCompletion(ctx context.Context, req ChatCompletionRequest) (ChatCompletionResponse, error) {
+    converted, err := Convert(req)
+    if err != nil {
+        return ChatCompletionResponse{}, err
+    }
+    resp, err := a.client.Messages(ctx, converted.Messages, &converted.Params)
+    if err != nil {
+        return ChatCompletionResponse{}, err
+    }
+    return converted.Response(resp), nil
+}
+
+// Converted is an OpenAI request in this package's terms, ready for
+// AnthropicClient.Messages
+type Converted struct {
+    Messages []anthropic.Message
+    Params   anthropic.MessageParams
+
+    functions bool // The request used the legacy functions fields
+    respond   bool // The answer arrives through the respond tool
+}
+
+// Convert translates an OpenAI request. It fails for features Claude has
+// no equivalent for, such as n > 1, and for malformed messages.
+func Convert(req ChatCompletionRequest) (*Converted, error) {
+    switch {
+    case req.Stream:
+        return nil, fmt.Errorf("openaicompat: streaming is not supported")
+    case req.N > 1:
+        return nil, fmt.Errorf("openaicompat: n > 1 is not supported")
+    }
+
+    c := &Converted{functions: len(req.Functions) > 0 || req.FunctionCall != nil}
+    c.Params.Model = req.Model
+    c.Params.MaxTokens = req.MaxTokens
+    if req.MaxCompletionTokens > 0 {
+        c.Params.MaxTokens = req.MaxCompletionTokens
+    }
+    if req.Temperature != nil {
+        c.Params.Temperature = anthropic.Float(clamp(*req.Temperature, 0, 1))
+    }
+    c.Params.TopP = req.TopP
+    c.Params.StopSequences = req.Stop
+    if req.User != "" {
+        c.Params.Metadata = map[string]interface{}{"user_id": req.User}
+    }
+
+    if err := c.convertMessages(req.Messages); err != nil {
+        return nil, err
+    }
+    if err := c.convertTools(req); err != nil {
+        return nil, err
+    }
+    return c, nil
+}
+
+func (c *Converted) convertMessages(messages []ChatMessage) error {
+    var system []string
+    var out []anthropic.Message
+    // Legacy function messages answer the latest call of the same name
+    functionCalls := make(map[string]string)
+
+    for i, msg := range messages {
+        switch msg.Role {
+        case "system", "developer":
+            if text := msg.Content.String(); text != "" {
+                system = append(system, text)
+            }
+
+        case "user":
+            content, err := userContent(msg.Content)
+            if err != nil {
+                return fmt.Errorf("openaicompat: message %d: %w", i, err)
+            }
+            out = append(out, anthropic.Message{Role: anthropic.RoleUser, Content: content})
+
+        case "assistant":
+            var content []anthropic.MessageContent
+            if text := msg.Content.String(); text != "" {
+                content = append(content, anthropic.MessageContent{Type: anthropic.ContentTypeText, Text: text})
+            }
+            for _, call := range msg.ToolCalls {
+                content = append(content, toolUse(call.ID, call.Function))
+            }
+            if msg.FunctionCall != nil {
+                id := fmt.Sprintf("call_%d", i)
+                functionCalls[msg.FunctionCall.Name] = id
+                content = append(content, toolUse(id, *msg.FunctionCall))
+            }
+            out = append(out, anthropic.Message{Role: anthropic.RoleAssistant, Content: content})
+
+        case "tool", "function":
+            id := msg.ToolCallID
+            if msg.Role == "function" {
+                id = functionCalls[msg.Name]
+            }
+            if id == "" {
+                return fmt.Errorf("openaicompat: message %d answers no known tool call", i)
+            }
+            out = append(out, anthropic.Message{Role: anthropic.RoleUser, Content: []anthropic.MessageContent{{
+                Type:      anthropic.ContentTypeToolResult,
+                ToolUseID: id,
+                Content:   msg.Content.String(),
+            }}})
+
+        default:
+            return fmt.Errorf("openaicompat: message %d has unsupported role %q", i, msg.Role)
+        }
+    }
+
+    c.Params.System = strings.Join(system, "\n\n")
+    c.Messages = anthropic.RepairConversation(out)
+    if len(c.Messages) == 0 {
+        return fmt.Errorf("openaicompat: request has no user or assistant messages")
+    }
+    return nil
+}
+
+// userContent converts text and image parts
+func userContent(content Content) ([]anthropic.MessageContent, error) {
+    var blocks []anthropic.MessageContent
+    for _, part := range content.Parts {
+        switch part.Type {
+        case "text":
+            if part.Text != "" {
+                blocks = append(blocks, anthropic.MessageContent{Type: anthropic.ContentTypeText, Text: part.Text})
+            }
+        case "image_url":
+            if part.ImageURL == nil || part.ImageURL.URL == "" {
+                return nil, fmt.Errorf("image_url part has no url")
+            }
+            source, err := imageSource(part.ImageURL.URL)
+            if err != nil {
+                return nil, err
+            }
+            blocks = append(blocks, anthropic.MessageContent{Type: anthropic.ContentTypeImage, Source: source})
+        default:
+            return nil, fmt.Errorf("unsupported content part %q", part.Type)
+        }
+    }
+    return blocks, nil
+}
+
+// imageSource turns a URL or base64 data: URL into an image source
+func imageSource(url string) (*anthropic.ImageSource, error) {
+    if !strings.HasPrefix(url, "data:") {
+        return &anthropic.ImageSource{Type: "url", URL: url}, nil
+    }
+    header, data, ok := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+    mediaType, encoding, _ := strings.Cut(header, ";")
+    if !ok || encoding != "base64" {
+        return nil, fmt.Errorf("image data URLs must be base64-encoded")
+    }
+    return &anthropic.ImageSource{Type: "base64", MediaType: mediaType, Data: data}, nil
+}
+
+func toolUse(id string, call FunctionCall) anthropic.MessageContent {
+    input := json.RawMessage(call.Arguments)
+    if strings.TrimSpace(call.Arguments) == "" || !json.Valid(input) {
+        input = json.RawMessage("{}")
+    }
+    return anthropic.MessageContent{Type: anthropic.ContentTypeToolUse, ID: id, Name: call.Name, Input: input}
+}
+
+func (c *Converted) convertTools(req ChatCompletionRequest) error {
+    functions := append([]FunctionDefinition{}, req.Functions...)
+    for _, tool := range req.Tools {
+        if tool.Type != "" && tool.Type != "function" {
+            return fmt.Errorf("openaicompat: unsupported tool type %q", tool.Type)
+        }
+        functions = append(functions, tool.Function)
+    }
+    for _, fn := range functions {
+        tool, err := functionTool(fn)
+        if err != nil {
+            return fmt.Errorf("openaicompat: %w", err)
+        }
+        c.Params.Tools = append(c.Params.Tools, tool)
+    }
+
+    choice := req.ToolChoice
+    if choice == nil {
+        choice = req.FunctionCall
+    }
+    if choice != nil {
+        switch {
+        case choice.Function != "":
+            c.Params.ToolChoice = &anthropic.ToolChoice{Type: anthropic.ToolChoiceTool, Name: choice.Function}
+        case choice.Mode == "required":
+            c.Params.ToolChoice = &anthropic.ToolChoice{Type: "any"}
+        case choice.Mode == "none":
+            c.Params.ToolChoice = &anthropic.ToolChoice{Type: anthropic.ToolChoiceNone}
+        case choice.Mode == "auto", choice.Mode == "":
+        default:
+            return fmt.Errorf("openaicompat: unsupported tool_choice %q", choice.Mode)
+        }
+    }
+
+    if err := c.convertResponseFormat(req.ResponseFormat); err != nil {
+        return err
+    }
+    if req.ParallelToolCalls != nil && !*req.ParallelToolCalls && len(c.Params.Tools) > 0 {
+        if c.Params.ToolChoice == nil {
+            c.Params.ToolChoice = &anthropic.ToolChoice{Type: anthropic.ToolChoiceAuto}
+        }
+        c.Params.ToolChoice.DisableParallel = true
+    }
+    return nil
+}
+
+// convertResponseFormat makes Claude answer JSON requests through the
+// respond tool. With other tools present Claude must call one of them or
+// respond; otherwise it is forced to respond.
+func (c *Converted) convertResponseFormat(format *ResponseFormat) error {
+    if format == nil || format.Type == "" || format.Type == "text" {
+        return nil
+    }
+    tool := anthropic.Tool{
+        Name:        respondTool,
+        Description: "Give your final answer by calling this tool. Its input is your complete response.",
+        InputSchema: anthropic.InputSchema{Type: "object", Properties: map[string]anthropic.Property{}, Required: []string{}},
+    }
+    switch format.Type {
+    case "json_object":
+    case "json_schema":
+        if format.JSONSchema == nil || len(format.JSONSchema.Schema) == 0 {
+            return fmt.Errorf("openaicompat: json_schema response format has no schema")
+        }
+        schema, err := parseSchema(format.JSONSchema.Schema)
+        if err != nil {
+            return fmt.Errorf("openaicompat: response format %s: %w", format.JSONSchema.Name, err)
+        }
+        tool.InputSchema = schema
+        if format.JSONSchema.Description != "" {
+            tool.Description += " " + format.JSONSchema.Description
+        }
+    default:
+        return fmt.Errorf("openaicompat: unsupported response format %q", format.Type)
+    }
+
+    c.respond = true
+    if len(c.Params.Tools) == 0 {
+        c.Params.ToolChoice = &anthropic.ToolChoice{Type: anthropic.ToolChoiceTool, Name: respondTool}
+    } else if c.Params.ToolChoice == nil || c.Params.ToolChoice.Type == anthropic.ToolChoiceAuto {
+        c.Params.ToolChoice = &anthropic.ToolChoice{Type: "any"}
+    }
+    c.Params.Tools = append(c.Params.Tools, tool)
+    return nil
+}
+
+// functionTool converts a function definition into a tool
+func functionTool(fn FunctionDefinition) (anthropic.Tool, error) {
+    tool := anthropic.Tool{Name: fn.Name, Description: fn.Description}
+    tool.InputSchema = anthropic.InputSchema{Type: "object", Properties: map[string]anthropic.Property{}, Required: []string{}}
+    if len(fn.Parameters) > 0 {
+        schema, err := parseSchema(fn.Parameters)
+        if err != nil {
+            return anthropic.Tool{}, fmt.Errorf("function %s: %w", fn.Name, err)
+        }
+        tool.InputSchema = schema
+    }
+    // The client requires at least one property; allow calls without arguments
+    if len(tool.InputSchema.Properties) == 0 {
+        tool.InputSchema.Properties = map[string]anthropic.Property{
+            placeholderArg: {Type: "string", Description: "Unused; this function takes no parameters"},
+        }
+    }
+    return tool, nil
+}
+
+// Response translates Claude's reply to the converted request
+func (c *Converted) Response(resp *anthropic.AnthropicResponse) ChatCompletionResponse {
+    message := ChatMessage{Role: "assistant"}
+    finish := finishReason(resp.StopReason)
+    var text strings.Builder
+    for _, block := range resp.Content {
+        switch {
+        case block.Type == anthropic.ContentTypeText:
+            text.WriteString(block.Text)
+        case block.Type == anthropic.ContentTypeToolUse && c.respond && block.Name == respondTool:
+            // The structured answer replaces any text before it
+            text.Reset()
+            text.Write(block.Input)
+            finish = "stop"
+        case block.Type == anthropic.ContentTypeToolUse:
+            call := FunctionCall{Name: block.Name, Arguments: string(StripPlaceholder(block.Input))}
+            if c.functions {
+                message.FunctionCall = &call
+                finish = "function_call"
+                continue
+            }
+            message.ToolCalls = append(message.ToolCalls, ToolCall{ID: block.ID, Type: "function", Function: call})
+            finish = "tool_calls"
+        }
+    }
+    if text.Len() > 0 {
+        message.Content = Text(text.String())
+    }
+
+    u := resp.Usage
+    prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+    return ChatCompletionResponse{
+        ID:      resp.ID,
+        Object:  "chat.completion",
+        Created: time.Now().Unix(),
+        Model:   resp.Model,
+        Choices: []Choice{{Index: 0, Message: message, FinishReason: finish}},
+        Usage:   Usage{PromptTokens: prompt, CompletionTokens: u.OutputTokens, TotalTokens: prompt + u.OutputTokens},
+    }
+}
+
+func finishReason(reason anthropic.StopReason) string {
+    switch reason {
+    case anthropic.StopReasonMaxTokens:
+        return "length"
+    case anthropic.StopReasonToolUse:
+        return "tool_calls"
+    }
+    return "stop"
+}
+
+func clamp(v, lo, hi float64) float64 {
+    if v < lo {
+        return lo
+    }
+    if v > hi {
+        return hi
+    }
+    return v
+}
@@ -0,0 +1,518 @@
+// Package openapitools converts OpenAPI 3 operations into Anthropic tool
+// definitions backed by HTTP handlers, so an existing REST API can be exposed
+// to Claude with a few lines of configuration:
+//
+//     handlers, err := openapitools.LoadFile("petstore.yaml", openapitools.Config{
+//         BaseURL: "https://petstore.example.com/v1",
+//         Headers: map[string]string{"Authorization": "Bearer " + token},
+//     })
+//     for _, h := range handlers {
+//         registry.Register(h)
+//     }
+//
+// Path, query, and header parameters become top-level input properties; a JSON
+// request body is passed as the "body" property ("request_body" when a
+// parameter is already called body). Header parameters named in
+// Config.Headers are left out, so the model cannot replace configured
+// credentials.
+package openapitools
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "regexp"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/rdhillbb/anthropic"
+    "gopkg.in/yaml.v3"
+)
+
+const (
+    defaultMaxResponseBytes = 1 << 20
+    defaultTimeout          = 30 * time.Second
+    maxRefDepth             = 16
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// Config controls how operations are exposed and executed
+type Config struct {
+    BaseURL          string            // Overrides the first server URL in the spec
+    HTTPClient       *http.Client      // Defaults to a client with a 30s timeout
+    Headers          map[string]string // Sent with every request, e.g. authorization; spec parameters cannot override them
+    Operations       []string          // Optional allowlist of operationIds
+    MaxResponseBytes int64             // Response bodies are truncated beyond this size
+}
+
+// Load parses a JSON or YAML OpenAPI 3 document and returns one ToolHandler per operation
+func Load(spec []byte, cfg Config) ([]anthropic.ToolHandler, error) {
+    doc, err := parseDocument(spec)
+    if err != nil {
+        return nil, err
+    }
+
+    baseURL := cfg.BaseURL
+    if baseURL == "" && len(doc.Servers) > 0 {
+        baseURL = doc.Servers[0].URL
+    }
+    if baseURL == "" {
+        return nil, fmt.Errorf("no base URL configured and spec declares no servers")
+    }
+    baseURL = strings.TrimRight(baseURL, "/")
+
+    httpClient := cfg.HTTPClient
+    if httpClient == nil {
+        httpClient = &http.Client{Timeout: defaultTimeout}
+    }
+    maxBytes := cfg.MaxResponseBytes
+    if maxBytes <= 0 {
+        maxBytes = defaultMaxResponseBytes
+    }
+    allowed := make(map[string]bool, len(cfg.Operations))
+    for _, id := range cfg.Operations {
+        allowed[id] = true
+    }
+
+    // Iterate in a stable order so generated tool lists are deterministic
+    paths := make([]string, 0, len(doc.Paths))
+    for p := range doc.Paths {
+        paths = append(paths, p)
+    }
+    sort.Strings(paths)
+
+    var handlers []anthropic.ToolHandler
+    for _, p := range paths {
+        item := doc.Paths[p]
+        for _, method := range []string{"get", "put", "post", "delete", "patch", "head", "options"} {
+            op := item.operation(method)
+            if op == nil {
+                continue
+            }
+            if len(allowed) > 0 && !allowed[op.OperationID] {
+                continue
+            }
+
+            h, err := newOperationTool(doc, p, method, item.Parameters, op, cfg.Headers)
+            if err != nil {
+                return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), p, err)
+            }
+            h.baseURL = baseURL
+            h.client = httpClient
+            h.maxBytes = maxBytes
+            handlers = append(handlers, h)
+        }
+    }
+    return handlers, nil
+}
+
+// LoadFile reads an OpenAPI document from disk and converts it like Load
+func LoadFile(filename string, cfg Config) ([]anthropic.ToolHandler, error) {
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        return nil, fmt.Errorf("error reading OpenAPI spec: %w", err)
+    }
+    return Load(data, cfg)
+}
+
+// Register converts the spec and registers every resulting tool in registry
+func Register(registry *anthropic.ToolRegistry, spec []byte, cfg Config) error {
+    handlers, err := Load(spec, cfg)
+    if err != nil {
+        return err
+    }
+    for _, h := range handlers {
+        if err := registry.Register(h); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// OpenAPI document model (only the parts needed for tool generation)
+
+type document struct {
+    Servers []struct {
+        URL string `json:"url"`
+    } `json:"servers"`
+    Paths      map[string]pathItem `json:"paths"`
+    Components struct {
+        Schemas    map[string]*schema    `json:"schemas"`
+        Parameters map[string]*parameter `json:"parameters"`
+    } `json:"components"`
+}
+
+type pathItem struct {
+    Parameters []*parameter `json:"parameters"`
+    Get        *operation   `json:"get"`
+    Put        *operation   `json:"put"`
+    Post       *operation   `json:"post"`
+    Delete     *operation   `json:"delete"`
+    Patch      *operation   `json:"patch"`
+    Head       *operation   `json:"head"`
+    Options    *operation   `json:"options"`
+}
+
+func (p pathItem) operation(method string) *operation {
+    switch method {
+    case "get":
+        return p.Get
+    case "put":
+        return p.Put
+    case "post":
+        return p.Post
+    case "delete":
+        return p.Delete
+    case "patch":
+        return p.Patch
+    case "head":
+        return p.Head
+    case "options":
+        return p.Options
+    }
+    return nil
+}
+
+type operation struct {
+    OperationID string       `json:"operationId"`
+    Summary     string       `json:"summary"`
+    Description string       `json:"description"`
+    Parameters  []*parameter `json:"parameters"`
+    RequestBody *struct {
+        Description string `json:"description"`
+        Required    bool   `json:"required"`
+        Content     map[string]struct {
+            Schema *schema `json:"schema"`
+        } `json:"content"`
+    } `json:"requestBody"`
+}
+
+type parameter struct {
+    Ref         string  `json:"$ref"`
+    Name        string  `json:"name"`
+    In          string  `json:"in"`
+    Description string  `json:"description"`
+    Required    bool    `json:"required"`
+    Schema      *schema `json:"schema"`
+}
+
+type schema struct {
+    Ref         string             `json:"$ref"`
+    Type        string             `json:"type"`
+    Description string             `json:"description"`
+    Enum        []interface{}      `json:"enum"`
+    Items       *schema            `json:"items"`
+    Properties  map[string]*schema `json:"properties"`
+    Required    []string           `json:"required"`
+}
+
+func parseDocument(spec []byte) (*document, error) {
+    var doc document
+    if err := json.Unmarshal(spec, &doc); err != nil {
+        // Not JSON; round-trip YAML through JSON so the json tags above apply
+        var raw interface{}
+        if yamlErr := yaml.Unmarshal(spec, &raw); yamlErr != nil {
+            return nil, fmt.Errorf("error parsing OpenAPI spec: %w", yamlErr)
+        }
+        converted, err := json.Marshal(raw)
+        if err != nil {
+            return nil, fmt.Errorf("error converting OpenAPI spec: %w", err)
+        }
+        if err := json.Unmarshal(converted, &doc); err != nil {
+            return nil, fmt.Errorf("error parsing OpenAPI spec: %w", err)
+        }
+    }
+    if len(doc.Paths) == 0 {
+        return nil, fmt.Errorf("OpenAPI spec defines no paths")
+    }
+    return &doc, nil
+}
+
+// resolveSchema follows local component references
+func (d *document) resolveSchema(s *schema, depth int) (*schema, error) {
+    for s != nil && s.Ref != "" {
+        if depth > maxRefDepth {
+            return nil, fmt.Errorf("schema reference %s nests too deeply", s.Ref)
+        }
+        name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+        target, exists := d.Components.Schemas[name]
+        if name == s.Ref || !exists {
+            return nil, fmt.Errorf("unresolvable schema reference %s", s.Ref)
+        }
+        s = target
+        depth++
+    }
+    return s, nil
+}
+
+func (d *document) resolveParameter(p *parameter) (*parameter, error) {
+    if p.Ref == "" {
+        return p, nil
+    }
+    name := strings.TrimPrefix(p.Ref, "#/components/parameters/")
+    target, exists := d.Components.Parameters[name]
+    if name == p.Ref || !exists {
+        return nil, fmt.Errorf("unresolvable parameter reference %s", p.Ref)
+    }
+    return target, nil
+}
+
+// toProperty converts an OpenAPI schema into the package's Property type
+func (d *document) toProperty(s *schema, description string, depth int) (anthropic.Property, error) {
+    s, err := d.resolveSchema(s, depth)
+    if err != nil {
+        return anthropic.Property{}, err
+    }
+    if s == nil {
+        return anthropic.Property{Type: "string", Description: description}, nil
+    }
+    if depth > maxRefDepth {
+        return anthropic.Property{}, fmt.Errorf("schema nests too deeply")
+    }
+
+    prop := anthropic.Property{
+        Type:        s.Type,
+        Description: s.Description,
+        Required:    s.Required,
+    }
+    if description != "" {
+        prop.Description = description
+    }
+    for _, v := range s.Enum {
+        prop.Enum = append(prop.Enum, fmt.Sprint(v))
+    }
+    if s.Items != nil {
+        items, err := d.toProperty(s.Items, "", depth+1)
+        if err != nil {
+            return anthropic.Property{}, err
+        }
+        prop.Items = &items
+    }
+    if len(s.Properties) > 0 {
+        prop.Properties = make(map[string]anthropic.Property, len(s.Properties))
+        for name, child := range s.Properties {
+            childProp, err := d.toProperty(child, "", depth+1)
+            if err != nil {
+                return anthropic.Property{}, err
+            }
+            prop.Properties[name] = childProp
+        }
+    }
+    return prop, nil
+}
+
+// operationTool is the HTTP-backed ToolHandler for a single operation
+type operationTool struct {
+    def      anthropic.Tool
+    method   string
+    path     string
+    params   []*parameter
+    hasBody  bool
+    bodyArg  string // Input property holding the request body
+    baseURL  string
+    client   *http.Client
+    headers  map[string]string
+    maxBytes int64
+}
+
+func newOperationTool(doc *document, p, method string, shared []*parameter, op *operation, headers map[string]string) (*operationTool, error) {
+    name := op.OperationID
+    if name == "" {
+        name = method + "_" + p
+    }
+    name = strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+    if len(name) > 64 {
+        name = name[:64]
+    }
+
+    description := strings.TrimSpace(strings.Join([]string{op.Summary, op.Description}, "\n\n"))
+    if description == "" {
+        description = fmt.Sprintf("Calls %s %s", strings.ToUpper(method), p)
+    }
+
+    t := &operationTool{
+        method:  strings.ToUpper(method),
+        path:    p,
+        headers: headers,
+    }
+    configured := map[string]bool{}
+    for name := range headers {
+        configured[http.CanonicalHeaderKey(name)] = true
+    }
+    schema := anthropic.InputSchema{
+        Type:       "object",
+        Properties: map[string]anthropic.Property{},
+    }
+
+    // Operation-level parameters override path-level ones with the same name and location
+    byKey := map[string]*parameter{}
+    var keys []string
+    for _, raw := range append(append([]*parameter{}, shared...), op.Parameters...) {
+        param, err := doc.resolveParameter(raw)
+        if err != nil {
+            return nil, err
+        }
+        if param.In == "cookie" {
+            continue
+        }
+        // Headers set by the operator, such as credentials, are never left to the model
+        if param.In == "header" && configured[http.CanonicalHeaderKey(param.Name)] {
+            continue
+        }
+        key := param.In + ":" + param.Name
+        if _, exists := byKey[key]; !exists {
+            keys = append(keys, key)
+        }
+        byKey[key] = param
+    }
+    for _, key := range keys {
+        param := byKey[key]
+        prop, err := doc.toProperty(param.Schema, param.Description, 0)
+        if err != nil {
+            return nil, fmt.Errorf("parameter %s: %w", param.Name, err)
+        }
+        schema.Properties[param.Name] = prop
+        if param.Required || param.In == "path" {
+            schema.Required = append(schema.Required, param.Name)
+        }
+        t.params = append(t.params, param)
+    }
+
+    if op.RequestBody != nil {
+        if media, exists := op.RequestBody.Content["application/json"]; exists {
+            prop, err := doc.toProperty(media.Schema, op.RequestBody.Description, 0)
+            if err != nil {
+                return nil, fmt.Errorf("request body: %w", err)
+            }
+            if prop.Description == "" {
+                prop.Description = "JSON request body"
+            }
+            // A parameter may already be called "body"
+            t.bodyArg = "body"
+            for {
+                if _, taken := schema.Properties[t.bodyArg]; !taken {
+                    break
+                }
+                t.bodyArg = "request_" + t.bodyArg
+            }
+            schema.Properties[t.bodyArg] = prop
+            if op.RequestBody.Required {
+                schema.Required = append(schema.Required, t.bodyArg)
+            }
+            t.hasBody = true
+        }
+    }
+
+    // The API requires at least one property; allow calls without arguments
+    if len(schema.Properties) == 0 {
+        schema.Properties["_"] = anthropic.Property{
+            Type:        "string",
+            Description: "Unused; this operation takes no parameters",
+        }
+    }
+
+    t.def = anthropic.Tool{
+        Name:        name,
+        Description: description,
+        InputSchema: schema,
+    }
+    return t, nil
+}
+
+func (t *operationTool) Definition() anthropic.Tool {
+    return t.def
+}
+
+// Execute maps the tool input onto the HTTP request and returns the response body
+func (t *operationTool) Execute(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+    args := map[string]json.RawMessage{}
+    if len(input) > 0 {
+        if err := json.Unmarshal(input, &args); err != nil {
+            return anthropic.ToolResult{}, fmt.Errorf("invalid tool input: %w", err)
+        }
+    }
+
+    reqPath := t.path
+    query := url.Values{}
+    headers := http.Header{}
+    for _, param := range t.params {
+        raw, exists := args[param.Name]
+        if !exists {
+            continue
+        }
+        value := scalarString(raw)
+        switch param.In {
+        case "path":
+            reqPath = strings.ReplaceAll(reqPath, "{"+param.Name+"}", url.PathEscape(value))
+        case "query":
+            query.Set(param.Name, value)
+        case "header":
+            headers.Set(param.Name, value)
+        }
+    }
+
+    reqURL := t.baseURL + reqPath
+    if len(query) > 0 {
+        reqURL += "?" + query.Encode()
+    }
+
+    var body io.Reader
+    if raw, exists := args[t.bodyArg]; exists && t.hasBody {
+        body = bytes.NewReader(raw)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, t.method, reqURL, body)
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error creating request: %w", err)
+    }
+    for k := range headers {
+        req.Header.Set(k, headers.Get(k))
+    }
+    // Configured headers go last so spec parameters cannot replace them
+    for k, v := range t.headers {
+        req.Header.Set(k, v)
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+    req.Header.Set("Accept", "application/json")
+
+    resp, err := t.client.Do(req)
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error calling %s %s: %w", t.method, reqPath, err)
+    }
+    defer resp.Body.Close()
+
+    data, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBytes+1))
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error reading response: %w", err)
+    }
+    content := string(data)
+    if int64(len(data)) > t.maxBytes {
+        content = string(data[:t.maxBytes]) + "\n[response truncated]"
+    }
+
+    if resp.StatusCode >= 400 {
+        return anthropic.ToolResult{
+            Content: fmt.Sprintf("HTTP %d: %s", resp.StatusCode, content),
+            IsError: true,
+        }, nil
+    }
+    return anthropic.ToolResult{Content: content}, nil
+}
+
+// scalarString renders a JSON value for use in a path, query, or header
+func scalarString(raw json.RawMessage) string {
+    var s string
+    if err := json.Unmarshal(raw, &s); err == nil {
+        return s
+    }
+    return strings.TrimSpace(string(raw))
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+    "context"
+    "crypto/subtle"
+    "errors"
+    "net/http"
+    "strings"
+)
+
+// ErrUnauthorized is returned by an Authenticator that rejects a request
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Authenticator identifies the user making a request, or returns an error
+// to reject it
+type Authenticator func(r *http.Request) (user string, err error)
+
+type userKey struct{}
+
+// RequireAuth wraps next so that only requests accepted by auth reach it.
+// Rejected requests get a 401 in the API's error format. The user is stored
+// on the request context, where the Server reads it to keep each user's
+// sessions apart.
+func RequireAuth(auth Authenticator, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        user, err := auth(r)
+        if err != nil {
+            w.Header().Set("WWW-Authenticate", `Bearer realm="anthropic"`)
+            writeError(w, http.StatusUnauthorized, "authentication_error", err.Error())
+            return
+        }
+        next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+    })
+}
+
+// UserFromContext returns the user set by RequireAuth, or "" for requests
+// that did not pass through it
+func UserFromContext(ctx context.Context) string {
+    user, _ := ctx.Value(userKey{}).(string)
+    return user
+}
+
+// BearerTokens accepts requests whose Authorization header carries one of
+// the tokens, identifying the caller as the user the token maps to
+func BearerTokens(tokens map[string]string) Authenticator {
+    return func(r *http.Request) (string, error) {
+        header := r.Header.Get("Authorization")
+        if !strings.HasPrefix(header, "Bearer ") {
+            return "", ErrUnauthorized
+        }
+        presented := []byte(strings.TrimPrefix(header, "Bearer "))
+        // Every token is compared, in constant time, so timing reveals nothing
+        var user string
+        found := false
+        for token, u := range tokens {
+            if subtle.ConstantTimeCompare(presented, []byte(token)) == 1 {
+                user, found = u, true
+            }
+        }
+        if !found {
+            return "", ErrUnauthorized
+        }
+        return user, nil
+    }
+}
@@ -0,0 +1,505 @@
+// Package server exposes an AnthropicClient over HTTP, so a team can stand
+// up an internal Claude proxy in a few lines:
+//
+//     srv := server.New(client, server.Config{SystemPrompt: "You are a helpful assistant."})
+//     auth := server.BearerTokens(map[string]string{os.Getenv("PROXY_TOKEN"): "team"})
+//     log.Fatal(http.ListenAndServe(":8080", server.RequireAuth(auth, srv)))
+//
+// The handler serves:
+//
+//     POST   /chat           send a message and wait for the reply
+//     POST   /chat/stream    send a message and stream the reply as server-sent events
+//     GET    /chat/ws        chat over a WebSocket, with replies streamed as JSON frames
+//     POST   /sessions       start a session
+//     GET    /sessions       list the caller's sessions
+//     GET    /sessions/{id}  fetch a session's history
+//     DELETE /sessions/{id}  end a session
+//
+// Requests go through the client, so its credentials, retries, rate limit,
+// guardrails, budget and usage tracking apply. Sessions are held in memory;
+// behind RequireAuth each one belongs to the user that created it.
+package server
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    defaultSessionTTL   = 30 * time.Minute
+    defaultMaxSessions  = 1000
+    defaultMaxBodyBytes = 1 << 20
+)
+
+// Config configures a Server
+type Config struct {
+    Params       *anthropic.MessageParams // Parameters for every call; nil uses the client's defaults
+    SystemPrompt string                   // System prompt of sessions that don't set their own
+    Tools        *anthropic.ToolRegistry  // Tools Claude may call; nil disables tool use
+    SessionTTL   time.Duration            // Idle sessions are dropped after this; defaults to 30m
+    MaxSessions  int                      // Beyond this the least recently used session is dropped; defaults to 1000
+    MaxBodyBytes int64                    // Larger request bodies and WebSocket messages are rejected; defaults to 1 MiB
+
+    // AllowedOrigins lists the browser origins, such as
+    // "https://chat.example.com", that may open a WebSocket. When empty,
+    // only pages served from the same host may.
+    AllowedOrigins []string
+}
+
+// Server is an http.Handler serving chat sessions backed by a client
+type Server struct {
+    client *anthropic.AnthropicClient
+    cfg    Config
+    now    func() time.Time
+
+    mu       sync.Mutex
+    sessions map[string]*sessionEntry
+}
+
+type sessionEntry struct {
+    session  *anthropic.Session
+    owner    string
+    created  time.Time
+    lastUsed time.Time
+}
+
+// New creates a Server for client
+func New(client *anthropic.AnthropicClient, cfg Config) *Server {
+    if cfg.SessionTTL <= 0 {
+        cfg.SessionTTL = defaultSessionTTL
+    }
+    if cfg.MaxSessions <= 0 {
+        cfg.MaxSessions = defaultMaxSessions
+    }
+    if cfg.MaxBodyBytes <= 0 {
+        cfg.MaxBodyBytes = defaultMaxBodyBytes
+    }
+    return &Server{
+        client:   client,
+        cfg:      cfg,
+        now:      time.Now,
+        sessions: make(map[string]*sessionEntry),
+    }
+}
+
+// ChatRequest is the body of POST /chat and POST /chat/stream. An empty
+// SessionID starts a new session; System only applies to new sessions.
+type ChatRequest struct {
+    SessionID string `json:"session_id,omitempty"`
+    Message   string `json:"message"`
+    System    string `json:"system,omitempty"`
+}
+
+// ChatResponse is the reply to POST /chat, and the final "done" event of
+// POST /chat/stream
+type ChatResponse struct {
+    SessionID  string                     `json:"session_id"`
+    ID         string                     `json:"id"`
+    Model      string                     `json:"model"`
+    Text       string                     `json:"text"`
+    Content    []anthropic.MessageContent `json:"content"`
+    StopReason anthropic.StopReason       `json:"stop_reason"`
+    Usage      anthropic.Usage            `json:"usage"`
+}
+
+// SessionInfo describes a session in the /sessions endpoints
+type SessionInfo struct {
+    SessionID string              `json:"session_id"`
+    System    string              `json:"system,omitempty"`
+    Created   time.Time           `json:"created"`
+    LastUsed  time.Time           `json:"last_used"`
+    Messages  []anthropic.Message `json:"messages,omitempty"`
+}
+
+// ErrorResponse is the body of every failed request, in the shape of the
+// API's own errors
+type ErrorResponse struct {
+    Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes a failed request
+type ErrorDetail struct {
+    Type    string `json:"type"`
+    Message string `json:"message"`
+}
+
+// ServeHTTP routes a request to the chat and session endpoints
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := strings.TrimSuffix(r.URL.Path, "/")
+    switch {
+    case path == "/chat":
+        s.allow(w, r, http.MethodPost, s.handleChat)
+    case path == "/chat/stream":
+        s.allow(w, r, http.MethodPost, s.handleChatStream)
+    case path == "/chat/ws":
+        s.allow(w, r, http.MethodGet, s.handleWebSocket)
+    case path == "/sessions":
+        switch r.Method {
+        case http.MethodPost:
+            s.handleCreateSession(w, r)
+        case http.MethodGet:
+            s.handleListSessions(w, r)
+        default:
+            methodNotAllowed(w, "GET, POST")
+        }
+    case strings.HasPrefix(path, "/sessions/"):
+        id := strings.TrimPrefix(path, "/sessions/")
+        switch r.Method {
+        case http.MethodGet:
+            s.handleGetSession(w, r, id)
+        case http.MethodDelete:
+            s.handleDeleteSession(w, r, id)
+        default:
+            methodNotAllowed(w, "GET, DELETE")
+        }
+    default:
+        writeError(w, http.StatusNotFound, "not_found_error", "no such endpoint: "+r.URL.Path)
+    }
+}
+
+func (s *Server) allow(w http.ResponseWriter, r *http.Request, method string, handler http.HandlerFunc) {
+    if r.Method != method {
+        methodNotAllowed(w, method)
+        return
+    }
+    handler(w, r)
+}
+
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+    req, entry, ok := s.chatRequest(w, r)
+    if !ok {
+        return
+    }
+
+    var opts []anthropic.RequestOption
+    if key := r.Header.Get("Idempotency-Key"); key != "" {
+        opts = append(opts, anthropic.WithIdempotencyKey(entry.owner+"/"+entry.session.ID()+"/"+key))
+    }
+    var resp *anthropic.AnthropicResponse
+    var err error
+    if s.cfg.Tools != nil {
+        opts = append(opts, anthropic.WithTools(s.cfg.Tools.Tools()...))
+        resp, err = entry.session.AChatWithTools(r.Context(), req.Message, s.cfg.Params, s.cfg.Tools.Handlers(), opts...)
+    } else {
+        resp, err = entry.session.ChatMe(r.Context(), req.Message, s.cfg.Params, opts...)
+    }
+    s.touch(entry)
+    if err != nil {
+        writeChatError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, chatResponse(entry, resp))
+}
+
+// handleChatStream relays the stream events in the API's own SSE format,
+// with a tool_result event after each tool call, followed by a "done" event
+// carrying the ChatResponse. Failures after the stream has started are sent
+// as an "error" event.
+func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request) {
+    flusher, ok := w.(http.Flusher)
+    if !ok {
+        writeError(w, http.StatusInternalServerError, "api_error", "streaming is not supported by this connection")
+        return
+    }
+    req, entry, ok := s.chatRequest(w, r)
+    if !ok {
+        return
+    }
+
+    started := false
+    start := func() {
+        if !started {
+            started = true
+            header := w.Header()
+            header.Set("Content-Type", "text/event-stream")
+            header.Set("Cache-Control", "no-cache")
+            header.Set("X-Accel-Buffering", "no")
+            w.WriteHeader(http.StatusOK)
+        }
+    }
+    handler := func(event anthropic.StreamEvent) {
+        start()
+        writeEvent(w, event.Type, event)
+        flusher.Flush()
+    }
+
+    resp, err := s.stream(r.Context(), entry, req.Message, handler)
+    if err != nil {
+        if !started {
+            writeChatError(w, err)
+            return
+        }
+        _, errType := errorStatus(err)
+        writeEvent(w, "error", ErrorResponse{Error: ErrorDetail{Type: errType, Message: err.Error()}})
+        flusher.Flush()
+        return
+    }
+    start()
+    writeEvent(w, "done", chatResponse(entry, resp))
+    flusher.Flush()
+}
+
+// stream sends message on entry's session, streaming the reply to handler
+// and running the configured tools
+func (s *Server) stream(ctx context.Context, entry *sessionEntry, message string, handler anthropic.StreamHandler) (*anthropic.AnthropicResponse, error) {
+    defer s.touch(entry)
+    if s.cfg.Tools != nil {
+        tools := anthropic.WithTools(s.cfg.Tools.Tools()...)
+        return entry.session.ChatStreamWithTools(ctx, message, s.cfg.Params, s.cfg.Tools.Handlers(), handler, tools)
+    }
+    return entry.session.ChatStream(ctx, message, s.cfg.Params, handler)
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+    var req struct {
+        System string `json:"system"`
+    }
+    if r.ContentLength != 0 {
+        if !s.decode(w, r, &req) {
+            return
+        }
+    }
+    entry := s.createSession(UserFromContext(r.Context()), req.System)
+    writeJSON(w, http.StatusCreated, s.info(entry, false))
+}
+
+func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+    user := UserFromContext(r.Context())
+    s.mu.Lock()
+    s.expire()
+    infos := []SessionInfo{}
+    for _, entry := range s.sessions {
+        if entry.owner == user {
+            infos = append(infos, s.info(entry, false))
+        }
+    }
+    s.mu.Unlock()
+
+    sort.Slice(infos, func(i, j int) bool { return infos[i].LastUsed.After(infos[j].LastUsed) })
+    writeJSON(w, http.StatusOK, struct {
+        Sessions []SessionInfo `json:"sessions"`
+    }{infos})
+}
+
+func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request, id string) {
+    entry, ok := s.lookup(UserFromContext(r.Context()), id)
+    if !ok {
+        sessionNotFound(w, id)
+        return
+    }
+    writeJSON(w, http.StatusOK, s.info(entry, true))
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request, id string) {
+    user := UserFromContext(r.Context())
+    s.mu.Lock()
+    entry, ok := s.sessions[id]
+    if ok && entry.owner == user {
+        delete(s.sessions, id)
+    }
+    s.mu.Unlock()
+
+    if !ok || entry.owner != user {
+        sessionNotFound(w, id)
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// chatRequest decodes a chat body and finds or creates its session,
+// writing the error response itself when it fails
+func (s *Server) chatRequest(w http.ResponseWriter, r *http.Request) (ChatRequest, *sessionEntry, bool) {
+    var req ChatRequest
+    if !s.decode(w, r, &req) {
+        return req, nil, false
+    }
+    entry, err := s.chatSession(UserFromContext(r.Context()), req)
+    if errors.Is(err, errSessionNotFound) {
+        sessionNotFound(w, req.SessionID)
+        return req, nil, false
+    }
+    if err != nil {
+        writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+        return req, nil, false
+    }
+    return req, entry, true
+}
+
+var errSessionNotFound = errors.New("no such session")
+
+// chatSession checks a chat request from user and finds or creates its session
+func (s *Server) chatSession(user string, req ChatRequest) (*sessionEntry, error) {
+    if strings.TrimSpace(req.Message) == "" {
+        return nil, errors.New("message is required")
+    }
+    if req.SessionID == "" {
+        return s.createSession(user, req.System), nil
+    }
+    entry, ok := s.lookup(user, req.SessionID)
+    if !ok {
+        return nil, fmt.Errorf("%w: %s", errSessionNotFound, req.SessionID)
+    }
+    return entry, nil
+}
+
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+    body := http.MaxBytesReader(w, r.Body, s.cfg.MaxBodyBytes)
+    if err := json.NewDecoder(body).Decode(v); err != nil {
+        writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid request body: %v", err))
+        return false
+    }
+    return true
+}
+
+func (s *Server) createSession(owner, system string) *sessionEntry {
+    if system == "" {
+        system = s.cfg.SystemPrompt
+    }
+    var opts []anthropic.SessionOption
+    if system != "" {
+        opts = append(opts, anthropic.WithSessionSystemPrompt(system))
+    }
+    now := s.now()
+    entry := &sessionEntry{
+        session:  s.client.NewSession(opts...),
+        owner:    owner,
+        created:  now,
+        lastUsed: now,
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.expire()
+    for len(s.sessions) >= s.cfg.MaxSessions {
+        s.evictOldest()
+    }
+    s.sessions[entry.session.ID()] = entry
+    return entry
+}
+
+// lookup returns the session with id if it belongs to user. Sessions of
+// other users are reported as missing, so their IDs are not revealed.
+func (s *Server) lookup(user, id string) (*sessionEntry, bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.expire()
+    entry, ok := s.sessions[id]
+    if !ok || entry.owner != user {
+        return nil, false
+    }
+    entry.lastUsed = s.now()
+    return entry, true
+}
+
+func (s *Server) touch(entry *sessionEntry) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    entry.lastUsed = s.now()
+}
+
+// expire drops sessions idle for longer than the TTL. Callers must hold s.mu.
+func (s *Server) expire() {
+    cutoff := s.now().Add(-s.cfg.SessionTTL)
+    for id, entry := range s.sessions {
+        if entry.lastUsed.Before(cutoff) {
+            delete(s.sessions, id)
+        }
+    }
+}
+
+// evictOldest drops the least recently used session. Callers must hold s.mu.
+func (s *Server) evictOldest() {
+    var oldestID string
+    var oldest time.Time
+    for id, entry := range s.sessions {
+        if oldestID == "" || entry.lastUsed.Before(oldest) {
+            oldestID, oldest = id, entry.lastUsed
+        }
+    }
+    delete(s.sessions, oldestID)
+}
+
+func (s *Server) info(entry *sessionEntry, history bool) SessionInfo {
+    info := SessionInfo{
+        SessionID: entry.session.ID(),
+        System:    entry.session.GetSystemPrompt(),
+        Created:   entry.created,
+        LastUsed:  entry.lastUsed,
+    }
+    if history {
+        info.Messages = entry.session.GetConversation()
+    }
+    return info
+}
+
+func chatResponse(entry *sessionEntry, resp *anthropic.AnthropicResponse) ChatResponse {
+    return ChatResponse{
+        SessionID:  entry.session.ID(),
+        ID:         resp.ID,
+        Model:      resp.Model,
+        Text:       resp.Text(),
+        Content:    resp.Content,
+        StopReason: resp.StopReason,
+        Usage:      resp.Usage,
+    }
+}
+
+// errorStatus maps a client error to an HTTP status and API error type
+func errorStatus(err error) (int, string) {
+    switch {
+    case errors.Is(err, anthropic.ErrInvalidRequest), errors.Is(err, anthropic.ErrPromptTooLong):
+        return http.StatusBadRequest, "invalid_request_error"
+    case errors.Is(err, anthropic.ErrGuardrailBlocked):
+        return http.StatusUnprocessableEntity, "guardrail_error"
+    case errors.Is(err, anthropic.ErrBudgetExceeded):
+        return http.StatusTooManyRequests, "budget_exceeded_error"
+    case errors.Is(err, anthropic.ErrClientClosed):
+        return http.StatusServiceUnavailable, "overloaded_error"
+    case errors.Is(err, context.DeadlineExceeded), errors.Is(err, anthropic.ErrStreamStalled):
+        return http.StatusGatewayTimeout, "timeout_error"
+    default:
+        return http.StatusBadGateway, "api_error"
+    }
+}
+
+func writeChatError(w http.ResponseWriter, err error) {
+    status, errType := errorStatus(err)
+    writeError(w, status, errType, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, errType, message string) {
+    writeJSON(w, status, ErrorResponse{Error: ErrorDetail{Type: errType, Message: message}})
+}
+
+func sessionNotFound(w http.ResponseWriter, id string) {
+    writeError(w, http.StatusNotFound, "not_found_error", "no such session: "+id)
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+    w.Header().Set("Allow", allowed)
+    writeError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+func writeEvent(w http.ResponseWriter, event string, v interface{}) {
+    data, err := json.Marshal(v)
+    if err != nil {
+        data, _ = json.Marshal(ErrorResponse{Error: ErrorDetail{Type: "api_error", Message: err.Error()}})
+        event = "error"
+    }
+    fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
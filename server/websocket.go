@@ -0,0 +1,441 @@
+package server
+
+import (
+    "bufio"
+    "context"
+    "crypto/sha1"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+// ClientFrame is a JSON message sent by the browser over GET /chat/ws. A
+// "chat" frame sends Message like a ChatRequest; a "cancel" frame stops the
+// reply in progress.
+type ClientFrame struct {
+    Type      string `json:"type"` // "chat" or "cancel"
+    SessionID string `json:"session_id,omitempty"`
+    Message   string `json:"message,omitempty"`
+    System    string `json:"system,omitempty"`
+}
+
+// Frame is a JSON message sent to the browser over GET /chat/ws. Each reply
+// opens with "start", streams "text", "thinking", "tool_call" and
+// "tool_result" frames, and ends with exactly one of "done", "cancelled"
+// or "error".
+type Frame struct {
+    Type      string          `json:"type"`
+    SessionID string          `json:"session_id,omitempty"`
+    Text      string          `json:"text,omitempty"`        // text and thinking
+    ToolUseID string          `json:"tool_use_id,omitempty"` // tool_call and tool_result
+    Name      string          `json:"name,omitempty"`        // tool_call
+    Input     json.RawMessage `json:"input,omitempty"`       // tool_call
+    Content   string          `json:"content,omitempty"`     // tool_result
+    IsError   bool            `json:"is_error,omitempty"`    // tool_result
+    Response  *ChatResponse   `json:"response,omitempty"`    // done
+    Error     *ErrorDetail    `json:"error,omitempty"`       // error
+}
+
+// handleWebSocket serves one browser connection. Replies run one at a time;
+// frames read while a reply streams are only checked for "cancel". The
+// upgrade request goes through RequireAuth like any other, but browsers
+// cannot set headers on it, so use an Authenticator that reads a cookie.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+    if !s.allowOrigin(r) {
+        writeError(w, http.StatusForbidden, "permission_error", "origin not allowed: "+r.Header.Get("Origin"))
+        return
+    }
+    conn, err := upgradeWebSocket(w, r, s.cfg.MaxBodyBytes)
+    if err != nil {
+        writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+        return
+    }
+    defer conn.Close()
+
+    user := UserFromContext(r.Context())
+    ctx, cancel := context.WithCancel(r.Context())
+    var mu sync.Mutex
+    var cancelReply context.CancelFunc
+    var replies sync.WaitGroup
+    defer func() {
+        cancel()
+        replies.Wait()
+    }()
+
+    for {
+        data, err := conn.ReadMessage()
+        if err != nil {
+            return
+        }
+        var msg ClientFrame
+        if err := json.Unmarshal(data, &msg); err != nil {
+            conn.WriteJSON(frameError("", "invalid_request_error", fmt.Errorf("invalid frame: %w", err)))
+            continue
+        }
+
+        switch msg.Type {
+        case "cancel":
+            mu.Lock()
+            if cancelReply != nil {
+                cancelReply()
+            }
+            mu.Unlock()
+        case "chat":
+            mu.Lock()
+            busy := cancelReply != nil
+            mu.Unlock()
+            if busy {
+                conn.WriteJSON(frameError(msg.SessionID, "invalid_request_error", errors.New("a reply is already in progress")))
+                continue
+            }
+            entry, err := s.chatSession(user, ChatRequest{SessionID: msg.SessionID, Message: msg.Message, System: msg.System})
+            if err != nil {
+                errType := "invalid_request_error"
+                if errors.Is(err, errSessionNotFound) {
+                    errType = "not_found_error"
+                }
+                conn.WriteJSON(frameError(msg.SessionID, errType, err))
+                continue
+            }
+
+            replyCtx, replyCancel := context.WithCancel(ctx)
+            mu.Lock()
+            cancelReply = replyCancel
+            mu.Unlock()
+            replies.Add(1)
+            go func() {
+                defer replies.Done()
+                last := s.streamFrames(replyCtx, conn, entry, msg.Message)
+                mu.Lock()
+                cancelReply = nil
+                mu.Unlock()
+                replyCancel()
+                conn.WriteJSON(last)
+            }()
+        default:
+            conn.WriteJSON(frameError(msg.SessionID, "invalid_request_error", fmt.Errorf("unknown frame type %q", msg.Type)))
+        }
+    }
+}
+
+// streamFrames runs one reply, translating its stream events into frames,
+// and returns the frame that ends it
+func (s *Server) streamFrames(ctx context.Context, conn *wsConn, entry *sessionEntry, message string) Frame {
+    id := entry.session.ID()
+    conn.WriteJSON(Frame{Type: "start", SessionID: id})
+
+    // Tool calls are sent once their input is complete
+    tools := make(map[int]*Frame)
+    handler := func(event anthropic.StreamEvent) {
+        switch event.Type {
+        case anthropic.StreamMessageStart:
+            tools = make(map[int]*Frame)
+        case anthropic.StreamContentBlockStart:
+            if block := event.ContentBlock; block != nil && block.Type == anthropic.ContentTypeToolUse {
+                tools[event.Index] = &Frame{Type: "tool_call", SessionID: id, ToolUseID: block.ID, Name: block.Name}
+            }
+        case anthropic.StreamContentBlockDelta:
+            switch event.Delta.Type {
+            case "text_delta":
+                conn.WriteJSON(Frame{Type: "text", SessionID: id, Text: event.Delta.Text})
+            case "thinking_delta":
+                conn.WriteJSON(Frame{Type: "thinking", SessionID: id, Text: event.Delta.Thinking})
+            case "input_json_delta":
+                if frame := tools[event.Index]; frame != nil {
+                    frame.Input = append(frame.Input, event.Delta.PartialJSON...)
+                }
+            }
+        case anthropic.StreamContentBlockStop:
+            if frame := tools[event.Index]; frame != nil {
+                if len(frame.Input) == 0 {
+                    frame.Input = json.RawMessage("{}")
+                }
+                conn.WriteJSON(frame)
+                delete(tools, event.Index)
+            }
+        case anthropic.StreamToolResult:
+            if block := event.ContentBlock; block != nil {
+                content := block.Content
+                for _, part := range block.ContentBlocks {
+                    content += part.Text
+                }
+                conn.WriteJSON(Frame{Type: "tool_result", SessionID: id, ToolUseID: block.ToolUseID, Content: content, IsError: block.IsError})
+            }
+        }
+    }
+
+    resp, err := s.stream(ctx, entry, message, handler)
+    switch {
+    case err == nil:
+        reply := chatResponse(entry, resp)
+        return Frame{Type: "done", SessionID: id, Response: &reply}
+    case errors.Is(ctx.Err(), context.Canceled):
+        return Frame{Type: "cancelled", SessionID: id}
+    default:
+        _, errType := errorStatus(err)
+        return frameError(id, errType, err)
+    }
+}
+
+func frameError(sessionID, errType string, err error) Frame {
+    return Frame{Type: "error", SessionID: sessionID, Error: &ErrorDetail{Type: errType, Message: err.Error()}}
+}
+
+// allowOrigin reports whether a WebSocket may be opened from the request's
+// origin. Requests without an Origin header do not come from a browser.
+func (s *Server) allowOrigin(r *http.Request) bool {
+    origin := r.Header.Get("Origin")
+    if origin == "" {
+        return true
+    }
+    if len(s.cfg.AllowedOrigins) == 0 {
+        u, err := url.Parse(origin)
+        return err == nil && strings.EqualFold(u.Host, r.Host)
+    }
+    for _, allowed := range s.cfg.AllowedOrigins {
+        if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+            return true
+        }
+    }
+    return false
+}
+
+// The server side of RFC 6455, covering what a chat endpoint needs: text
+// messages, fragmentation, ping and close. Extensions such as compression
+// are not negotiated.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+    opContinuation = 0x0
+    opText         = 0x1
+    opBinary       = 0x2
+    opClose        = 0x8
+    opPing         = 0x9
+    opPong         = 0xA
+)
+
+// Close codes
+const (
+    closeNormal        = 1000
+    closeProtocolError = 1002
+    closeTooBig        = 1009
+)
+
+// wsConn is a WebSocket connection. Reads must come from one goroutine;
+// writes may come from any.
+type wsConn struct {
+    conn       net.Conn
+    r          *bufio.Reader
+    maxMessage int64
+
+    mu     sync.Mutex // Serializes writes
+    w      *bufio.Writer
+    closed bool
+}
+
+// upgradeWebSocket completes the opening handshake and takes over the
+// connection. Until it succeeds the caller can still write an HTTP error.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, maxMessage int64) (*wsConn, error) {
+    if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+        return nil, errors.New("expected a WebSocket upgrade request")
+    }
+    if r.Header.Get("Sec-WebSocket-Version") != "13" {
+        w.Header().Set("Sec-WebSocket-Version", "13")
+        return nil, errors.New("unsupported WebSocket version")
+    }
+    key := r.Header.Get("Sec-WebSocket-Key")
+    if key == "" {
+        return nil, errors.New("missing Sec-WebSocket-Key")
+    }
+    hijacker, ok := w.(http.Hijacker)
+    if !ok {
+        return nil, errors.New("connection cannot be upgraded")
+    }
+    conn, rw, err := hijacker.Hijack()
+    if err != nil {
+        return nil, fmt.Errorf("error taking over connection: %w", err)
+    }
+
+    sum := sha1.Sum([]byte(key + websocketGUID))
+    accept := base64.StdEncoding.EncodeToString(sum[:])
+    fmt.Fprintf(rw.Writer, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", accept)
+    if err := rw.Writer.Flush(); err != nil {
+        conn.Close()
+        return nil, fmt.Errorf("error completing handshake: %w", err)
+    }
+    return &wsConn{conn: conn, r: rw.Reader, w: rw.Writer, maxMessage: maxMessage}, nil
+}
+
+func headerContains(header http.Header, name, token string) bool {
+    for _, value := range header.Values(name) {
+        for _, part := range strings.Split(value, ",") {
+            if strings.EqualFold(strings.TrimSpace(part), token) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// ReadMessage returns the next text or binary message, answering pings
+// along the way. It returns io.EOF once the browser closes the connection.
+func (c *wsConn) ReadMessage() ([]byte, error) {
+    var message []byte
+    fragmented := false
+    for {
+        fin, op, payload, err := c.readFrame()
+        if err != nil {
+            return nil, err
+        }
+        switch op {
+        case opPing:
+            c.writeFrame(opPong, payload)
+            continue
+        case opPong:
+            continue
+        case opClose:
+            c.closeWith(closeNormal, "")
+            return nil, io.EOF
+        case opText, opBinary:
+            if fragmented {
+                c.closeWith(closeProtocolError, "expected a continuation frame")
+                return nil, errors.New("websocket: new message inside a fragmented one")
+            }
+        case opContinuation:
+            if !fragmented {
+                c.closeWith(closeProtocolError, "unexpected continuation frame")
+                return nil, errors.New("websocket: continuation without a message")
+            }
+        default:
+            c.closeWith(closeProtocolError, "unknown opcode")
+            return nil, fmt.Errorf("websocket: unknown opcode %d", op)
+        }
+
+        if int64(len(message)+len(payload)) > c.maxMessage {
+            c.closeWith(closeTooBig, "message too big")
+            return nil, fmt.Errorf("websocket: message exceeds %d bytes", c.maxMessage)
+        }
+        message = append(message, payload...)
+        if fin {
+            return message, nil
+        }
+        fragmented = true
+    }
+}
+
+// readFrame reads one frame, unmasking its payload
+func (c *wsConn) readFrame() (fin bool, op byte, payload []byte, err error) {
+    var head [2]byte
+    if _, err := io.ReadFull(c.r, head[:]); err != nil {
+        return false, 0, nil, err
+    }
+    fin = head[0]&0x80 != 0
+    op = head[0] & 0x0F
+    if head[0]&0x70 != 0 {
+        c.closeWith(closeProtocolError, "reserved bits set")
+        return false, 0, nil, errors.New("websocket: reserved bits set")
+    }
+    if head[1]&0x80 == 0 {
+        c.closeWith(closeProtocolError, "frames must be masked")
+        return false, 0, nil, errors.New("websocket: unmasked client frame")
+    }
+
+    length := uint64(head[1] & 0x7F)
+    switch length {
+    case 126:
+        var ext [2]byte
+        if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+            return false, 0, nil, err
+        }
+        length = uint64(binary.BigEndian.Uint16(ext[:]))
+    case 127:
+        var ext [8]byte
+        if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+            return false, 0, nil, err
+        }
+        length = binary.BigEndian.Uint64(ext[:])
+    }
+    if op >= opClose && (length > 125 || !fin) {
+        c.closeWith(closeProtocolError, "invalid control frame")
+        return false, 0, nil, errors.New("websocket: invalid control frame")
+    }
+    if length > uint64(c.maxMessage) {
+        c.closeWith(closeTooBig, "message too big")
+        return false, 0, nil, fmt.Errorf("websocket: frame exceeds %d bytes", c.maxMessage)
+    }
+
+    var mask [4]byte
+    if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+        return false, 0, nil, err
+    }
+    payload = make([]byte, length)
+    if _, err := io.ReadFull(c.r, payload); err != nil {
+        return false, 0, nil, err
+    }
+    for i := range payload {
+        payload[i] ^= mask[i%4]
+    }
+    return fin, op, payload, nil
+}
+
+// WriteJSON sends v as a text message
+func (c *wsConn) WriteJSON(v interface{}) error {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return err
+    }
+    return c.writeFrame(opText, data)
+}
+
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.closed {
+        return net.ErrClosed
+    }
+
+    header := []byte{0x80 | op}
+    switch n := len(payload); {
+    case n <= 125:
+        header = append(header, byte(n))
+    case n <= 0xFFFF:
+        header = append(header, 126, byte(n>>8), byte(n))
+    default:
+        var ext [8]byte
+        binary.BigEndian.PutUint64(ext[:], uint64(n))
+        header = append(append(header, 127), ext[:]...)
+    }
+    if _, err := c.w.Write(header); err != nil {
+        return err
+    }
+    if _, err := c.w.Write(payload); err != nil {
+        return err
+    }
+    return c.w.Flush()
+}
+
+// closeWith sends a close frame; the connection is torn down by Close
+func (c *wsConn) closeWith(code int, reason string) {
+    payload := make([]byte, 2, 2+len(reason))
+    binary.BigEndian.PutUint16(payload, uint16(code))
+    c.writeFrame(opClose, append(payload, reason...))
+    c.mu.Lock()
+    c.closed = true
+    c.mu.Unlock()
+}
+
+// Close sends a normal close frame, if none was sent, and closes the connection
+func (c *wsConn) Close() error {
+    c.closeWith(closeNormal, "")
+    return c.conn.Close()
+}
@@ -0,0 +1,178 @@
+package toolcatalog
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "os"
+    "os/exec"
+    "strings"
+    "time"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    defaultBashTimeout     = 30 * time.Second
+    defaultBashOutputBytes = 32 << 10
+)
+
+// BashConfig restricts what the bash handler may run
+type BashConfig struct {
+    // Allowlist names the programs that may be invoked (e.g. "ls", "grep",
+    // "go"). Every command in a pipeline or chain must be on it. Required.
+    Allowlist []string
+
+    Dir            string        // Working directory; defaults to the current directory
+    Env            []string      // Full environment; nil inherits the parent's
+    Timeout        time.Duration // Per-command limit; defaults to 30s
+    MaxOutputBytes int           // Output beyond this is truncated; defaults to 32KB
+    Shell          string        // Defaults to "bash"
+
+    // AllowRedirection permits >, < and >> in commands. Off by default so
+    // commands cannot write files the allowlist does not account for.
+    AllowRedirection bool
+}
+
+type bashInput struct {
+    Command string `json:"command"`
+    Restart bool   `json:"restart"`
+}
+
+// NewBash returns a handler for Anthropic's bash tool (anthropic.BashTool).
+// Each command runs in a fresh shell; only allowlisted programs may be
+// invoked and command substitution is rejected.
+func NewBash(cfg BashConfig) (anthropic.ToolHandler, error) {
+    if len(cfg.Allowlist) == 0 {
+        return nil, fmt.Errorf("bash tool: allowlist is required")
+    }
+    if cfg.Timeout <= 0 {
+        cfg.Timeout = defaultBashTimeout
+    }
+    if cfg.MaxOutputBytes <= 0 {
+        cfg.MaxOutputBytes = defaultBashOutputBytes
+    }
+    if cfg.Shell == "" {
+        cfg.Shell = "bash"
+    }
+    allowed := make(map[string]bool, len(cfg.Allowlist))
+    for _, name := range cfg.Allowlist {
+        allowed[name] = true
+    }
+    return &bashTool{cfg: cfg, allowed: allowed}, nil
+}
+
+type bashTool struct {
+    cfg     BashConfig
+    allowed map[string]bool
+}
+
+func (b *bashTool) Definition() anthropic.Tool {
+    return anthropic.BashTool()
+}
+
+func (b *bashTool) Execute(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+    var in bashInput
+    if err := json.Unmarshal(input, &in); err != nil {
+        return errorResult("invalid input: %v", err), nil
+    }
+    if in.Restart {
+        // Commands do not share a shell, so there is no state to reset
+        return anthropic.ToolResult{Content: "tool has been restarted."}, nil
+    }
+    if strings.TrimSpace(in.Command) == "" {
+        return errorResult("command is required"), nil
+    }
+    if err := b.checkCommand(in.Command); err != nil {
+        return errorResult("%v", err), nil
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+    defer cancel()
+
+    // Output goes to a file rather than a pipe so a background child holding
+    // the descriptor open cannot block Wait after a timeout
+    out, err := ioutil.TempFile("", "bash-tool-*")
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error creating output file: %w", err)
+    }
+    defer os.Remove(out.Name())
+    defer out.Close()
+
+    cmd := exec.CommandContext(ctx, b.cfg.Shell, "-c", in.Command)
+    cmd.Dir = b.cfg.Dir
+    cmd.Env = b.cfg.Env
+    cmd.Stdout = out
+    cmd.Stderr = out
+    runErr := cmd.Run()
+
+    if _, err := out.Seek(0, io.SeekStart); err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error reading command output: %w", err)
+    }
+    output, err := ioutil.ReadAll(io.LimitReader(out, int64(b.cfg.MaxOutputBytes)+1))
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error reading command output: %w", err)
+    }
+    text := string(output)
+    if len(output) > b.cfg.MaxOutputBytes {
+        text = string(output[:b.cfg.MaxOutputBytes]) + "\n[output truncated]"
+    }
+
+    switch {
+    case ctx.Err() == context.DeadlineExceeded:
+        return errorResult("command timed out after %s\n%s", b.cfg.Timeout, text), nil
+    case runErr != nil:
+        if exitErr, ok := runErr.(*exec.ExitError); ok {
+            status := fmt.Sprintf("[exit code %d]", exitErr.ExitCode())
+            if text != "" {
+                status = strings.TrimRight(text, "\n") + "\n" + status
+            }
+            return anthropic.ToolResult{Content: status, IsError: true}, nil
+        }
+        return anthropic.ToolResult{}, fmt.Errorf("error running command: %w", runErr)
+    }
+    return anthropic.ToolResult{Content: text}, nil
+}
+
+// checkCommand rejects constructs that could run programs outside the
+// allowlist and verifies the program of every command in the line
+func (b *bashTool) checkCommand(command string) error {
+    for _, banned := range []string{"`", "$(", "<(", ">(", "${"} {
+        if strings.Contains(command, banned) {
+            return fmt.Errorf("%q is not allowed in commands", banned)
+        }
+    }
+    if !b.cfg.AllowRedirection && strings.ContainsAny(command, "<>") {
+        return fmt.Errorf("redirection is not allowed in commands")
+    }
+
+    for _, segment := range splitCommands(command) {
+        fields := strings.Fields(segment)
+        if len(fields) == 0 {
+            continue
+        }
+        // Assignments such as PATH=... could redirect which binary runs
+        if strings.Contains(fields[0], "=") {
+            return fmt.Errorf("environment assignments are not allowed in commands")
+        }
+        // Programs must be named as allowlisted, not by a path to a lookalike
+        program := strings.Trim(fields[0], `"'`)
+        if !b.allowed[program] {
+            return fmt.Errorf("command %q is not in the allowlist (%s)", program, strings.Join(b.cfg.Allowlist, ", "))
+        }
+    }
+    return nil
+}
+
+// splitCommands breaks a command line at ;, &, |, newlines and parentheses
+func splitCommands(command string) []string {
+    return strings.FieldsFunc(command, func(r rune) bool {
+        switch r {
+        case ';', '&', '|', '\n', '(', ')', '{', '}':
+            return true
+        }
+        return false
+    })
+}
@@ -0,0 +1,341 @@
+package toolcatalog
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+    "unicode"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const maxExpressionLength = 1000
+
+type calculatorInput struct {
+    Expression string `json:"expression" jsonschema:"description=Arithmetic expression such as (2 + 3) * sqrt(16) / 2^3"`
+}
+
+// calculatorFuncs maps function names to their implementation and arity
+var calculatorFuncs = map[string]struct {
+    arity int // -1 for variadic
+    fn    func(args []float64) float64
+}{
+    "sqrt":  {1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+    "abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+    "exp":   {1, func(a []float64) float64 { return math.Exp(a[0]) }},
+    "ln":    {1, func(a []float64) float64 { return math.Log(a[0]) }},
+    "log":   {1, func(a []float64) float64 { return math.Log10(a[0]) }},
+    "log2":  {1, func(a []float64) float64 { return math.Log2(a[0]) }},
+    "log10": {1, func(a []float64) float64 { return math.Log10(a[0]) }},
+    "sin":   {1, func(a []float64) float64 { return math.Sin(a[0]) }},
+    "cos":   {1, func(a []float64) float64 { return math.Cos(a[0]) }},
+    "tan":   {1, func(a []float64) float64 { return math.Tan(a[0]) }},
+    "asin":  {1, func(a []float64) float64 { return math.Asin(a[0]) }},
+    "acos":  {1, func(a []float64) float64 { return math.Acos(a[0]) }},
+    "atan":  {1, func(a []float64) float64 { return math.Atan(a[0]) }},
+    "floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+    "ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+    "round": {1, func(a []float64) float64 { return math.Round(a[0]) }},
+    "pow":   {2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+    "min": {-1, func(a []float64) float64 {
+        m := a[0]
+        for _, v := range a[1:] {
+            m = math.Min(m, v)
+        }
+        return m
+    }},
+    "max": {-1, func(a []float64) float64 {
+        m := a[0]
+        for _, v := range a[1:] {
+            m = math.Max(m, v)
+        }
+        return m
+    }},
+}
+
+var calculatorConstants = map[string]float64{
+    "pi": math.Pi,
+    "e":  math.E,
+}
+
+// NewCalculator returns a calculator tool that evaluates arithmetic
+// expressions exactly, so numeric answers do not depend on the model's
+// arithmetic. It supports + - * / % ^, parentheses, pi, e and common
+// functions (sqrt, abs, exp, ln, log, log2, sin, cos, tan, asin, acos, atan,
+// floor, ceil, round, pow, min, max). Trigonometry uses radians.
+func NewCalculator() (anthropic.ToolHandler, error) {
+    return newStructTool("calculator",
+        "Evaluates an arithmetic expression and returns the numeric result. Use it for any calculation "+
+            "instead of computing by hand. Supports + - * / % ^, parentheses, pi, e, and the functions "+
+            "sqrt, abs, exp, ln, log, log2, sin, cos, tan, asin, acos, atan, floor, ceil, round, pow, min, max (radians).",
+        func(ctx context.Context, in calculatorInput) (anthropic.ToolResult, error) {
+            value, err := Evaluate(in.Expression)
+            if err != nil {
+                return errorResult("%v", err), nil
+            }
+            return anthropic.ToolResult{Content: strconv.FormatFloat(value, 'g', -1, 64)}, nil
+        })
+}
+
+// Evaluate computes the value of an arithmetic expression using the same
+// grammar as the calculator tool
+func Evaluate(expression string) (float64, error) {
+    if len(expression) > maxExpressionLength {
+        return 0, fmt.Errorf("expression is longer than %d characters", maxExpressionLength)
+    }
+    p := &exprParser{input: expression}
+    p.next()
+    value, err := p.parseExpression()
+    if err != nil {
+        return 0, err
+    }
+    if p.tok.kind != tokEOF {
+        return 0, fmt.Errorf("unexpected %q at position %d", p.tok.text, p.tok.pos+1)
+    }
+    if math.IsNaN(value) || math.IsInf(value, 0) {
+        return 0, fmt.Errorf("result is not a finite number")
+    }
+    return value, nil
+}
+
+type tokenKind int
+
+const (
+    tokEOF tokenKind = iota
+    tokNumber
+    tokIdent
+    tokOp
+)
+
+type token struct {
+    kind  tokenKind
+    text  string
+    value float64
+    pos   int
+}
+
+// exprParser is a recursive-descent parser over the grammar:
+//
+//     expression = term { ("+" | "-") term }
+//     term       = unary { ("*" | "/" | "%") unary }
+//     unary      = ("-" | "+") unary | power
+//     power      = primary [ "^" unary ]
+//     primary    = number | constant | function "(" args ")" | "(" expression ")"
+type exprParser struct {
+    input string
+    pos   int
+    tok   token
+    depth int
+}
+
+func (p *exprParser) next() {
+    for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+        p.pos++
+    }
+    start := p.pos
+    if p.pos >= len(p.input) {
+        p.tok = token{kind: tokEOF, pos: start}
+        return
+    }
+
+    c := p.input[p.pos]
+    switch {
+    case c >= '0' && c <= '9' || c == '.':
+        for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+            p.pos++
+        }
+        // Exponent, e.g. 1.5e-3
+        if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+            end := p.pos + 1
+            if end < len(p.input) && (p.input[end] == '+' || p.input[end] == '-') {
+                end++
+            }
+            if end < len(p.input) && isDigit(p.input[end]) {
+                for end < len(p.input) && isDigit(p.input[end]) {
+                    end++
+                }
+                p.pos = end
+            }
+        }
+        text := p.input[start:p.pos]
+        value, err := strconv.ParseFloat(text, 64)
+        if err != nil {
+            p.tok = token{kind: tokOp, text: text, pos: start}
+            return
+        }
+        p.tok = token{kind: tokNumber, text: text, value: value, pos: start}
+    case unicode.IsLetter(rune(c)):
+        for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || isDigit(p.input[p.pos])) {
+            p.pos++
+        }
+        p.tok = token{kind: tokIdent, text: strings.ToLower(p.input[start:p.pos]), pos: start}
+    default:
+        p.pos++
+        text := string(c)
+        if c == '*' && p.pos < len(p.input) && p.input[p.pos] == '*' {
+            p.pos++
+            text = "^" // Accept ** as exponentiation
+        }
+        p.tok = token{kind: tokOp, text: text, pos: start}
+    }
+}
+
+func isDigit(c byte) bool {
+    return c >= '0' && c <= '9'
+}
+
+func (p *exprParser) parseExpression() (float64, error) {
+    p.depth++
+    defer func() { p.depth-- }()
+    if p.depth > 100 {
+        return 0, fmt.Errorf("expression is nested too deeply")
+    }
+
+    left, err := p.parseTerm()
+    if err != nil {
+        return 0, err
+    }
+    for p.tok.kind == tokOp && (p.tok.text == "+" || p.tok.text == "-") {
+        op := p.tok.text
+        p.next()
+        right, err := p.parseTerm()
+        if err != nil {
+            return 0, err
+        }
+        if op == "+" {
+            left += right
+        } else {
+            left -= right
+        }
+    }
+    return left, nil
+}
+
+func (p *exprParser) parseTerm() (float64, error) {
+    left, err := p.parseUnary()
+    if err != nil {
+        return 0, err
+    }
+    for p.tok.kind == tokOp && (p.tok.text == "*" || p.tok.text == "/" || p.tok.text == "%") {
+        op := p.tok.text
+        p.next()
+        right, err := p.parseUnary()
+        if err != nil {
+            return 0, err
+        }
+        switch op {
+        case "*":
+            left *= right
+        case "/":
+            if right == 0 {
+                return 0, fmt.Errorf("division by zero")
+            }
+            left /= right
+        case "%":
+            if right == 0 {
+                return 0, fmt.Errorf("modulo by zero")
+            }
+            left = math.Mod(left, right)
+        }
+    }
+    return left, nil
+}
+
+func (p *exprParser) parseUnary() (float64, error) {
+    if p.tok.kind == tokOp && (p.tok.text == "-" || p.tok.text == "+") {
+        op := p.tok.text
+        p.next()
+        p.depth++
+        defer func() { p.depth-- }()
+        if p.depth > 100 {
+            return 0, fmt.Errorf("expression is nested too deeply")
+        }
+        value, err := p.parseUnary()
+        if op == "-" {
+            value = -value
+        }
+        return value, err
+    }
+    return p.parsePower()
+}
+
+func (p *exprParser) parsePower() (float64, error) {
+    base, err := p.parsePrimary()
+    if err != nil {
+        return 0, err
+    }
+    if p.tok.kind == tokOp && p.tok.text == "^" {
+        p.next()
+        // Right-associative: 2^3^2 is 2^(3^2); -2^2 is -(2^2)
+        exponent, err := p.parseUnary()
+        if err != nil {
+            return 0, err
+        }
+        return math.Pow(base, exponent), nil
+    }
+    return base, nil
+}
+
+func (p *exprParser) parsePrimary() (float64, error) {
+    tok := p.tok
+    switch tok.kind {
+    case tokNumber:
+        p.next()
+        return tok.value, nil
+
+    case tokIdent:
+        p.next()
+        if value, ok := calculatorConstants[tok.text]; ok {
+            return value, nil
+        }
+        fn, ok := calculatorFuncs[tok.text]
+        if !ok {
+            return 0, fmt.Errorf("unknown name %q", tok.text)
+        }
+        if p.tok.kind != tokOp || p.tok.text != "(" {
+            return 0, fmt.Errorf("expected ( after %s", tok.text)
+        }
+        p.next()
+        var args []float64
+        for {
+            arg, err := p.parseExpression()
+            if err != nil {
+                return 0, err
+            }
+            args = append(args, arg)
+            if p.tok.kind == tokOp && p.tok.text == "," {
+                p.next()
+                continue
+            }
+            break
+        }
+        if p.tok.kind != tokOp || p.tok.text != ")" {
+            return 0, fmt.Errorf("expected ) to close %s(", tok.text)
+        }
+        p.next()
+        if fn.arity >= 0 && len(args) != fn.arity {
+            return 0, fmt.Errorf("%s takes %d argument(s), got %d", tok.text, fn.arity, len(args))
+        }
+        return fn.fn(args), nil
+
+    case tokOp:
+        if tok.text == "(" {
+            p.next()
+            value, err := p.parseExpression()
+            if err != nil {
+                return 0, err
+            }
+            if p.tok.kind != tokOp || p.tok.text != ")" {
+                return 0, fmt.Errorf("missing closing parenthesis")
+            }
+            p.next()
+            return value, nil
+        }
+        return 0, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+
+    default:
+        return 0, fmt.Errorf("unexpected end of expression")
+    }
+}
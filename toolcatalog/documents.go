@@ -0,0 +1,410 @@
+package toolcatalog
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+    "sync"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    defaultDocumentResults  = 5
+    defaultDocumentBytes    = 4 << 10
+    defaultChunkSize        = 2000
+    defaultMaxDocumentBytes = 1 << 20
+)
+
+// Document is a passage of a private document, as returned by a search
+type Document struct {
+    ID      string
+    Title   string
+    Source  string // Path or URL the passage came from, shown to Claude for citing
+    Content string
+    Score   float64 // Relevance to the query; higher is better
+}
+
+// DocumentStore searches a collection of documents. Search returns at most
+// limit passages, most relevant first. Implement it to put a vector database
+// or search service behind the search_documents tool.
+type DocumentStore interface {
+    Search(ctx context.Context, query string, limit int) ([]Document, error)
+}
+
+// SearchDocumentsConfig configures the search_documents tool
+type SearchDocumentsConfig struct {
+    Name string // Tool name; defaults to "search_documents"
+
+    // Collection describes what the documents cover (e.g. "the employee
+    // handbook and HR policies") and is included in the tool description so
+    // Claude knows when to search
+    Collection string
+
+    MaxResults int // Passages returned per search; defaults to 5
+    MaxBytes   int // Size of each passage shown to Claude; defaults to 4KB
+}
+
+type searchDocumentsInput struct {
+    Query string `json:"query" jsonschema:"description=What to look for as a question or keywords"`
+}
+
+// NewSearchDocuments returns a tool that searches store and returns the best
+// matching passages with their sources, so Claude can ground its answers in
+// private documents
+func NewSearchDocuments(store DocumentStore, cfg SearchDocumentsConfig) (anthropic.ToolHandler, error) {
+    if store == nil {
+        return nil, fmt.Errorf("search documents tool: store is required")
+    }
+    if cfg.Name == "" {
+        cfg.Name = "search_documents"
+    }
+    if cfg.MaxResults <= 0 {
+        cfg.MaxResults = defaultDocumentResults
+    }
+    if cfg.MaxBytes <= 0 {
+        cfg.MaxBytes = defaultDocumentBytes
+    }
+
+    description := "Searches private documents and returns the most relevant passages with their sources"
+    if cfg.Collection != "" {
+        description += ". The documents cover " + cfg.Collection
+    }
+    description += ". Use it before answering questions the documents may cover, and cite the sources you use"
+
+    s := &searchDocuments{store: store, cfg: cfg}
+    return newStructTool(cfg.Name, description, s.run)
+}
+
+type searchDocuments struct {
+    store DocumentStore
+    cfg   SearchDocumentsConfig
+}
+
+func (s *searchDocuments) run(ctx context.Context, in searchDocumentsInput) (anthropic.ToolResult, error) {
+    if strings.TrimSpace(in.Query) == "" {
+        return errorResult("query is required"), nil
+    }
+    docs, err := s.store.Search(ctx, in.Query, s.cfg.MaxResults)
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error searching documents: %w", err)
+    }
+    if len(docs) == 0 {
+        return anthropic.ToolResult{Content: "No documents matched the query."}, nil
+    }
+
+    var b strings.Builder
+    for i, doc := range docs {
+        if i > 0 {
+            b.WriteString("\n\n")
+        }
+        fmt.Fprintf(&b, "[%d] %s", i+1, documentLabel(doc))
+        content := doc.Content
+        if len(content) > s.cfg.MaxBytes {
+            content = strings.ToValidUTF8(content[:s.cfg.MaxBytes], "") + "\n[truncated]"
+        }
+        b.WriteString("\n")
+        b.WriteString(content)
+    }
+    return anthropic.ToolResult{Content: b.String()}, nil
+}
+
+func documentLabel(doc Document) string {
+    switch {
+    case doc.Title != "" && doc.Source != "" && doc.Title != doc.Source:
+        return fmt.Sprintf("%s (%s)", doc.Title, doc.Source)
+    case doc.Title != "":
+        return doc.Title
+    case doc.Source != "":
+        return doc.Source
+    }
+    return doc.ID
+}
+
+// VectorStore is a DocumentStore held in memory that ranks passages by the
+// cosine similarity of their embeddings. A VectorStore is safe for
+// concurrent use.
+type VectorStore struct {
+    embedder anthropic.Embedder
+    mu       sync.RWMutex
+    docs     []Document
+    vectors  [][]float32
+}
+
+// NewVectorStore creates an empty store embedding with embedder; nil uses
+// an anthropic.HashingEmbedder
+func NewVectorStore(embedder anthropic.Embedder) *VectorStore {
+    if embedder == nil {
+        embedder = anthropic.NewHashingEmbedder(0)
+    }
+    return &VectorStore{embedder: embedder}
+}
+
+// Add embeds and stores the documents. Documents without an ID are numbered
+// in the order added.
+func (s *VectorStore) Add(ctx context.Context, docs ...Document) error {
+    if len(docs) == 0 {
+        return nil
+    }
+    texts := make([]string, len(docs))
+    for i, doc := range docs {
+        texts[i] = doc.Content
+        if doc.Title != "" {
+            texts[i] = doc.Title + "\n" + doc.Content
+        }
+    }
+    vectors, err := s.embedder.Embed(ctx, texts, anthropic.EmbedDocument)
+    if err != nil {
+        return fmt.Errorf("error embedding documents: %w", err)
+    }
+    if len(vectors) != len(docs) {
+        return fmt.Errorf("embedder returned %d vectors for %d documents", len(vectors), len(docs))
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for i, doc := range docs {
+        if doc.ID == "" {
+            doc.ID = fmt.Sprintf("doc-%d", len(s.docs)+1)
+        }
+        doc.Score = 0
+        s.docs = append(s.docs, doc)
+        s.vectors = append(s.vectors, vectors[i])
+    }
+    return nil
+}
+
+// Len returns the number of stored documents
+func (s *VectorStore) Len() int {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    return len(s.docs)
+}
+
+// Search returns the passages most similar to query, leaving out those with
+// a similarity of 0 or less
+func (s *VectorStore) Search(ctx context.Context, query string, limit int) ([]Document, error) {
+    if limit <= 0 {
+        return nil, nil
+    }
+    vectors, err := s.embedder.Embed(ctx, []string{query}, anthropic.EmbedQuery)
+    if err != nil {
+        return nil, fmt.Errorf("error embedding query: %w", err)
+    }
+    if len(vectors) != 1 {
+        return nil, fmt.Errorf("embedder returned %d vectors for 1 query", len(vectors))
+    }
+
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    var matches []Document
+    for i, doc := range s.docs {
+        if score := anthropic.CosineSimilarity(vectors[0], s.vectors[i]); score > 0 {
+            doc.Score = score
+            matches = append(matches, doc)
+        }
+    }
+    sort.SliceStable(matches, func(i, j int) bool {
+        return matches[i].Score > matches[j].Score
+    })
+    if len(matches) > limit {
+        matches = matches[:limit]
+    }
+    return matches, nil
+}
+
+// DirectoryStoreConfig configures NewDirectoryStore
+type DirectoryStoreConfig struct {
+    Root       string
+    Extensions []string           // File extensions indexed; defaults to .md, .txt and .rst
+    ChunkSize  int                // Target passage size in bytes; defaults to 2000
+    MaxBytes   int                // Larger files are skipped; defaults to 1MB
+    Embedder   anthropic.Embedder // nil uses an anthropic.HashingEmbedder
+}
+
+// NewDirectoryStore indexes the text files under a directory into a
+// VectorStore, split into passages at paragraph breaks. Binary files, hidden
+// files and symlinks leading outside the root are skipped. The index is a
+// snapshot; build a new store to pick up changes.
+func NewDirectoryStore(ctx context.Context, cfg DirectoryStoreConfig) (*VectorStore, error) {
+    box, err := newSandbox(cfg.Root)
+    if err != nil {
+        return nil, fmt.Errorf("directory store: %w", err)
+    }
+    if len(cfg.Extensions) == 0 {
+        cfg.Extensions = []string{".md", ".txt", ".rst"}
+    }
+    if cfg.ChunkSize <= 0 {
+        cfg.ChunkSize = defaultChunkSize
+    }
+    if cfg.MaxBytes <= 0 {
+        cfg.MaxBytes = defaultMaxDocumentBytes
+    }
+    extensions := make(map[string]bool)
+    for _, ext := range cfg.Extensions {
+        extensions[strings.ToLower(ext)] = true
+    }
+
+    var docs []Document
+    err = filepath.WalkDir(box.root, func(path string, entry fs.DirEntry, err error) error {
+        if err != nil {
+            return err
+        }
+        if path != box.root && strings.HasPrefix(entry.Name(), ".") {
+            if entry.IsDir() {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        if entry.IsDir() || !extensions[strings.ToLower(filepath.Ext(path))] {
+            return nil
+        }
+        resolved, err := box.resolve(box.display(path))
+        if err != nil {
+            return nil
+        }
+        info, err := os.Stat(resolved)
+        if err != nil || !info.Mode().IsRegular() || info.Size() > int64(cfg.MaxBytes) {
+            return nil
+        }
+        data, err := os.ReadFile(resolved)
+        if err != nil {
+            return fmt.Errorf("error reading %s: %w", box.display(path), err)
+        }
+        if isBinary(data) {
+            return nil
+        }
+        source := box.display(path)
+        for i, chunk := range chunkText(string(data), cfg.ChunkSize) {
+            docs = append(docs, Document{
+                ID:      fmt.Sprintf("%s#%d", source, i+1),
+                Title:   source,
+                Source:  source,
+                Content: chunk,
+            })
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("directory store: %w", err)
+    }
+
+    store := NewVectorStore(cfg.Embedder)
+    if err := store.Add(ctx, docs...); err != nil {
+        return nil, fmt.Errorf("directory store: %w", err)
+    }
+    return store, nil
+}
+
+// chunkText splits text into passages of about size bytes, breaking at
+// blank lines where it can and inside long paragraphs where it must
+func chunkText(text string, size int) []string {
+    var chunks []string
+    var current strings.Builder
+    flush := func() {
+        if chunk := strings.TrimSpace(current.String()); chunk != "" {
+            chunks = append(chunks, chunk)
+        }
+        current.Reset()
+    }
+    for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+        if current.Len() > 0 && current.Len()+len(paragraph) > size {
+            flush()
+        }
+        for len(paragraph) > size {
+            cut := strings.LastIndexAny(paragraph[:size], " \n")
+            if cut <= 0 {
+                cut = size
+            }
+            current.WriteString(paragraph[:cut])
+            flush()
+            paragraph = strings.TrimLeft(paragraph[cut:], " \n")
+        }
+        if current.Len() > 0 {
+            current.WriteString("\n\n")
+        }
+        current.WriteString(paragraph)
+    }
+    flush()
+    return chunks
+}
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLiteFTSStore is a DocumentStore over an SQLite FTS5 table, ranked by
+// bm25. The table needs title, source and content columns:
+//
+//     CREATE VIRTUAL TABLE documents USING fts5(title, source, content);
+//
+// The database driver is up to the caller; it must be built with FTS5.
+type SQLiteFTSStore struct {
+    db    *sql.DB
+    table string
+}
+
+// NewSQLiteFTSStore searches the FTS5 table in db; an empty table name
+// defaults to "documents"
+func NewSQLiteFTSStore(db *sql.DB, table string) (*SQLiteFTSStore, error) {
+    if db == nil {
+        return nil, fmt.Errorf("sqlite fts store: db is required")
+    }
+    if table == "" {
+        table = "documents"
+    }
+    if !sqlIdentifier.MatchString(table) {
+        return nil, fmt.Errorf("sqlite fts store: invalid table name %q", table)
+    }
+    return &SQLiteFTSStore{db: db, table: table}, nil
+}
+
+// Search matches passages containing any word of query, best bm25 first.
+// Words are quoted so FTS5 query syntax in the query is taken literally.
+func (s *SQLiteFTSStore) Search(ctx context.Context, query string, limit int) ([]Document, error) {
+    match := ftsQuery(query)
+    if match == "" || limit <= 0 {
+        return nil, nil
+    }
+    rows, err := s.db.QueryContext(ctx, fmt.Sprintf(
+        "SELECT rowid, title, source, content, bm25(%[1]s) FROM %[1]s WHERE %[1]s MATCH ? ORDER BY bm25(%[1]s) LIMIT ?",
+        s.table), match, limit)
+    if err != nil {
+        return nil, fmt.Errorf("error searching %s: %w", s.table, err)
+    }
+    defer rows.Close()
+
+    var docs []Document
+    for rows.Next() {
+        var doc Document
+        var title, source sql.NullString
+        var rank float64
+        if err := rows.Scan(&doc.ID, &title, &source, &doc.Content, &rank); err != nil {
+            return nil, fmt.Errorf("error reading search results: %w", err)
+        }
+        doc.Title, doc.Source = title.String, source.String
+        // bm25 is lower for better matches
+        doc.Score = -rank
+        docs = append(docs, doc)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error reading search results: %w", err)
+    }
+    return docs, nil
+}
+
+// ftsQuery turns free text into an FTS5 query matching any of its words
+func ftsQuery(query string) string {
+    var terms []string
+    for _, word := range strings.FieldsFunc(query, func(r rune) bool {
+        return !(r == '_' || r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r > 127)
+    }) {
+        terms = append(terms, `"`+word+`"`)
+    }
+    return strings.Join(terms, " OR ")
+}
@@ -0,0 +1,230 @@
+package toolcatalog
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+// Weather is the result of a weather lookup
+type Weather struct {
+    Location     string  `json:"location"`
+    TemperatureC float64 `json:"temperature_c"`
+    TemperatureF float64 `json:"temperature_f"`
+    Condition    string  `json:"condition"`
+    Humidity     int     `json:"humidity"`
+}
+
+// StockQuote is the result of a stock price lookup
+type StockQuote struct {
+    Symbol string `json:"symbol"`
+    Price  string `json:"price"`
+}
+
+// WeatherLookup fetches current weather for a location
+type WeatherLookup func(ctx context.Context, location string) (Weather, error)
+
+// StockLookup fetches the current price for a stock symbol
+type StockLookup func(ctx context.Context, symbol string) (StockQuote, error)
+
+// SearchFunc runs a search and returns results as text for the model
+type SearchFunc func(ctx context.Context, query string) (string, error)
+
+type weatherInput struct {
+    Location string `json:"location"`
+    Unit     string `json:"unit"`
+}
+
+type stockInput struct {
+    Symbol string `json:"symbol"`
+}
+
+type searchInput struct {
+    Query string `json:"query"`
+}
+
+// NewWeather returns the get_weather example tool. A nil lookup uses a
+// reference implementation that reports fixed sample conditions.
+func NewWeather(lookup WeatherLookup) (anthropic.ToolHandler, error) {
+    if lookup == nil {
+        lookup = sampleWeather
+    }
+    def := anthropic.Tool{
+        Name: "get_weather",
+        Description: "Get the current weather in a given location. Returns temperature, " +
+            "conditions (sunny, cloudy, etc), and humidity. Always provide both Celsius " +
+            "and Fahrenheit in your natural language response.",
+        InputSchema: anthropic.InputSchema{
+            Type: "object",
+            Properties: map[string]anthropic.Property{
+                "location": {
+                    Type:        "string",
+                    Description: "The location name (city, country, or region), e.g. 'San Francisco, CA' or 'Cambodia'",
+                },
+                "unit": {
+                    Type:        "string",
+                    Description: "Temperature unit (celsius or fahrenheit)",
+                    Enum:        []string{"celsius", "fahrenheit"},
+                },
+            },
+            Required: []string{"location"},
+        },
+    }
+    return &toolFunc{def: def, fn: func(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+        var in weatherInput
+        if err := json.Unmarshal(input, &in); err != nil || in.Location == "" {
+            return errorResult("location is required"), nil
+        }
+        weather, err := lookup(ctx, in.Location)
+        if err != nil {
+            return errorResult("weather lookup failed: %v", err), nil
+        }
+        return jsonResult(weather)
+    }}, nil
+}
+
+// NewStockPrice returns the get_stock_price example tool. A nil lookup uses a
+// reference implementation that reports a fixed sample price.
+func NewStockPrice(lookup StockLookup) (anthropic.ToolHandler, error) {
+    if lookup == nil {
+        lookup = sampleStockQuote
+    }
+    def := anthropic.Tool{
+        Name:        "get_stock_price",
+        Description: "Get the current stock price for a given symbol",
+        InputSchema: anthropic.InputSchema{
+            Type: "object",
+            Properties: map[string]anthropic.Property{
+                "symbol": {
+                    Type:        "string",
+                    Description: "The stock symbol, e.g. AAPL",
+                },
+            },
+            Required: []string{"symbol"},
+        },
+    }
+    return &toolFunc{def: def, fn: func(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+        var in stockInput
+        if err := json.Unmarshal(input, &in); err != nil || in.Symbol == "" {
+            return errorResult("symbol is required"), nil
+        }
+        quote, err := lookup(ctx, in.Symbol)
+        if err != nil {
+            return errorResult("stock lookup failed: %v", err), nil
+        }
+        return jsonResult(quote)
+    }}, nil
+}
+
+// NewSearch returns the SearchInternet example tool backed by search
+func NewSearch(search SearchFunc) (anthropic.ToolHandler, error) {
+    return newSearchTool("SearchInternet",
+        "Search the internet for information when user requests it or when information is needed",
+        "The search query or question", search)
+}
+
+// NewDeepSearch returns the DeepSearch example tool backed by search
+func NewDeepSearch(search SearchFunc) (anthropic.ToolHandler, error) {
+    return newSearchTool("DeepSearch",
+        "Perform a comprehensive search when deep analysis is requested",
+        "The search query or question for detailed analysis", search)
+}
+
+func newSearchTool(name, description, queryDescription string, search SearchFunc) (anthropic.ToolHandler, error) {
+    if search == nil {
+        return nil, fmt.Errorf("%s tool: search function is required", name)
+    }
+    def := anthropic.Tool{
+        Name:        name,
+        Description: description,
+        InputSchema: anthropic.InputSchema{
+            Type: "object",
+            Properties: map[string]anthropic.Property{
+                "query": {Type: "string", Description: queryDescription},
+            },
+            Required: []string{"query"},
+        },
+    }
+    return &toolFunc{def: def, fn: func(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+        var in searchInput
+        if err := json.Unmarshal(input, &in); err != nil || in.Query == "" {
+            return errorResult("query is required"), nil
+        }
+        results, err := search(ctx, in.Query)
+        if err != nil {
+            return errorResult("search failed: %v", err), nil
+        }
+        return anthropic.ToolResult{Content: results}, nil
+    }}, nil
+}
+
+// ExampleToolsConfig selects the members of the example tool bundle.
+// get_weather and get_stock_price are always included, using Weather and
+// Stock or the reference lookups; the search tools need their functions.
+type ExampleToolsConfig struct {
+    Weather    WeatherLookup
+    Stock      StockLookup
+    Search     SearchFunc // Enables SearchInternet
+    DeepSearch SearchFunc // Enables DeepSearch
+}
+
+// ExampleTools returns the weather, stock and search tools used by the
+// examples, which earlier versions of the client sent by default:
+//
+//     tools, err := toolcatalog.ExampleTools(toolcatalog.ExampleToolsConfig{Search: mySearch})
+//     for _, t := range tools {
+//         registry.Register(t)
+//     }
+func ExampleTools(cfg ExampleToolsConfig) ([]anthropic.ToolHandler, error) {
+    weather, err := NewWeather(cfg.Weather)
+    if err != nil {
+        return nil, err
+    }
+    stock, err := NewStockPrice(cfg.Stock)
+    if err != nil {
+        return nil, err
+    }
+    tools := []anthropic.ToolHandler{weather, stock}
+
+    if cfg.Search != nil {
+        search, err := NewSearch(cfg.Search)
+        if err != nil {
+            return nil, err
+        }
+        tools = append(tools, search)
+    }
+    if cfg.DeepSearch != nil {
+        deep, err := NewDeepSearch(cfg.DeepSearch)
+        if err != nil {
+            return nil, err
+        }
+        tools = append(tools, deep)
+    }
+    return tools, nil
+}
+
+// sampleWeather is the reference weather lookup
+func sampleWeather(ctx context.Context, location string) (Weather, error) {
+    return Weather{
+        Location:     location,
+        TemperatureC: 22,
+        TemperatureF: 22*9/5 + 32,
+        Condition:    "sunny",
+        Humidity:     65,
+    }, nil
+}
+
+// sampleStockQuote is the reference stock lookup
+func sampleStockQuote(ctx context.Context, symbol string) (StockQuote, error) {
+    return StockQuote{Symbol: symbol, Price: "150.00"}, nil
+}
+
+func jsonResult(v interface{}) (anthropic.ToolResult, error) {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error encoding result: %w", err)
+    }
+    return anthropic.ToolResult{Content: string(data)}, nil
+}
@@ -0,0 +1,212 @@
+package toolcatalog
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    defaultMaxReadBytes  = 256 << 10
+    defaultMaxWriteBytes = 1 << 20
+    defaultMaxListItems  = 1000
+)
+
+// FileToolsConfig configures the get_file, put_file and list_dir tools
+type FileToolsConfig struct {
+    Root          string // Directory the tools are confined to. Required.
+    MaxReadBytes  int    // get_file truncates beyond this; defaults to 256KB
+    MaxWriteBytes int    // put_file rejects content beyond this; defaults to 1MB
+    MaxListItems  int    // list_dir stops after this many entries; defaults to 1000
+    ReadOnly      bool   // Omit put_file
+}
+
+type getFileInput struct {
+    Path string `json:"path" jsonschema:"description=File path relative to the workspace root"`
+}
+
+type putFileInput struct {
+    Path    string `json:"path" jsonschema:"description=File path relative to the workspace root"`
+    Content string `json:"content" jsonschema:"description=Text to write"`
+    Append  bool   `json:"append,omitempty" jsonschema:"description=Append to the file instead of replacing it"`
+}
+
+type listDirInput struct {
+    Path      string `json:"path,omitempty" jsonschema:"description=Directory relative to the workspace root; defaults to the root"`
+    Recursive bool   `json:"recursive,omitempty" jsonschema:"description=Include the contents of subdirectories"`
+}
+
+// NewFileTools returns get_file, list_dir and (unless ReadOnly) put_file
+// handlers confined to cfg.Root. Binary files are refused rather than sent to
+// the model.
+func NewFileTools(cfg FileToolsConfig) ([]anthropic.ToolHandler, error) {
+    box, err := newSandbox(cfg.Root)
+    if err != nil {
+        return nil, fmt.Errorf("file tools: %w", err)
+    }
+    if cfg.MaxReadBytes <= 0 {
+        cfg.MaxReadBytes = defaultMaxReadBytes
+    }
+    if cfg.MaxWriteBytes <= 0 {
+        cfg.MaxWriteBytes = defaultMaxWriteBytes
+    }
+    if cfg.MaxListItems <= 0 {
+        cfg.MaxListItems = defaultMaxListItems
+    }
+    ft := &fileTools{box: box, cfg: cfg}
+
+    getFile, err := newStructTool("get_file", "Reads a text file from the workspace", ft.getFile)
+    if err != nil {
+        return nil, err
+    }
+    listDir, err := newStructTool("list_dir", "Lists files and directories in the workspace", ft.listDir)
+    if err != nil {
+        return nil, err
+    }
+    tools := []anthropic.ToolHandler{getFile, listDir}
+
+    if !cfg.ReadOnly {
+        putFile, err := newStructTool("put_file", "Writes a text file in the workspace, creating directories as needed", ft.putFile)
+        if err != nil {
+            return nil, err
+        }
+        tools = append(tools, putFile)
+    }
+    return tools, nil
+}
+
+type fileTools struct {
+    box sandbox
+    cfg FileToolsConfig
+}
+
+func (ft *fileTools) getFile(ctx context.Context, in getFileInput) (anthropic.ToolResult, error) {
+    path, err := ft.box.resolve(in.Path)
+    if err != nil {
+        return errorResult("%v", err), nil
+    }
+    info, err := os.Stat(path)
+    if err != nil {
+        return errorResult("%s does not exist", ft.box.display(path)), nil
+    }
+    if info.IsDir() {
+        return errorResult("%s is a directory; use list_dir", ft.box.display(path)), nil
+    }
+
+    f, err := os.Open(path)
+    if err != nil {
+        return errorResult("cannot read %s: %v", ft.box.display(path), err), nil
+    }
+    defer f.Close()
+    data, err := ioutil.ReadAll(io.LimitReader(f, int64(ft.cfg.MaxReadBytes)))
+    if err != nil {
+        return errorResult("cannot read %s: %v", ft.box.display(path), err), nil
+    }
+    if isBinary(data) {
+        return errorResult("%s is a binary file (%d bytes)", ft.box.display(path), info.Size()), nil
+    }
+
+    content := string(data)
+    if info.Size() > int64(ft.cfg.MaxReadBytes) {
+        // Drop a multi-byte rune split by the limit
+        for i := 0; i < utf8.UTFMax-1 && !utf8.ValidString(content); i++ {
+            content = content[:len(content)-1]
+        }
+        content += fmt.Sprintf("\n[truncated: showing %d of %d bytes]", ft.cfg.MaxReadBytes, info.Size())
+    }
+    return anthropic.ToolResult{Content: content}, nil
+}
+
+func (ft *fileTools) putFile(ctx context.Context, in putFileInput) (anthropic.ToolResult, error) {
+    path, err := ft.box.resolve(in.Path)
+    if err != nil {
+        return errorResult("%v", err), nil
+    }
+    if len(in.Content) > ft.cfg.MaxWriteBytes {
+        return errorResult("content is %d bytes; the limit is %d", len(in.Content), ft.cfg.MaxWriteBytes), nil
+    }
+    if info, err := os.Stat(path); err == nil && info.IsDir() {
+        return errorResult("%s is a directory", ft.box.display(path)), nil
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return errorResult("cannot create directory for %s: %v", ft.box.display(path), err), nil
+    }
+
+    flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+    if in.Append {
+        flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+    }
+    f, err := os.OpenFile(path, flags, 0644)
+    if err != nil {
+        return errorResult("cannot write %s: %v", ft.box.display(path), err), nil
+    }
+    if _, err := f.WriteString(in.Content); err != nil {
+        f.Close()
+        return errorResult("cannot write %s: %v", ft.box.display(path), err), nil
+    }
+    if err := f.Close(); err != nil {
+        return errorResult("cannot write %s: %v", ft.box.display(path), err), nil
+    }
+    return anthropic.ToolResult{Content: fmt.Sprintf("Wrote %d bytes to %s", len(in.Content), ft.box.display(path))}, nil
+}
+
+func (ft *fileTools) listDir(ctx context.Context, in listDirInput) (anthropic.ToolResult, error) {
+    if in.Path == "" {
+        in.Path = "/"
+    }
+    path, err := ft.box.resolve(in.Path)
+    if err != nil {
+        return errorResult("%v", err), nil
+    }
+    info, err := os.Stat(path)
+    if err != nil {
+        return errorResult("%s does not exist", ft.box.display(path)), nil
+    }
+    if !info.IsDir() {
+        return errorResult("%s is not a directory", ft.box.display(path)), nil
+    }
+
+    var lines []string
+    truncated := false
+    err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+        if err != nil || p == path {
+            return nil
+        }
+        if len(lines) >= ft.cfg.MaxListItems {
+            truncated = true
+            return io.EOF
+        }
+        rel, _ := filepath.Rel(path, p)
+        rel = filepath.ToSlash(rel)
+        if info.IsDir() {
+            lines = append(lines, rel+"/")
+            if !in.Recursive {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        lines = append(lines, fmt.Sprintf("%s (%d bytes)", rel, info.Size()))
+        return nil
+    })
+    if err != nil && err != io.EOF {
+        return errorResult("cannot list %s: %v", ft.box.display(path), err), nil
+    }
+    sort.Strings(lines)
+
+    if len(lines) == 0 {
+        return anthropic.ToolResult{Content: fmt.Sprintf("%s is empty", ft.box.display(path))}, nil
+    }
+    content := strings.Join(lines, "\n")
+    if truncated {
+        content += fmt.Sprintf("\n[truncated after %d entries]", ft.cfg.MaxListItems)
+    }
+    return anthropic.ToolResult{Content: content}, nil
+}
@@ -0,0 +1,226 @@
+package toolcatalog
+
+import (
+    "context"
+    "fmt"
+    "html"
+    "io"
+    "io/ioutil"
+    "net"
+    "net/http"
+    "net/url"
+    "regexp"
+    "strings"
+    "syscall"
+    "time"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    defaultFetchTimeout   = 15 * time.Second
+    defaultFetchBodyBytes = 512 << 10
+    maxFetchRedirects     = 5
+)
+
+// HTTPFetchConfig configures the http_fetch tool
+type HTTPFetchConfig struct {
+    // AllowedHosts restricts requests to these hosts. "*.example.com" matches
+    // example.com and its subdomains. Empty allows any public host.
+    AllowedHosts []string
+
+    // AllowPrivateNetworks permits loopback, private and link-local addresses,
+    // which are blocked by default to keep the model away from internal services
+    AllowPrivateNetworks bool
+
+    AllowPost    bool              // Permit POST in addition to GET
+    MaxBodyBytes int               // Response bytes returned; defaults to 512KB
+    Timeout      time.Duration     // Defaults to 15s
+    Headers      map[string]string // Added to every request, e.g. User-Agent
+    HTMLToText   bool              // Convert HTML responses to plain text unless the call opts out
+}
+
+type httpFetchInput struct {
+    URL         string `json:"url" jsonschema:"description=Absolute http or https URL"`
+    Method      string `json:"method,omitempty" jsonschema:"enum=GET,enum=POST,description=HTTP method; defaults to GET"`
+    Body        string `json:"body,omitempty" jsonschema:"description=Request body for POST"`
+    ContentType string `json:"content_type,omitempty" jsonschema:"description=Content-Type of the POST body; defaults to application/json"`
+    Raw         bool   `json:"raw,omitempty" jsonschema:"description=Return HTML as-is instead of converting it to text"`
+}
+
+// NewHTTPFetch returns an http_fetch tool that retrieves web pages and APIs
+// for the model, subject to the host allowlist and size limits in cfg
+func NewHTTPFetch(cfg HTTPFetchConfig) (anthropic.ToolHandler, error) {
+    if cfg.MaxBodyBytes <= 0 {
+        cfg.MaxBodyBytes = defaultFetchBodyBytes
+    }
+    if cfg.Timeout <= 0 {
+        cfg.Timeout = defaultFetchTimeout
+    }
+    f := &httpFetch{cfg: cfg}
+
+    dialer := &net.Dialer{Timeout: cfg.Timeout}
+    if !cfg.AllowPrivateNetworks {
+        // Check the address actually dialed so DNS tricks cannot reach internal hosts
+        dialer.Control = func(network, address string, c syscall.RawConn) error {
+            host, _, err := net.SplitHostPort(address)
+            if err != nil {
+                return err
+            }
+            if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+                return fmt.Errorf("address %s is on a private network", host)
+            }
+            return nil
+        }
+    }
+    f.client = &http.Client{
+        Timeout:   cfg.Timeout,
+        Transport: &http.Transport{DialContext: dialer.DialContext},
+        CheckRedirect: func(req *http.Request, via []*http.Request) error {
+            if len(via) >= maxFetchRedirects {
+                return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+            }
+            return f.checkURL(req.URL)
+        },
+    }
+
+    description := "Fetches a URL over HTTP(S) and returns the response body"
+    if len(cfg.AllowedHosts) > 0 {
+        description += ". Allowed hosts: " + strings.Join(cfg.AllowedHosts, ", ")
+    }
+    return newStructTool("http_fetch", description, f.fetch)
+}
+
+type httpFetch struct {
+    cfg    HTTPFetchConfig
+    client *http.Client
+}
+
+func (f *httpFetch) fetch(ctx context.Context, in httpFetchInput) (anthropic.ToolResult, error) {
+    u, err := url.Parse(in.URL)
+    if err != nil {
+        return errorResult("invalid URL: %v", err), nil
+    }
+    if err := f.checkURL(u); err != nil {
+        return errorResult("%v", err), nil
+    }
+
+    method := strings.ToUpper(in.Method)
+    if method == "" {
+        method = http.MethodGet
+    }
+    var body io.Reader
+    switch method {
+    case http.MethodGet:
+    case http.MethodPost:
+        if !f.cfg.AllowPost {
+            return errorResult("POST requests are not allowed"), nil
+        }
+        body = strings.NewReader(in.Body)
+    default:
+        return errorResult("unsupported method %s", in.Method), nil
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+    if err != nil {
+        return errorResult("invalid request: %v", err), nil
+    }
+    for k, v := range f.cfg.Headers {
+        req.Header.Set(k, v)
+    }
+    if method == http.MethodPost {
+        contentType := in.ContentType
+        if contentType == "" {
+            contentType = "application/json"
+        }
+        req.Header.Set("Content-Type", contentType)
+    }
+
+    resp, err := f.client.Do(req)
+    if err != nil {
+        return errorResult("request failed: %v", err), nil
+    }
+    defer resp.Body.Close()
+
+    data, err := ioutil.ReadAll(io.LimitReader(resp.Body, int64(f.cfg.MaxBodyBytes)+1))
+    if err != nil {
+        return errorResult("error reading response: %v", err), nil
+    }
+    truncated := len(data) > f.cfg.MaxBodyBytes
+    if truncated {
+        data = data[:f.cfg.MaxBodyBytes]
+    }
+
+    contentType := resp.Header.Get("Content-Type")
+    text := string(data)
+    if f.cfg.HTMLToText && !in.Raw && strings.Contains(contentType, "html") {
+        text = htmlToText(text)
+    } else if isBinary(data) {
+        text = fmt.Sprintf("[binary content, %s]", contentType)
+    }
+    if truncated {
+        text += fmt.Sprintf("\n[truncated at %d bytes]", f.cfg.MaxBodyBytes)
+    }
+
+    content := fmt.Sprintf("HTTP %d %s\nContent-Type: %s\n\n%s", resp.StatusCode, http.StatusText(resp.StatusCode), contentType, text)
+    return anthropic.ToolResult{Content: content, IsError: resp.StatusCode >= 400}, nil
+}
+
+// checkURL enforces the scheme and host allowlist
+func (f *httpFetch) checkURL(u *url.URL) error {
+    if u.Scheme != "http" && u.Scheme != "https" {
+        return fmt.Errorf("only http and https URLs are allowed")
+    }
+    host := strings.ToLower(u.Hostname())
+    if host == "" {
+        return fmt.Errorf("URL has no host")
+    }
+    if len(f.cfg.AllowedHosts) == 0 {
+        return nil
+    }
+    for _, pattern := range f.cfg.AllowedHosts {
+        pattern = strings.ToLower(pattern)
+        if strings.HasPrefix(pattern, "*.") {
+            if host == pattern[2:] || strings.HasSuffix(host, pattern[1:]) {
+                return nil
+            }
+        } else if host == pattern {
+            return nil
+        }
+    }
+    return fmt.Errorf("host %s is not in the allowlist", host)
+}
+
+func isPrivateIP(ip net.IP) bool {
+    return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+        ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+var (
+    htmlDropBlocks = regexp.MustCompile(`(?is)<(script|style|noscript|head|svg)\b.*?</(script|style|noscript|head|svg)>`)
+    htmlComments   = regexp.MustCompile(`(?s)<!--.*?-->`)
+    htmlBreaks     = regexp.MustCompile(`(?i)<(br|/p|/div|/tr|/h[1-6]|/section|/article|/header|/footer|/pre|/blockquote)\b[^>]*>`)
+    htmlListItems  = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+    htmlTags       = regexp.MustCompile(`(?s)<[^>]*>`)
+    spaceRuns      = regexp.MustCompile(`[ \t\r\f\v]+`)
+    blankLines     = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// htmlToText reduces an HTML document to readable text: scripts, styles and
+// markup are removed, block elements become line breaks, entities are decoded
+func htmlToText(doc string) string {
+    doc = htmlDropBlocks.ReplaceAllString(doc, "")
+    doc = htmlComments.ReplaceAllString(doc, "")
+    doc = htmlBreaks.ReplaceAllString(doc, "\n")
+    doc = htmlListItems.ReplaceAllString(doc, "\n- ")
+    doc = htmlTags.ReplaceAllString(doc, " ")
+    doc = html.UnescapeString(doc)
+    doc = spaceRuns.ReplaceAllString(doc, " ")
+
+    lines := strings.Split(doc, "\n")
+    for i, line := range lines {
+        lines[i] = strings.TrimSpace(line)
+    }
+    doc = strings.Join(lines, "\n")
+    return strings.TrimSpace(blankLines.ReplaceAllString(doc, "\n\n"))
+}
@@ -0,0 +1,211 @@
+package toolcatalog
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "os"
+    "os/exec"
+    "strings"
+    "time"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    defaultShellTimeout     = 30 * time.Second
+    defaultShellOutputBytes = 32 << 10
+)
+
+// ShellPolicy governs what the run_command tool may execute. Commands are run
+// directly, never through a shell, so there is no globbing, piping or
+// substitution to escape the policy with.
+type ShellPolicy struct {
+    // AllowedBinaries names the programs that may run (e.g. "ls", "git"). Each
+    // is resolved to an absolute path when the tool is created. Required.
+    AllowedBinaries []string
+
+    // BannedArgs rejects any argument equal to an entry, or starting with the
+    // entry followed by "=" (so "--output" also bans "--output=file")
+    BannedArgs []string
+
+    // Env is the complete child environment. When nil the environment is
+    // scrubbed to PATH, HOME (the working directory) and LANG, plus PassEnv.
+    Env     []string
+    PassEnv []string // Parent variables copied into a scrubbed environment
+
+    Dir            string        // Working directory; defaults to the current directory
+    Timeout        time.Duration // Wall-clock limit; defaults to 30s
+    MaxCPUSeconds  int           // CPU time limit; 0 means no limit (unix only)
+    MaxMemoryBytes int64         // Address space limit; 0 means no limit (unix only)
+    MaxOutputBytes int           // Combined stdout/stderr returned; defaults to 32KB
+}
+
+// Check reports whether the policy permits running program with args
+func (p *ShellPolicy) Check(program string, args []string) error {
+    allowed := false
+    for _, name := range p.AllowedBinaries {
+        if program == name {
+            allowed = true
+            break
+        }
+    }
+    if !allowed {
+        return fmt.Errorf("program %q is not allowed (allowed: %s)", program, strings.Join(p.AllowedBinaries, ", "))
+    }
+    for _, arg := range args {
+        for _, banned := range p.BannedArgs {
+            if arg == banned || strings.HasPrefix(arg, banned+"=") {
+                return fmt.Errorf("argument %q is not allowed", arg)
+            }
+        }
+    }
+    return nil
+}
+
+// environment builds the child environment according to the policy
+func (p *ShellPolicy) environment() []string {
+    if p.Env != nil {
+        return p.Env
+    }
+    home := p.Dir
+    if home == "" {
+        home, _ = os.Getwd()
+    }
+    env := []string{"PATH=/usr/local/bin:/usr/bin:/bin", "HOME=" + home, "LANG=C.UTF-8"}
+    for _, name := range p.PassEnv {
+        if value, ok := os.LookupEnv(name); ok {
+            env = append(env, name+"="+value)
+        }
+    }
+    return env
+}
+
+type shellInput struct {
+    Program string   `json:"program" jsonschema:"description=Program to run, e.g. ls"`
+    Args    []string `json:"args,omitempty" jsonschema:"description=Arguments, one per element; no shell syntax is interpreted"`
+}
+
+// NewShell returns a run_command tool that executes allowed programs under
+// the given policy. On unix the CPU and memory limits are applied with
+// setrlimit and the whole process group is killed on timeout.
+func NewShell(policy ShellPolicy) (anthropic.ToolHandler, error) {
+    if len(policy.AllowedBinaries) == 0 {
+        return nil, fmt.Errorf("shell tool: at least one allowed binary is required")
+    }
+    if err := checkLimitsSupported(policy); err != nil {
+        return nil, fmt.Errorf("shell tool: %w", err)
+    }
+    if policy.Timeout <= 0 {
+        policy.Timeout = defaultShellTimeout
+    }
+    if policy.MaxOutputBytes <= 0 {
+        policy.MaxOutputBytes = defaultShellOutputBytes
+    }
+
+    paths := make(map[string]string, len(policy.AllowedBinaries))
+    for _, name := range policy.AllowedBinaries {
+        path, err := exec.LookPath(name)
+        if err != nil {
+            return nil, fmt.Errorf("shell tool: cannot find allowed binary %s: %w", name, err)
+        }
+        paths[name] = path
+    }
+
+    s := &shellTool{policy: policy, paths: paths}
+    description := fmt.Sprintf("Runs a program with arguments and returns its output. Allowed programs: %s. "+
+        "Arguments are passed directly; pipes, redirection and globbing are not available. Time limit: %s",
+        strings.Join(policy.AllowedBinaries, ", "), policy.Timeout)
+    return newStructTool("run_command", description, s.run)
+}
+
+type shellTool struct {
+    policy ShellPolicy
+    paths  map[string]string // Allowed program name to resolved absolute path
+}
+
+func (s *shellTool) run(ctx context.Context, in shellInput) (anthropic.ToolResult, error) {
+    if err := s.policy.Check(in.Program, in.Args); err != nil {
+        return errorResult("%v", err), nil
+    }
+
+    cmd := limitedCommand(s.policy, s.paths[in.Program], in.Args)
+    cmd.Dir = s.policy.Dir
+    cmd.Env = s.policy.environment()
+    output := &cappedBuffer{limit: s.policy.MaxOutputBytes}
+    cmd.Stdout = output
+    cmd.Stderr = output
+
+    if err := cmd.Start(); err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error starting %s: %w", in.Program, err)
+    }
+    done := make(chan error, 1)
+    go func() { done <- cmd.Wait() }()
+
+    timer := time.NewTimer(s.policy.Timeout)
+    defer timer.Stop()
+
+    var waitErr error
+    timedOut := false
+    select {
+    case waitErr = <-done:
+    case <-timer.C:
+        timedOut = true
+        killProcessTree(cmd)
+        waitErr = <-done
+    case <-ctx.Done():
+        killProcessTree(cmd)
+        <-done
+        return anthropic.ToolResult{}, ctx.Err()
+    }
+
+    text := output.String()
+    switch {
+    case timedOut:
+        if text != "" {
+            return errorResult("%s timed out after %s\n%s", in.Program, s.policy.Timeout, text), nil
+        }
+        return errorResult("%s timed out after %s", in.Program, s.policy.Timeout), nil
+    case waitErr != nil:
+        status := waitErr.Error()
+        if exitErr, ok := waitErr.(*exec.ExitError); ok {
+            status = fmt.Sprintf("exit code %d", exitErr.ExitCode())
+            if exitErr.ExitCode() < 0 {
+                status = exitErr.String() // Killed by a signal, e.g. a resource limit
+            }
+        }
+        if text != "" {
+            return anthropic.ToolResult{Content: strings.TrimRight(text, "\n") + "\n[" + status + "]", IsError: true}, nil
+        }
+        return anthropic.ToolResult{Content: "[" + status + "]", IsError: true}, nil
+    }
+    return anthropic.ToolResult{Content: text}, nil
+}
+
+// cappedBuffer keeps the first limit bytes written and discards the rest
+type cappedBuffer struct {
+    buf       bytes.Buffer
+    limit     int
+    truncated bool
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+    if room := c.limit - c.buf.Len(); room > 0 {
+        if len(p) > room {
+            c.buf.Write(p[:room])
+            c.truncated = true
+        } else {
+            c.buf.Write(p)
+        }
+    } else if len(p) > 0 {
+        c.truncated = true
+    }
+    return len(p), nil
+}
+
+func (c *cappedBuffer) String() string {
+    if c.truncated {
+        return c.buf.String() + "\n[output truncated]"
+    }
+    return c.buf.String()
+}
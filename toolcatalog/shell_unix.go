@@ -0,0 +1,49 @@
+//go:build !windows
+
+package toolcatalog
+
+import (
+    "os/exec"
+    "strconv"
+    "syscall"
+)
+
+// limitedCommand runs path with args in its own process group. Resource limits
+// are applied by a /bin/sh wrapper that sets them with ulimit and then execs
+// the program, so the limits bind the program itself and nothing else runs.
+func limitedCommand(policy ShellPolicy, path string, args []string) *exec.Cmd {
+    var cmd *exec.Cmd
+    if policy.MaxCPUSeconds > 0 || policy.MaxMemoryBytes > 0 {
+        script := ""
+        if policy.MaxCPUSeconds > 0 {
+            script += "ulimit -t " + strconv.Itoa(policy.MaxCPUSeconds) + " || exit 125; "
+        }
+        if policy.MaxMemoryBytes > 0 {
+            kb := policy.MaxMemoryBytes / 1024
+            if kb < 1 {
+                kb = 1
+            }
+            script += "ulimit -v " + strconv.FormatInt(kb, 10) + " || exit 125; "
+        }
+        script += `exec "$0" "$@"`
+        cmd = exec.Command("/bin/sh", append([]string{"-c", script, path}, args...)...)
+    } else {
+        cmd = exec.Command(path, args...)
+    }
+    cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+    return cmd
+}
+
+// killProcessTree kills the command's whole process group
+func killProcessTree(cmd *exec.Cmd) {
+    if cmd.Process == nil {
+        return
+    }
+    if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+        cmd.Process.Kill()
+    }
+}
+
+func checkLimitsSupported(policy ShellPolicy) error {
+    return nil
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package toolcatalog
+
+import (
+    "fmt"
+    "os/exec"
+)
+
+func limitedCommand(policy ShellPolicy, path string, args []string) *exec.Cmd {
+    return exec.Command(path, args...)
+}
+
+// killProcessTree kills the command; child processes it started may survive
+func killProcessTree(cmd *exec.Cmd) {
+    if cmd.Process != nil {
+        cmd.Process.Kill()
+    }
+}
+
+func checkLimitsSupported(policy ShellPolicy) error {
+    if policy.MaxCPUSeconds > 0 || policy.MaxMemoryBytes > 0 {
+        return fmt.Errorf("CPU and memory limits are not supported on Windows")
+    }
+    return nil
+}
@@ -0,0 +1,244 @@
+package toolcatalog
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    defaultSQLMaxRows  = 200
+    defaultSQLMaxBytes = 64 << 10
+    defaultSQLTimeout  = 30 * time.Second
+)
+
+// SQLQueryConfig configures the SQL query tool
+type SQLQueryConfig struct {
+    Name string // Tool name; defaults to "sql_query"
+
+    // Schema describes the tables available (e.g. the CREATE TABLE statements)
+    // and is included in the tool description so Claude can write valid SQL
+    Schema  string
+    Dialect string // e.g. "PostgreSQL" or "SQLite", included in the description
+
+    // AllowedStatements lists the leading keywords a query may start with.
+    // Defaults to SELECT, WITH and EXPLAIN.
+    AllowedStatements []string
+
+    MaxRows  int           // Rows returned; defaults to 200
+    MaxBytes int           // Size of the formatted result; defaults to 64KB
+    Timeout  time.Duration // Per-query limit; defaults to 30s
+}
+
+type sqlQueryInput struct {
+    Query string `json:"query" jsonschema:"description=A single read-only SQL statement"`
+}
+
+// NewSQLQuery returns a tool that lets Claude query db. Each query runs in a
+// read-only transaction that is always rolled back, must be a single statement
+// starting with an allowed keyword, and has its result capped in rows and bytes.
+func NewSQLQuery(db *sql.DB, cfg SQLQueryConfig) (anthropic.ToolHandler, error) {
+    if db == nil {
+        return nil, fmt.Errorf("sql query tool: db is required")
+    }
+    if cfg.Name == "" {
+        cfg.Name = "sql_query"
+    }
+    if len(cfg.AllowedStatements) == 0 {
+        cfg.AllowedStatements = []string{"SELECT", "WITH", "EXPLAIN"}
+    }
+    if cfg.MaxRows <= 0 {
+        cfg.MaxRows = defaultSQLMaxRows
+    }
+    if cfg.MaxBytes <= 0 {
+        cfg.MaxBytes = defaultSQLMaxBytes
+    }
+    if cfg.Timeout <= 0 {
+        cfg.Timeout = defaultSQLTimeout
+    }
+
+    description := "Runs a read-only SQL query against the database and returns the result as a table"
+    if cfg.Dialect != "" {
+        description += fmt.Sprintf(". SQL dialect: %s", cfg.Dialect)
+    }
+    description += fmt.Sprintf(". Allowed statements: %s. At most %d rows are returned",
+        strings.Join(cfg.AllowedStatements, ", "), cfg.MaxRows)
+    if cfg.Schema != "" {
+        description += ".\n\nSchema:\n" + cfg.Schema
+    }
+
+    q := &sqlQuery{db: db, cfg: cfg}
+    return newStructTool(cfg.Name, description, q.run)
+}
+
+type sqlQuery struct {
+    db  *sql.DB
+    cfg SQLQueryConfig
+}
+
+func (q *sqlQuery) run(ctx context.Context, in sqlQueryInput) (anthropic.ToolResult, error) {
+    query, err := q.checkQuery(in.Query)
+    if err != nil {
+        return errorResult("%v", err), nil
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, q.cfg.Timeout)
+    defer cancel()
+
+    tx, err := q.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+    if err != nil {
+        return anthropic.ToolResult{}, fmt.Errorf("error starting read-only transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    rows, err := tx.QueryContext(ctx, query)
+    if err != nil {
+        return errorResult("query failed: %v", err), nil
+    }
+    defer rows.Close()
+
+    columns, err := rows.Columns()
+    if err != nil {
+        return errorResult("query failed: %v", err), nil
+    }
+
+    var b strings.Builder
+    b.WriteString(strings.Join(columns, " | "))
+    b.WriteString("\n")
+
+    values := make([]interface{}, len(columns))
+    pointers := make([]interface{}, len(columns))
+    for i := range values {
+        pointers[i] = &values[i]
+    }
+
+    count := 0
+    truncated := ""
+    for rows.Next() {
+        if count >= q.cfg.MaxRows {
+            truncated = fmt.Sprintf("[stopped after %d rows]", q.cfg.MaxRows)
+            break
+        }
+        if err := rows.Scan(pointers...); err != nil {
+            return errorResult("error reading row: %v", err), nil
+        }
+
+        cells := make([]string, len(values))
+        for i, v := range values {
+            cells[i] = formatSQLValue(v)
+        }
+        line := strings.Join(cells, " | ") + "\n"
+        if b.Len()+len(line) > q.cfg.MaxBytes {
+            truncated = fmt.Sprintf("[stopped after %d rows: result exceeds %d bytes]", count, q.cfg.MaxBytes)
+            break
+        }
+        b.WriteString(line)
+        count++
+    }
+    if err := rows.Err(); err != nil {
+        return errorResult("query failed: %v", err), nil
+    }
+
+    if truncated != "" {
+        b.WriteString(truncated)
+    } else {
+        fmt.Fprintf(&b, "(%d rows)", count)
+    }
+    return anthropic.ToolResult{Content: b.String()}, nil
+}
+
+// checkQuery enforces the single-statement rule and the statement allowlist
+func (q *sqlQuery) checkQuery(query string) (string, error) {
+    query = strings.TrimSpace(query)
+    query = strings.TrimSpace(strings.TrimRight(query, ";"))
+    if query == "" {
+        return "", fmt.Errorf("query is required")
+    }
+    if containsStatementSeparator(query) {
+        return "", fmt.Errorf("only a single SQL statement is allowed")
+    }
+
+    fields := strings.Fields(stripLeadingComments(query))
+    if len(fields) == 0 {
+        return "", fmt.Errorf("query is required")
+    }
+    keyword := strings.ToUpper(strings.TrimLeft(fields[0], "("))
+    for _, allowed := range q.cfg.AllowedStatements {
+        if keyword == strings.ToUpper(allowed) {
+            return query, nil
+        }
+    }
+    return "", fmt.Errorf("%s statements are not allowed (allowed: %s)", keyword, strings.Join(q.cfg.AllowedStatements, ", "))
+}
+
+// containsStatementSeparator reports a semicolon outside quotes and comments
+func containsStatementSeparator(query string) bool {
+    var quote byte
+    for i := 0; i < len(query); i++ {
+        c := query[i]
+        switch {
+        case quote != 0:
+            if c == quote {
+                quote = 0
+            }
+        case c == '\'' || c == '"' || c == '`':
+            quote = c
+        case c == '-' && i+1 < len(query) && query[i+1] == '-':
+            for i < len(query) && query[i] != '\n' {
+                i++
+            }
+        case c == '/' && i+1 < len(query) && query[i+1] == '*':
+            end := strings.Index(query[i+2:], "*/")
+            if end < 0 {
+                return false
+            }
+            i += end + 3
+        case c == ';':
+            return true
+        }
+    }
+    return false
+}
+
+// stripLeadingComments removes comments before the first keyword
+func stripLeadingComments(query string) string {
+    for {
+        query = strings.TrimSpace(query)
+        switch {
+        case strings.HasPrefix(query, "--"):
+            end := strings.Index(query, "\n")
+            if end < 0 {
+                return ""
+            }
+            query = query[end+1:]
+        case strings.HasPrefix(query, "/*"):
+            end := strings.Index(query, "*/")
+            if end < 0 {
+                return ""
+            }
+            query = query[end+2:]
+        default:
+            return query
+        }
+    }
+}
+
+func formatSQLValue(v interface{}) string {
+    switch val := v.(type) {
+    case nil:
+        return "NULL"
+    case []byte:
+        if isBinary(val) {
+            return fmt.Sprintf("<%d bytes>", len(val))
+        }
+        return string(val)
+    case time.Time:
+        return val.Format(time.RFC3339)
+    default:
+        return fmt.Sprint(val)
+    }
+}
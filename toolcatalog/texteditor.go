@@ -0,0 +1,242 @@
+package toolcatalog
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+const (
+    maxEditorFileBytes = 4 << 20
+    maxDirEntries      = 500
+)
+
+type editorInput struct {
+    Command    string `json:"command"`
+    Path       string `json:"path"`
+    ViewRange  []int  `json:"view_range"`
+    FileText   string `json:"file_text"`
+    OldStr     string `json:"old_str"`
+    NewStr     string `json:"new_str"`
+    InsertLine int    `json:"insert_line"`
+}
+
+// NewTextEditor returns a handler for Anthropic's text editor tool
+// (anthropic.TextEditorTool) that reads and edits files under root. Paths
+// outside root, including via symlinks, are rejected.
+func NewTextEditor(root string) (anthropic.ToolHandler, error) {
+    box, err := newSandbox(root)
+    if err != nil {
+        return nil, fmt.Errorf("text editor: %w", err)
+    }
+    return &textEditor{box: box, history: make(map[string][]string)}, nil
+}
+
+type textEditor struct {
+    box sandbox
+
+    mu      sync.Mutex
+    history map[string][]string // Previous contents per file for undo_edit
+}
+
+func (e *textEditor) Definition() anthropic.Tool {
+    return anthropic.TextEditorTool()
+}
+
+func (e *textEditor) Execute(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+    var in editorInput
+    if err := json.Unmarshal(input, &in); err != nil {
+        return errorResult("invalid input: %v", err), nil
+    }
+    path, err := e.box.resolve(in.Path)
+    if err != nil {
+        return errorResult("%v", err), nil
+    }
+
+    e.mu.Lock()
+    defer e.mu.Unlock()
+
+    switch in.Command {
+    case "view":
+        return e.view(path, in.ViewRange), nil
+    case "create":
+        return e.create(path, in.FileText), nil
+    case "str_replace":
+        return e.strReplace(path, in.OldStr, in.NewStr), nil
+    case "insert":
+        return e.insert(path, in.InsertLine, in.NewStr), nil
+    case "undo_edit":
+        return e.undo(path), nil
+    default:
+        return errorResult("unknown command %q", in.Command), nil
+    }
+}
+
+func (e *textEditor) view(path string, viewRange []int) anthropic.ToolResult {
+    info, err := os.Stat(path)
+    if err != nil {
+        return errorResult("%s does not exist", e.box.display(path))
+    }
+    if info.IsDir() {
+        return e.listDir(path)
+    }
+
+    text, result, ok := e.readFile(path)
+    if !ok {
+        return result
+    }
+    lines := strings.Split(text, "\n")
+    start, end := 1, len(lines)
+    if len(viewRange) == 2 {
+        start = viewRange[0]
+        if viewRange[1] != -1 {
+            end = viewRange[1]
+        }
+        if start < 1 || start > len(lines) || end < start || end > len(lines) {
+            return errorResult("invalid view_range %v for a file with %d lines", viewRange, len(lines))
+        }
+    }
+
+    var b strings.Builder
+    for i := start; i <= end; i++ {
+        fmt.Fprintf(&b, "%6d\t%s\n", i, lines[i-1])
+    }
+    return anthropic.ToolResult{Content: b.String()}
+}
+
+func (e *textEditor) listDir(path string) anthropic.ToolResult {
+    var entries []string
+    err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+        if err != nil || p == path {
+            return nil
+        }
+        if strings.HasPrefix(info.Name(), ".") {
+            if info.IsDir() {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        rel, _ := filepath.Rel(path, p)
+        if info.IsDir() {
+            rel += "/"
+            // Two levels deep, like the reference implementation
+            if strings.Count(filepath.ToSlash(rel), "/") >= 2 {
+                entries = append(entries, filepath.ToSlash(rel))
+                return filepath.SkipDir
+            }
+        }
+        entries = append(entries, filepath.ToSlash(rel))
+        if len(entries) >= maxDirEntries {
+            return fmt.Errorf("too many entries")
+        }
+        return nil
+    })
+    sort.Strings(entries)
+
+    header := fmt.Sprintf("Files in %s:\n", e.box.display(path))
+    if err != nil {
+        header = fmt.Sprintf("First %d entries in %s:\n", maxDirEntries, e.box.display(path))
+    }
+    return anthropic.ToolResult{Content: header + strings.Join(entries, "\n")}
+}
+
+func (e *textEditor) create(path, text string) anthropic.ToolResult {
+    if info, err := os.Stat(path); err == nil && info.IsDir() {
+        return errorResult("%s is a directory", e.box.display(path))
+    }
+    if previous, err := ioutil.ReadFile(path); err == nil {
+        e.history[path] = append(e.history[path], string(previous))
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return errorResult("cannot create directory for %s: %v", e.box.display(path), err)
+    }
+    if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+        return errorResult("cannot write %s: %v", e.box.display(path), err)
+    }
+    return anthropic.ToolResult{Content: fmt.Sprintf("File created successfully at %s", e.box.display(path))}
+}
+
+func (e *textEditor) strReplace(path, oldStr, newStr string) anthropic.ToolResult {
+    text, result, ok := e.readFile(path)
+    if !ok {
+        return result
+    }
+    if oldStr == "" {
+        return errorResult("old_str must not be empty")
+    }
+    switch count := strings.Count(text, oldStr); count {
+    case 0:
+        return errorResult("no match found for old_str in %s", e.box.display(path))
+    case 1:
+    default:
+        return errorResult("old_str matches %d times in %s; include more context to make it unique",
+            count, e.box.display(path))
+    }
+    return e.write(path, text, strings.Replace(text, oldStr, newStr, 1))
+}
+
+func (e *textEditor) insert(path string, line int, newStr string) anthropic.ToolResult {
+    text, result, ok := e.readFile(path)
+    if !ok {
+        return result
+    }
+    lines := strings.Split(text, "\n")
+    if line < 0 || line > len(lines) {
+        return errorResult("insert_line %d is out of range (0-%d)", line, len(lines))
+    }
+    inserted := strings.Split(newStr, "\n")
+    updated := append(append(append([]string{}, lines[:line]...), inserted...), lines[line:]...)
+    return e.write(path, text, strings.Join(updated, "\n"))
+}
+
+func (e *textEditor) undo(path string) anthropic.ToolResult {
+    versions := e.history[path]
+    if len(versions) == 0 {
+        return errorResult("no edit history for %s", e.box.display(path))
+    }
+    previous := versions[len(versions)-1]
+    e.history[path] = versions[:len(versions)-1]
+    if err := ioutil.WriteFile(path, []byte(previous), 0644); err != nil {
+        return errorResult("cannot write %s: %v", e.box.display(path), err)
+    }
+    return anthropic.ToolResult{Content: fmt.Sprintf("Last edit to %s undone", e.box.display(path))}
+}
+
+// readFile loads a text file, returning an error result when it cannot be edited
+func (e *textEditor) readFile(path string) (string, anthropic.ToolResult, bool) {
+    info, err := os.Stat(path)
+    if err != nil {
+        return "", errorResult("%s does not exist", e.box.display(path)), false
+    }
+    if info.IsDir() {
+        return "", errorResult("%s is a directory", e.box.display(path)), false
+    }
+    if info.Size() > maxEditorFileBytes {
+        return "", errorResult("%s is too large (%d bytes)", e.box.display(path), info.Size()), false
+    }
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return "", errorResult("cannot read %s: %v", e.box.display(path), err), false
+    }
+    if isBinary(data) {
+        return "", errorResult("%s is a binary file", e.box.display(path)), false
+    }
+    return string(data), anthropic.ToolResult{}, true
+}
+
+// write saves new contents and records the old ones for undo_edit
+func (e *textEditor) write(path, previous, updated string) anthropic.ToolResult {
+    if err := ioutil.WriteFile(path, []byte(updated), 0644); err != nil {
+        return errorResult("cannot write %s: %v", e.box.display(path), err)
+    }
+    e.history[path] = append(e.history[path], previous)
+    return anthropic.ToolResult{Content: fmt.Sprintf("The file %s has been edited", e.box.display(path))}
+}
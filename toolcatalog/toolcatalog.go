@@ -0,0 +1,191 @@
+// Package toolcatalog provides ready-made tool handlers for common agent tasks.
+// Each constructor returns an anthropic.ToolHandler that can be added to a
+// ToolRegistry:
+//
+//     editor, err := toolcatalog.NewTextEditor("/srv/workspace")
+//     if err != nil { ... }
+//     registry.Register(editor)
+//
+// Handlers that touch the filesystem are confined to a root directory.
+package toolcatalog
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/rdhillbb/anthropic"
+)
+
+// sandbox confines paths to a root directory
+type sandbox struct {
+    root string
+}
+
+func newSandbox(root string) (sandbox, error) {
+    if root == "" {
+        return sandbox{}, fmt.Errorf("root directory is required")
+    }
+    abs, err := filepath.Abs(root)
+    if err != nil {
+        return sandbox{}, fmt.Errorf("error resolving root %s: %w", root, err)
+    }
+    resolved, err := filepath.EvalSymlinks(abs)
+    if err != nil {
+        return sandbox{}, fmt.Errorf("error resolving root %s: %w", root, err)
+    }
+    info, err := os.Stat(resolved)
+    if err != nil {
+        return sandbox{}, fmt.Errorf("error reading root %s: %w", root, err)
+    }
+    if !info.IsDir() {
+        return sandbox{}, fmt.Errorf("root %s is not a directory", root)
+    }
+    return sandbox{root: resolved}, nil
+}
+
+// resolve maps a model-supplied path onto the sandbox. Absolute paths are
+// treated as relative to the root, and symlinks may not escape it.
+func (s sandbox) resolve(path string) (string, error) {
+    if path == "" {
+        return "", fmt.Errorf("path is required")
+    }
+    full := filepath.Join(s.root, filepath.Clean("/"+path))
+
+    // Resolve the longest existing prefix so symlinks are checked even when
+    // the final component does not exist yet
+    existing := full
+    var rest []string
+    for {
+        if _, err := os.Lstat(existing); err == nil {
+            break
+        }
+        parent := filepath.Dir(existing)
+        if parent == existing {
+            break
+        }
+        rest = append([]string{filepath.Base(existing)}, rest...)
+        existing = parent
+    }
+    resolved, err := filepath.EvalSymlinks(existing)
+    if err != nil {
+        return "", fmt.Errorf("error resolving %s: %w", path, err)
+    }
+    resolved = filepath.Join(append([]string{resolved}, rest...)...)
+    if !s.contains(resolved) {
+        return "", fmt.Errorf("path %s is outside the allowed directory", path)
+    }
+    return resolved, nil
+}
+
+func (s sandbox) contains(path string) bool {
+    return path == s.root || strings.HasPrefix(path, s.root+string(filepath.Separator))
+}
+
+// display returns a path relative to the root for messages shown to the model
+func (s sandbox) display(path string) string {
+    rel, err := filepath.Rel(s.root, path)
+    if err != nil || rel == "." {
+        return "/"
+    }
+    return "/" + filepath.ToSlash(rel)
+}
+
+// errorResult reports a problem the model can correct as an is_error result
+func errorResult(format string, args ...interface{}) anthropic.ToolResult {
+    return anthropic.ToolResult{Content: "Error: " + fmt.Sprintf(format, args...), IsError: true}
+}
+
+// isBinary reports whether data looks like a binary file: it contains a NUL
+// byte or is not valid UTF-8 within the first few kilobytes
+func isBinary(data []byte) bool {
+    sample := data
+    truncated := len(sample) > 8192
+    if truncated {
+        sample = sample[:8192]
+    }
+    if bytes.IndexByte(sample, 0) >= 0 {
+        return true
+    }
+    // Allow a multi-byte rune cut off at the sample boundary
+    for i := 0; truncated && i < utf8.UTFMax-1 && !utf8.Valid(sample); i++ {
+        sample = sample[:len(sample)-1]
+    }
+    return !utf8.Valid(sample)
+}
+
+// toolFunc is a ToolHandler built from a definition and a function
+type toolFunc struct {
+    def anthropic.Tool
+    fn  func(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error)
+}
+
+func (t *toolFunc) Definition() anthropic.Tool {
+    return t.def
+}
+
+func (t *toolFunc) Execute(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+    return t.fn(ctx, input)
+}
+
+// newStructTool derives the tool schema from T and decodes input into T
+// before calling fn. Malformed input is reported to the model as an error.
+func newStructTool[T any](name, description string, fn func(ctx context.Context, in T) (anthropic.ToolResult, error)) (anthropic.ToolHandler, error) {
+    def, err := anthropic.NewToolFromStruct[T](name, description)
+    if err != nil {
+        return nil, err
+    }
+    return &toolFunc{def: def, fn: func(ctx context.Context, input json.RawMessage) (anthropic.ToolResult, error) {
+        var in T
+        if err := json.Unmarshal(input, &in); err != nil {
+            return errorResult("invalid input: %v", err), nil
+        }
+        return fn(ctx, in)
+    }}, nil
+}
+
+// StandardToolsConfig selects the members of the standard tool bundle. The
+// calculator is always included; the others are enabled by their settings.
+type StandardToolsConfig struct {
+    WorkspaceRoot string           // Enables get_file, put_file and list_dir under this directory
+    ReadOnly      bool             // Omit put_file from the file tools
+    HTTPFetch     *HTTPFetchConfig // Enables http_fetch with this configuration
+}
+
+// StandardTools returns an opt-in bundle of general-purpose tools:
+//
+//     tools, err := toolcatalog.StandardTools(toolcatalog.StandardToolsConfig{
+//         WorkspaceRoot: "/srv/workspace",
+//         HTTPFetch:     &toolcatalog.HTTPFetchConfig{HTMLToText: true},
+//     })
+//     for _, t := range tools {
+//         registry.Register(t)
+//     }
+func StandardTools(cfg StandardToolsConfig) ([]anthropic.ToolHandler, error) {
+    calculator, err := NewCalculator()
+    if err != nil {
+        return nil, err
+    }
+    tools := []anthropic.ToolHandler{calculator}
+
+    if cfg.WorkspaceRoot != "" {
+        files, err := NewFileTools(FileToolsConfig{Root: cfg.WorkspaceRoot, ReadOnly: cfg.ReadOnly})
+        if err != nil {
+            return nil, err
+        }
+        tools = append(tools, files...)
+    }
+    if cfg.HTTPFetch != nil {
+        fetch, err := NewHTTPFetch(*cfg.HTTPFetch)
+        if err != nil {
+            return nil, err
+        }
+        tools = append(tools, fetch)
+    }
+    return tools, nil
+}